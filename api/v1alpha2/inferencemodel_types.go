@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha2
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -67,7 +68,7 @@ type InferenceModelSpec struct {
 	// ModelNames must be unique for a referencing InferencePool
 	// (names can be reused for a different pool in the same cluster).
 	// The modelName with the oldest creation timestamp is retained, and the incoming
-	// InferenceModel is sets the Ready status to false with a corresponding reason.
+	// InferenceModel sets the Accepted status to false with a corresponding reason.
 	// In the rare case of a race condition, one Model will be selected randomly to be considered valid, and the other rejected.
 	// Names can be reserved without an underlying model configured in the pool.
 	// This can be done by specifying a target model and setting the weight to zero,
@@ -78,6 +79,21 @@ type InferenceModelSpec struct {
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="modelName is immutable"
 	ModelName string `json:"modelName"`
 
+	// Aliases are additional names of the model matched against the "model" parameter of an incoming
+	// request. An alias containing '*' is a pattern where '*' matches any sequence of characters,
+	// e.g. "llama-3-*". Requests are matched against the modelNames first, then the aliases, then the
+	// most specific pattern, being the one with the most characters other than '*'.
+	// Like modelNames, aliases must be unique for a referencing InferencePool: the InferenceModel with
+	// the oldest creation timestamp retains the alias, and the others set the AliasesAccepted status
+	// to false with a corresponding reason.
+	//
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:items:MinLength=1
+	// +kubebuilder:validation:items:MaxLength=256
+	Aliases []string `json:"aliases,omitempty"`
+
 	// Criticality defines how important it is to serve the model compared to other models referencing the same pool.
 	// Criticality impacts how traffic is handled in resource constrained situations. It handles this by
 	// queuing or rejecting requests of lower criticality. InferenceModels of an equivalent Criticality will
@@ -98,6 +114,22 @@ type InferenceModelSpec struct {
 	// +kubebuilder:validation:XValidation:message="Weights should be set for all models, or none of the models.",rule="self.all(model, has(model.weight)) || self.all(model, !has(model.weight))"
 	TargetModels []TargetModel `json:"targetModels,omitempty"`
 
+	// Limits bound the size of the requests accepted for the model. Requests exceeding a limit are
+	// rejected before being scheduled, with a 413 status if the body is too large and a 400 status
+	// otherwise. Unset limits are not enforced.
+	//
+	// +optional
+	Limits *RequestLimits `json:"limits,omitempty"`
+
+	// Mutations are rules rewriting the body of the requests for the model, such as defaulting or
+	// bounding their parameters. They are applied in order, before the requests are checked against
+	// the limits and scheduled.
+	//
+	// +optional
+	// +listType=atomic
+	// +kubebuilder:validation:MaxItems=16
+	Mutations []RequestMutation `json:"mutations,omitempty"`
+
 	// PoolRef is a reference to the inference pool, the pool must exist in the same namespace.
 	//
 	// +kubebuilder:validation:Required
@@ -145,6 +177,66 @@ const (
 	Sheddable Criticality = "Sheddable"
 )
 
+// RequestMutationType is the type of a RequestMutation.
+//
+// +kubebuilder:validation:Enum=SetIfAbsent;Clamp;PrependSystemMessage;Drop
+type RequestMutationType string
+
+const (
+	// MutationSetIfAbsent sets the field to the value in the requests that don't set it.
+	MutationSetIfAbsent RequestMutationType = "SetIfAbsent"
+
+	// MutationClamp bounds the numeric value of the field to the [min, max] range. Requests that don't
+	// set the field are left unchanged.
+	MutationClamp RequestMutationType = "Clamp"
+
+	// MutationPrependSystemMessage prepends a system message with the content to the messages of chat
+	// completions requests, and prepends the content as is to the string prompts of completions
+	// requests.
+	MutationPrependSystemMessage RequestMutationType = "PrependSystemMessage"
+
+	// MutationDrop removes the field from the requests.
+	MutationDrop RequestMutationType = "Drop"
+)
+
+// RequestMutation is a rule rewriting the body of a request.
+type RequestMutation struct {
+	// Type is the type of the mutation.
+	//
+	// +kubebuilder:validation:Required
+	Type RequestMutationType `json:"type"`
+
+	// Field is the name of the top-level request parameter mutated by the SetIfAbsent, Clamp and Drop
+	// mutations, e.g. "max_tokens". The "model" parameter can't be mutated.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxLength=256
+	Field string `json:"field,omitempty"`
+
+	// Value is the JSON value set by the SetIfAbsent mutation.
+	//
+	// +optional
+	Value *apiextensionsv1.JSON `json:"value,omitempty"`
+
+	// Min is the lower bound of the Clamp mutation, as a decimal number.
+	//
+	// +optional
+	// +kubebuilder:validation:Pattern=`^-?[0-9]+(\.[0-9]+)?$`
+	Min *string `json:"min,omitempty"`
+
+	// Max is the upper bound of the Clamp mutation, as a decimal number.
+	//
+	// +optional
+	// +kubebuilder:validation:Pattern=`^-?[0-9]+(\.[0-9]+)?$`
+	Max *string `json:"max,omitempty"`
+
+	// Content is the content of the system message prepended by the PrependSystemMessage mutation.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxLength=65536
+	Content string `json:"content,omitempty"`
+}
+
 // TargetModel represents a deployed model or a LoRA adapter. The
 // Name field is expected to match the name of the LoRA adapter
 // (or base model) as it is registered within the model server. Inference
@@ -178,6 +270,43 @@ type TargetModel struct {
 	Weight *int32 `json:"weight,omitempty"`
 }
 
+// RequestLimits bound the size of the requests accepted for a model.
+type RequestLimits struct {
+	// MaxBodyBytes is the maximum size of the request body in bytes.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxBodyBytes *int64 `json:"maxBodyBytes,omitempty"`
+
+	// MaxMessages is the maximum number of messages of a chat completions request.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxMessages *int32 `json:"maxMessages,omitempty"`
+
+	// MaxTokens is the maximum number of tokens a request may ask to generate, through the
+	// "max_tokens", "max_completion_tokens" or "max_output_tokens" parameter depending on the API.
+	// Requests that don't set the parameter are accepted.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxTokens *int32 `json:"maxTokens,omitempty"`
+
+	// MaxN is the maximum number of choices a request may ask to generate for each prompt, through
+	// the "n" parameter.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxN *int32 `json:"maxN,omitempty"`
+
+	// MaxPromptLength is the maximum length in characters of the text of the prompt, messages or
+	// input of a request. Token ID prompts are not counted.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxPromptLength *int32 `json:"maxPromptLength,omitempty"`
+}
+
 // InferenceModelStatus defines the observed state of InferenceModel
 type InferenceModelStatus struct {
 	// Conditions track the state of the InferenceModel.
@@ -190,7 +319,7 @@ type InferenceModelStatus struct {
 	// +listType=map
 	// +listMapKey=type
 	// +kubebuilder:validation:MaxItems=8
-	// +kubebuilder:default={{type: "Ready", status: "Unknown", reason:"Pending", message:"Waiting for controller", lastTransitionTime: "1970-01-01T00:00:00Z"}}
+	// +kubebuilder:default={{type: "Accepted", status: "Unknown", reason:"Pending", message:"Waiting for controller", lastTransitionTime: "1970-01-01T00:00:00Z"}}
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
@@ -217,6 +346,19 @@ const (
 	//
 	ModelConditionAccepted InferenceModelConditionType = "Accepted"
 
+	// ModelConditionAliasesAccepted indicates whether the aliases of an accepted InferenceModel are
+	// routed to it. It is only set on accepted InferenceModels with aliases.
+	//
+	// Possible reasons for this condition to be True are:
+	//
+	// * "Accepted"
+	//
+	// Possible reasons for this condition to be False are:
+	//
+	// * "AliasInUse"
+	//
+	ModelConditionAliasesAccepted InferenceModelConditionType = "AliasesAccepted"
+
 	// ModelReasonAccepted is the desired state. Model conforms to the state of the pool.
 	ModelReasonAccepted InferenceModelConditionReason = "Accepted"
 
@@ -224,6 +366,12 @@ const (
 	// Details about naming conflict resolution are on the ModelName field itself.
 	ModelReasonNameInUse InferenceModelConditionReason = "ModelNameInUse"
 
-	// ModelReasonPending is the initial state, and indicates that the controller has not yet reconciled the InferenceModel.
+	// ModelReasonAliasInUse is used when an alias of the model is already a modelName or an alias of
+	// another InferenceModel of the pool. The other names of the model are still routed to it.
+	// Details about naming conflict resolution are on the Aliases field itself.
+	ModelReasonAliasInUse InferenceModelConditionReason = "AliasInUse"
+
+	// ModelReasonPending is the initial state, and indicates that the controller has not yet reconciled the InferenceModel,
+	// or that the referenced InferencePool does not exist.
 	ModelReasonPending InferenceModelConditionReason = "Pending"
 )
@@ -64,11 +64,157 @@ type InferencePoolSpec struct {
 	// +kubebuilder:validation:Required
 	TargetPortNumber int32 `json:"targetPortNumber"`
 
+	// Metrics configures how the endpoint picker scrapes metrics from the selected model servers.
+	// When unspecified, the endpoint picker uses its own defaults.
+	//
+	// +optional
+	Metrics *ModelServerMetrics `json:"metrics,omitempty"`
+
 	// EndpointPickerConfig specifies the configuration needed by the proxy to discover and connect to the endpoint
 	// picker service that picks endpoints for the requests routed to this pool.
 	EndpointPickerConfig `json:",inline"`
 }
 
+// ModelServerMetrics configures the metrics endpoint exposed by the model servers in the pool, and
+// how the metrics it serves map to the signals used by the endpoint picker.
+type ModelServerMetrics struct {
+	// PortNumber is the port number serving the metrics endpoint on the model servers.
+	// Defaults to the TargetPortNumber of the pool.
+	//
+	// +optional
+	PortNumber *PortNumber `json:"portNumber,omitempty"`
+
+	// Path is the HTTP path of the metrics endpoint on the model servers.
+	// Defaults to "/metrics".
+	//
+	// +optional
+	// +kubebuilder:validation:MaxLength=1024
+	// +kubebuilder:validation:Pattern=`^/`
+	Path *string `json:"path,omitempty"`
+
+	// Scheme is the scheme used to scrape the metrics endpoint.
+	// Defaults to HTTP.
+	//
+	// +optional
+	Scheme *MetricsScheme `json:"scheme,omitempty"`
+
+	// TLS configures the TLS client used when the Scheme is HTTPS. Fields that are not set use the
+	// TLS configuration of the endpoint picker. The files must be in the directory that the endpoint
+	// picker allows with its --scrapeCredentialsDir flag.
+	//
+	// +optional
+	TLS *MetricsTLSConfig `json:"tls,omitempty"`
+
+	// BearerTokenFile is the path, in the endpoint picker container, of a file holding a bearer
+	// token sent with the scrapes, which must use HTTPS. The file must be in the directory that the
+	// endpoint picker allows with its --scrapeCredentialsDir flag. It is re-read when it changes, so
+	// that the token can be rotated. Defaults to the bearer token file of the endpoint picker.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxLength=1024
+	BearerTokenFile *string `json:"bearerTokenFile,omitempty"`
+
+	// Protocol selects a built-in metric mapping for a well known model server. When unspecified,
+	// the protocol configured on the endpoint picker is used.
+	//
+	// +optional
+	Protocol *ModelServerProtocol `json:"protocol,omitempty"`
+
+	// Mapping maps the signals used by the endpoint picker to the metrics exposed by the model
+	// servers. Signals that are not specified use the mapping of the Protocol.
+	//
+	// +optional
+	Mapping *ModelServerMetricMapping `json:"mapping,omitempty"`
+}
+
+// ModelServerProtocol identifies the metrics exposed by a well known model server.
+// +kubebuilder:validation:Enum=vLLM;SGLang;TGI;TritonTensorRTLLM;JetStream
+type ModelServerProtocol string
+
+const (
+	// ModelServerProtocolVLLM is the protocol of vLLM.
+	ModelServerProtocolVLLM ModelServerProtocol = "vLLM"
+	// ModelServerProtocolSGLang is the protocol of SGLang.
+	ModelServerProtocolSGLang ModelServerProtocol = "SGLang"
+	// ModelServerProtocolTGI is the protocol of Text Generation Inference.
+	ModelServerProtocolTGI ModelServerProtocol = "TGI"
+	// ModelServerProtocolTritonTensorRTLLM is the protocol of Triton with the TensorRT-LLM backend.
+	ModelServerProtocolTritonTensorRTLLM ModelServerProtocol = "TritonTensorRTLLM"
+	// ModelServerProtocolJetStream is the protocol of JetStream.
+	ModelServerProtocolJetStream ModelServerProtocol = "JetStream"
+)
+
+// MetricsTLSConfig configures the TLS client scraping the metrics endpoint. The files are read
+// from the endpoint picker container, where they are usually mounted from Secrets.
+//
+// +kubebuilder:validation:XValidation:rule="has(self.certFile) == has(self.keyFile)",message="certFile and keyFile must be set together"
+type MetricsTLSConfig struct {
+	// CAFile is the path of the PEM encoded CA bundle verifying the certificates of the model
+	// servers. Defaults to the system roots.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxLength=1024
+	CAFile *string `json:"caFile,omitempty"`
+
+	// CertFile is the path of the PEM encoded client certificate presented to the model servers.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxLength=1024
+	CertFile *string `json:"certFile,omitempty"`
+
+	// KeyFile is the path of the PEM encoded private key of the client certificate.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxLength=1024
+	KeyFile *string `json:"keyFile,omitempty"`
+
+	// ServerName is the name used to verify the certificates of the model servers, which are
+	// scraped by IP address. Defaults to the IP address of the pod.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxLength=253
+	ServerName *string `json:"serverName,omitempty"`
+
+	// InsecureSkipVerify disables the verification of the certificates of the model servers.
+	//
+	// +optional
+	InsecureSkipVerify *bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// MetricsScheme is the scheme used to scrape the metrics endpoint.
+// +kubebuilder:validation:Enum=HTTP;HTTPS
+type MetricsScheme string
+
+const (
+	// MetricsSchemeHTTP scrapes the metrics endpoint over plain HTTP.
+	MetricsSchemeHTTP MetricsScheme = "HTTP"
+	// MetricsSchemeHTTPS scrapes the metrics endpoint over HTTPS.
+	MetricsSchemeHTTPS MetricsScheme = "HTTPS"
+)
+
+// ModelServerMetricMapping maps the signals used by the endpoint picker to Prometheus metrics.
+// Each value is a metric name, optionally followed by a set of label matchers, for example
+// "vllm:num_requests_waiting" or "tgi_queue_size{model=llama}".
+type ModelServerMetricMapping struct {
+	// TotalQueuedRequests is the metric for the number of requests waiting in the queue.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxLength=1024
+	TotalQueuedRequests string `json:"totalQueuedRequests,omitempty"`
+
+	// KVCacheUtilization is the metric for the fraction of KV-cache blocks in use (from 0 to 1).
+	//
+	// +optional
+	// +kubebuilder:validation:MaxLength=1024
+	KVCacheUtilization string `json:"kvCacheUtilization,omitempty"`
+
+	// LoRARequestInfo is the metric for the LoRA adapter info, in the vLLM label format.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxLength=1024
+	LoRARequestInfo string `json:"loraRequestInfo,omitempty"`
+}
+
 // EndpointPickerConfig specifies the configuration needed by the proxy to discover and connect to the endpoint picker extension.
 // This type is intended to be a union of mutually exclusive configuration options that we may add in the future.
 type EndpointPickerConfig struct {
@@ -157,6 +303,45 @@ type InferencePoolStatus struct {
 	//
 	// +kubebuilder:validation:MaxItems=32
 	Parents []PoolStatus `json:"parent,omitempty"`
+
+	// EndpointPicker is the state of the pool as observed by the endpoint picker
+	// serving it. It is written by the endpoint picker, not by Gateways.
+	//
+	// +optional
+	EndpointPicker *EndpointPickerStatus `json:"endpointPicker,omitempty"`
+}
+
+// EndpointPickerStatus defines the observed state of InferencePool from its endpoint picker.
+type EndpointPickerStatus struct {
+	// ReadyEndpoints is the number of endpoints of the pool whose metrics are fresh. Endpoints
+	// with stale metrics are counted in StaleMetricsEndpoints instead.
+	//
+	// +kubebuilder:validation:Minimum=0
+	ReadyEndpoints int32 `json:"readyEndpoints"`
+
+	// StaleMetricsEndpoints is the number of endpoints of the pool whose metrics
+	// have not been refreshed recently.
+	//
+	// +kubebuilder:validation:Minimum=0
+	StaleMetricsEndpoints int32 `json:"staleMetricsEndpoints"`
+
+	// LastSyncTime is the last time the endpoint picker updated this status. The
+	// status is only written when it changes, or every few minutes otherwise.
+	//
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// Conditions track the state of the endpoint picker.
+	//
+	// Known condition types are:
+	//
+	// * "Ready"
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:MaxItems=8
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // PoolStatus defines the observed state of InferencePool from a Gateway.
@@ -244,3 +429,31 @@ const (
 	// or API group, or a reference to a resource that can not be found.
 	InferencePoolReasonInvalidExtensionRef InferencePoolReason = "InvalidExtensionRef"
 )
+
+const (
+	// This condition indicates whether the endpoint picker is able to pick
+	// endpoints for requests routed to the InferencePool.
+	//
+	// Possible reasons for this condition to be True are:
+	//
+	// * "Ready"
+	//
+	// Possible reasons for this condition to be False are:
+	//
+	// * "NoReadyEndpoints"
+	// * "NotServing"
+	//
+	EndpointPickerConditionReady InferencePoolConditionType = "Ready"
+
+	// This reason is used with the "Ready" condition when the endpoint picker
+	// serves requests and has at least one ready endpoint to route to.
+	EndpointPickerReasonReady InferencePoolReason = "Ready"
+
+	// This reason is used with the "Ready" condition when the endpoint picker
+	// has no endpoint with fresh metrics to route to.
+	EndpointPickerReasonNoReadyEndpoints InferencePoolReason = "NoReadyEndpoints"
+
+	// This reason is used with the "Ready" condition when the ext-proc server
+	// of the endpoint picker is not serving requests.
+	EndpointPickerReasonNotServing InferencePoolReason = "NotServing"
+)
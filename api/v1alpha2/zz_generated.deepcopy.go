@@ -21,6 +21,7 @@ limitations under the License.
 package v1alpha2
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -45,6 +46,32 @@ func (in *EndpointPickerConfig) DeepCopy() *EndpointPickerConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointPickerStatus) DeepCopyInto(out *EndpointPickerStatus) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointPickerStatus.
+func (in *EndpointPickerStatus) DeepCopy() *EndpointPickerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointPickerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Extension) DeepCopyInto(out *Extension) {
 	*out = *in
@@ -174,6 +201,11 @@ func (in *InferenceModelList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InferenceModelSpec) DeepCopyInto(out *InferenceModelSpec) {
 	*out = *in
+	if in.Aliases != nil {
+		in, out := &in.Aliases, &out.Aliases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Criticality != nil {
 		in, out := &in.Criticality, &out.Criticality
 		*out = new(Criticality)
@@ -186,6 +218,18 @@ func (in *InferenceModelSpec) DeepCopyInto(out *InferenceModelSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(RequestLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Mutations != nil {
+		in, out := &in.Mutations, &out.Mutations
+		*out = make([]RequestMutation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	out.PoolRef = in.PoolRef
 }
 
@@ -290,6 +334,11 @@ func (in *InferencePoolSpec) DeepCopyInto(out *InferencePoolSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(ModelServerMetrics)
+		(*in).DeepCopyInto(*out)
+	}
 	in.EndpointPickerConfig.DeepCopyInto(&out.EndpointPickerConfig)
 }
 
@@ -313,6 +362,11 @@ func (in *InferencePoolStatus) DeepCopyInto(out *InferencePoolStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.EndpointPicker != nil {
+		in, out := &in.EndpointPicker, &out.EndpointPicker
+		*out = new(EndpointPickerStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InferencePoolStatus.
@@ -325,6 +379,111 @@ func (in *InferencePoolStatus) DeepCopy() *InferencePoolStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsTLSConfig) DeepCopyInto(out *MetricsTLSConfig) {
+	*out = *in
+	if in.CAFile != nil {
+		in, out := &in.CAFile, &out.CAFile
+		*out = new(string)
+		**out = **in
+	}
+	if in.CertFile != nil {
+		in, out := &in.CertFile, &out.CertFile
+		*out = new(string)
+		**out = **in
+	}
+	if in.KeyFile != nil {
+		in, out := &in.KeyFile, &out.KeyFile
+		*out = new(string)
+		**out = **in
+	}
+	if in.ServerName != nil {
+		in, out := &in.ServerName, &out.ServerName
+		*out = new(string)
+		**out = **in
+	}
+	if in.InsecureSkipVerify != nil {
+		in, out := &in.InsecureSkipVerify, &out.InsecureSkipVerify
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsTLSConfig.
+func (in *MetricsTLSConfig) DeepCopy() *MetricsTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelServerMetricMapping) DeepCopyInto(out *ModelServerMetricMapping) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelServerMetricMapping.
+func (in *ModelServerMetricMapping) DeepCopy() *ModelServerMetricMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelServerMetricMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelServerMetrics) DeepCopyInto(out *ModelServerMetrics) {
+	*out = *in
+	if in.PortNumber != nil {
+		in, out := &in.PortNumber, &out.PortNumber
+		*out = new(PortNumber)
+		**out = **in
+	}
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(string)
+		**out = **in
+	}
+	if in.Scheme != nil {
+		in, out := &in.Scheme, &out.Scheme
+		*out = new(MetricsScheme)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(MetricsTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BearerTokenFile != nil {
+		in, out := &in.BearerTokenFile, &out.BearerTokenFile
+		*out = new(string)
+		**out = **in
+	}
+	if in.Protocol != nil {
+		in, out := &in.Protocol, &out.Protocol
+		*out = new(ModelServerProtocol)
+		**out = **in
+	}
+	if in.Mapping != nil {
+		in, out := &in.Mapping, &out.Mapping
+		*out = new(ModelServerMetricMapping)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelServerMetrics.
+func (in *ModelServerMetrics) DeepCopy() *ModelServerMetrics {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelServerMetrics)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PoolObjectReference) DeepCopyInto(out *PoolObjectReference) {
 	*out = *in
@@ -363,6 +522,76 @@ func (in *PoolStatus) DeepCopy() *PoolStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequestLimits) DeepCopyInto(out *RequestLimits) {
+	*out = *in
+	if in.MaxBodyBytes != nil {
+		in, out := &in.MaxBodyBytes, &out.MaxBodyBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxMessages != nil {
+		in, out := &in.MaxMessages, &out.MaxMessages
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxTokens != nil {
+		in, out := &in.MaxTokens, &out.MaxTokens
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxN != nil {
+		in, out := &in.MaxN, &out.MaxN
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxPromptLength != nil {
+		in, out := &in.MaxPromptLength, &out.MaxPromptLength
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestLimits.
+func (in *RequestLimits) DeepCopy() *RequestLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(RequestLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequestMutation) DeepCopyInto(out *RequestMutation) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		*out = new(string)
+		**out = **in
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestMutation.
+func (in *RequestMutation) DeepCopy() *RequestMutation {
+	if in == nil {
+		return nil
+	}
+	out := new(RequestMutation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TargetModel) DeepCopyInto(out *TargetModel) {
 	*out = *in
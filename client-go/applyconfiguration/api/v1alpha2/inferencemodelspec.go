@@ -25,8 +25,11 @@ import (
 // with apply.
 type InferenceModelSpecApplyConfiguration struct {
 	ModelName    *string                                `json:"modelName,omitempty"`
+	Aliases      []string                               `json:"aliases,omitempty"`
 	Criticality  *apiv1alpha2.Criticality               `json:"criticality,omitempty"`
 	TargetModels []TargetModelApplyConfiguration        `json:"targetModels,omitempty"`
+	Limits       *RequestLimitsApplyConfiguration       `json:"limits,omitempty"`
+	Mutations    []RequestMutationApplyConfiguration    `json:"mutations,omitempty"`
 	PoolRef      *PoolObjectReferenceApplyConfiguration `json:"poolRef,omitempty"`
 }
 
@@ -44,6 +47,16 @@ func (b *InferenceModelSpecApplyConfiguration) WithModelName(value string) *Infe
 	return b
 }
 
+// WithAliases adds the given value to the Aliases field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Aliases field.
+func (b *InferenceModelSpecApplyConfiguration) WithAliases(values ...string) *InferenceModelSpecApplyConfiguration {
+	for i := range values {
+		b.Aliases = append(b.Aliases, values[i])
+	}
+	return b
+}
+
 // WithCriticality sets the Criticality field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the Criticality field is set to the value of the last call.
@@ -65,6 +78,27 @@ func (b *InferenceModelSpecApplyConfiguration) WithTargetModels(values ...*Targe
 	return b
 }
 
+// WithLimits sets the Limits field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Limits field is set to the value of the last call.
+func (b *InferenceModelSpecApplyConfiguration) WithLimits(value *RequestLimitsApplyConfiguration) *InferenceModelSpecApplyConfiguration {
+	b.Limits = value
+	return b
+}
+
+// WithMutations adds the given value to the Mutations field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Mutations field.
+func (b *InferenceModelSpecApplyConfiguration) WithMutations(values ...*RequestMutationApplyConfiguration) *InferenceModelSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithMutations")
+		}
+		b.Mutations = append(b.Mutations, *values[i])
+	}
+	return b
+}
+
 // WithPoolRef sets the PoolRef field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the PoolRef field is set to the value of the last call.
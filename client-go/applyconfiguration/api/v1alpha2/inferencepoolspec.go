@@ -26,6 +26,7 @@ import (
 type InferencePoolSpecApplyConfiguration struct {
 	Selector                               map[apiv1alpha2.LabelKey]apiv1alpha2.LabelValue `json:"selector,omitempty"`
 	TargetPortNumber                       *int32                                          `json:"targetPortNumber,omitempty"`
+	Metrics                                *ModelServerMetricsApplyConfiguration           `json:"metrics,omitempty"`
 	EndpointPickerConfigApplyConfiguration `json:",inline"`
 }
 
@@ -57,6 +58,14 @@ func (b *InferencePoolSpecApplyConfiguration) WithTargetPortNumber(value int32)
 	return b
 }
 
+// WithMetrics sets the Metrics field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Metrics field is set to the value of the last call.
+func (b *InferencePoolSpecApplyConfiguration) WithMetrics(value *ModelServerMetricsApplyConfiguration) *InferencePoolSpecApplyConfiguration {
+	b.Metrics = value
+	return b
+}
+
 // WithExtensionRef sets the ExtensionRef field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the ExtensionRef field is set to the value of the last call.
@@ -20,7 +20,8 @@ package v1alpha2
 // InferencePoolStatusApplyConfiguration represents a declarative configuration of the InferencePoolStatus type for use
 // with apply.
 type InferencePoolStatusApplyConfiguration struct {
-	Parents []PoolStatusApplyConfiguration `json:"parent,omitempty"`
+	Parents        []PoolStatusApplyConfiguration          `json:"parent,omitempty"`
+	EndpointPicker *EndpointPickerStatusApplyConfiguration `json:"endpointPicker,omitempty"`
 }
 
 // InferencePoolStatusApplyConfiguration constructs a declarative configuration of the InferencePoolStatus type for use with
@@ -41,3 +42,11 @@ func (b *InferencePoolStatusApplyConfiguration) WithParents(values ...*PoolStatu
 	}
 	return b
 }
+
+// WithEndpointPicker sets the EndpointPicker field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the EndpointPicker field is set to the value of the last call.
+func (b *InferencePoolStatusApplyConfiguration) WithEndpointPicker(value *EndpointPickerStatusApplyConfiguration) *InferencePoolStatusApplyConfiguration {
+	b.EndpointPicker = value
+	return b
+}
@@ -33,6 +33,8 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 	// Group=inference.networking.x-k8s.io, Version=v1alpha2
 	case v1alpha2.SchemeGroupVersion.WithKind("EndpointPickerConfig"):
 		return &apiv1alpha2.EndpointPickerConfigApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("EndpointPickerStatus"):
+		return &apiv1alpha2.EndpointPickerStatusApplyConfiguration{}
 	case v1alpha2.SchemeGroupVersion.WithKind("Extension"):
 		return &apiv1alpha2.ExtensionApplyConfiguration{}
 	case v1alpha2.SchemeGroupVersion.WithKind("ExtensionConnection"):
@@ -51,10 +53,20 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &apiv1alpha2.InferencePoolSpecApplyConfiguration{}
 	case v1alpha2.SchemeGroupVersion.WithKind("InferencePoolStatus"):
 		return &apiv1alpha2.InferencePoolStatusApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("MetricsTLSConfig"):
+		return &apiv1alpha2.MetricsTLSConfigApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("ModelServerMetricMapping"):
+		return &apiv1alpha2.ModelServerMetricMappingApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("ModelServerMetrics"):
+		return &apiv1alpha2.ModelServerMetricsApplyConfiguration{}
 	case v1alpha2.SchemeGroupVersion.WithKind("PoolObjectReference"):
 		return &apiv1alpha2.PoolObjectReferenceApplyConfiguration{}
 	case v1alpha2.SchemeGroupVersion.WithKind("PoolStatus"):
 		return &apiv1alpha2.PoolStatusApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("RequestLimits"):
+		return &apiv1alpha2.RequestLimitsApplyConfiguration{}
+	case v1alpha2.SchemeGroupVersion.WithKind("RequestMutation"):
+		return &apiv1alpha2.RequestMutationApplyConfiguration{}
 	case v1alpha2.SchemeGroupVersion.WithKind("TargetModel"):
 		return &apiv1alpha2.TargetModelApplyConfiguration{}
 
@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -36,9 +37,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
 	"sigs.k8s.io/gateway-api-inference-extension/internal/runnable"
 	backendmetrics "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/backend/metrics"
 	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/datastore"
+	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/handlers"
 	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/metrics"
 	runserver "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/server"
 	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/logging"
@@ -80,10 +84,111 @@ var (
 		"refreshMetricsInterval",
 		runserver.DefaultRefreshMetricsInterval,
 		"interval to refresh metrics")
+	scrapeWorkers = flag.Int(
+		"scrapeWorkers",
+		runserver.DefaultScrapeWorkers,
+		"maximum number of model server pods scraped for metrics concurrently")
+	scrapeMaxBackoff = flag.Duration(
+		"scrapeMaxBackoff",
+		runserver.DefaultScrapeMaxBackoff,
+		"maximum interval between metrics scrapes of a pod that repeatedly fails to be scraped")
+	scrapeScheme = flag.String(
+		"scrapeScheme",
+		string(v1alpha2.MetricsSchemeHTTP),
+		"scheme of the metrics scrapes, HTTP or HTTPS. Used unless the InferencePool sets spec.metrics.scheme.")
+	scrapeCAFile = flag.String(
+		"scrapeCAFile",
+		"",
+		"path of the PEM encoded CA bundle verifying the model servers scraped over HTTPS. Defaults to the system roots.")
+	scrapeCertFile = flag.String(
+		"scrapeCertFile",
+		"",
+		"path of the PEM encoded client certificate presented to the model servers scraped over HTTPS. "+
+			"Re-read when it changes.")
+	scrapeKeyFile = flag.String(
+		"scrapeKeyFile",
+		"",
+		"path of the PEM encoded private key of the scrape client certificate. Re-read when it changes.")
+	scrapeServerName = flag.String(
+		"scrapeServerName",
+		"",
+		"name used to verify the certificates of the model servers, which are scraped by IP address.")
+	scrapeInsecureSkipVerify = flag.Bool(
+		"scrapeInsecureSkipVerify",
+		false,
+		"disables the verification of the certificates of the model servers scraped over HTTPS.")
+	scrapeBearerTokenFile = flag.String(
+		"scrapeBearerTokenFile",
+		"",
+		"path of a file holding a bearer token sent with the metrics scrapes over HTTPS. Re-read when it changes.")
+	scrapeCredentialsDir = flag.String(
+		"scrapeCredentialsDir",
+		"",
+		"directory holding the CA, client certificate, key and bearer token files that the InferencePool may set in "+
+			"spec.metrics. The pool files are refused when unset.")
+	queueSmoothing = flag.String(
+		"queueSmoothing",
+		runserver.DefaultQueueSmoothing,
+		"smoothing of the queue size of the pods: 'none', 'ewma:<half-life>', 'avg:<window>' or 'max:<window>', e.g. 'ewma:1s'")
+	kvCacheSmoothing = flag.String(
+		"kvCacheSmoothing",
+		runserver.DefaultKVCacheSmoothing,
+		"smoothing of the KV cache usage of the pods: 'none', 'ewma:<half-life>', 'avg:<window>' or 'max:<window>', e.g. 'max:500ms'")
 	refreshPrometheusMetricsInterval = flag.Duration(
 		"refreshPrometheusMetricsInterval",
 		runserver.DefaultRefreshPrometheusMetricsInterval,
 		"interval to flush prometheus metrics")
+	poolStatusUpdateInterval = flag.Duration(
+		"poolStatusUpdateInterval",
+		runserver.DefaultPoolStatusUpdateInterval,
+		"interval to publish the endpoint picker status on the InferencePool. Set to 0 to disable.")
+	enableLeaderElection = flag.Bool(
+		"enableLeaderElection",
+		runserver.DefaultEnableLeaderElection,
+		"Enables leader election so that only one replica writes InferenceModel status. All replicas keep serving requests.")
+	enableValidationWebhook = flag.Bool(
+		"enableValidationWebhook",
+		runserver.DefaultEnableValidationWebhook,
+		"Enables the validating admission webhook for InferenceModel and InferencePool.")
+	injectStreamUsage = flag.Bool(
+		"injectStreamUsage",
+		runserver.DefaultInjectStreamUsage,
+		"Injects stream_options.include_usage into streamed requests so token usage is always accounted. "+
+			"The usage-only event is removed from the response if the client didn't ask for it. Requests whose body "+
+			"is passed through with --modelNameHeader are left unchanged.")
+	filterModelsByCapacity = flag.Bool(
+		"filterModelsByCapacity",
+		runserver.DefaultFilterModelsByCapacity,
+		"Lists only the InferenceModels that a pod with a healthy metrics scrape can currently serve in GET /v1/models responses.")
+	unregisteredModelPolicy = flag.String(
+		"unregisteredModelPolicy",
+		string(runserver.DefaultUnregisteredModelPolicy),
+		"How requests for models without an InferenceModel are handled: 'reject' with a 404, 'passthrough' "+
+			"to route them unchanged as sheddable, or 'default' to serve them with the InferenceModel of --defaultModelName.")
+	defaultModelName = flag.String(
+		"defaultModelName",
+		"",
+		"The modelName of the InferenceModel serving unregistered models with --unregisteredModelPolicy=default.")
+	modelNameHeader = flag.String(
+		"modelNameHeader",
+		"",
+		"The request header holding the model name, e.g. X-Gateway-Model-Name. Requests with the header are "+
+			"scheduled before their body is received, and their body is passed through unchanged unless the target "+
+			"model must be rewritten or the InferenceModel has mutations or limits beyond the body size. Passed through "+
+			"bodies don't get --injectStreamUsage. Disabled if empty.")
+	maxRequestBodyBytes = flag.Int64(
+		"maxRequestBodyBytes",
+		0,
+		"The maximum size of a request body in bytes. Larger requests are rejected with a 413 before their "+
+			"body is buffered. Unlimited if 0.")
+	webhookPort = flag.Int(
+		"webhookPort",
+		runserver.DefaultWebhookPort,
+		"The port the validating admission webhook is served on.")
+	webhookCertDir = flag.String(
+		"webhookCertDir", "", "The directory containing the certificate for the validating admission webhook. "+
+			"The certificate and private key files are assumed to be named tls.crt and tls.key, respectively. "+
+			"If not set, the controller-runtime default directory is used.")
 	logVerbosity  = flag.Int("v", logging.DEFAULT, "number for the log level verbosity")
 	secureServing = flag.Bool(
 		"secureServing", runserver.DefaultSecureServing, "Enables secure serving. Defaults to true.")
@@ -92,16 +197,24 @@ var (
 			"are assumed to be named tls.crt and tls.key, respectively. If not set, and secureServing is enabled, "+
 			"then a self-signed certificate is used.")
 	// metric flags
+	modelServerProtocol = flag.String("modelServerProtocol",
+		string(v1alpha2.ModelServerProtocolVLLM),
+		"The protocol of the model servers, selecting the built-in metric mapping. One of vLLM, SGLang, TGI, "+
+			"TritonTensorRTLLM or JetStream. Metric flags that are set explicitly override the mapping.")
 	totalQueuedRequestsMetric = flag.String("totalQueuedRequestsMetric",
 		"vllm:num_requests_waiting",
-		"Prometheus metric for the number of queued requests.")
+		"Prometheus metric, or ORCA metric prefixed with 'orca.', for the number of queued requests. Used unless the InferencePool maps it in spec.metrics.mapping.")
 	kvCacheUsagePercentageMetric = flag.String("kvCacheUsagePercentageMetric",
 		"vllm:gpu_cache_usage_perc",
-		"Prometheus metric for the fraction of KV-cache blocks currently in use (from 0 to 1).")
+		"Prometheus metric, or ORCA metric prefixed with 'orca.', for the fraction of KV-cache blocks currently in use (from 0 to 1). Used unless the InferencePool maps it in spec.metrics.mapping.")
 	// LoRA metrics
 	loraInfoMetric = flag.String("loraInfoMetric",
 		"vllm:lora_requests_info",
-		"Prometheus metric for the LoRA info metrics (must be in vLLM label format).")
+		"Prometheus metric for the LoRA info metrics (must be in vLLM label format). Used unless the InferencePool maps it in spec.metrics.mapping.")
+	customMetrics = flag.String("customMetrics",
+		"",
+		"Semicolon-separated name=spec pairs of custom metrics to scrape, such as 'batch_fill_ratio=batch_fill_ratio{model=llama}'. "+
+			"Their values can be referenced as custom.<name> in the scheduler expressions.")
 
 	setupLog = ctrl.Log.WithName("setup")
 )
@@ -140,7 +253,14 @@ func run() error {
 		return err
 	}
 
-	mgr, err := runserver.NewDefaultManager(*poolNamespace, *poolName, cfg)
+	mgrOpts := runserver.DefaultManagerOptions(*poolNamespace, *poolName, *enableLeaderElection)
+	if *enableValidationWebhook {
+		mgrOpts.WebhookServer = webhook.NewServer(webhook.Options{
+			Port:    *webhookPort,
+			CertDir: *webhookCertDir,
+		})
+	}
+	mgr, err := runserver.NewManagerWithOptions(cfg, mgrOpts)
 	if err != nil {
 		setupLog.Error(err, "Failed to create controller manager")
 		return err
@@ -149,18 +269,39 @@ func run() error {
 	ctx := ctrl.SetupSignalHandler()
 
 	// Set up mapper for metric scraping.
-	mapping, err := backendmetrics.NewMetricMapping(
-		*totalQueuedRequestsMetric,
-		*kvCacheUsagePercentageMetric,
-		*loraInfoMetric,
-	)
+	mapping, err := metricMappingFromFlags()
 	if err != nil {
 		setupLog.Error(err, "Failed to create metric mapping from flags.")
 		return err
 	}
 	verifyMetricMapping(*mapping, setupLog)
 
-	pmf := backendmetrics.NewPodMetricsFactory(&backendmetrics.PodMetricsClientImpl{MetricMapping: mapping}, *refreshMetricsInterval)
+	smoothing, err := smoothingFromFlags()
+	if err != nil {
+		setupLog.Error(err, "Failed to parse metrics smoothing from flags.")
+		return err
+	}
+
+	pmc := &backendmetrics.PodMetricsClientImpl{
+		MetricMapping: mapping,
+		Scheme:        v1alpha2.MetricsScheme(strings.ToUpper(*scrapeScheme)),
+		TLS: backendmetrics.ScrapeTLSConfig{
+			CAFile:             *scrapeCAFile,
+			CertFile:           *scrapeCertFile,
+			KeyFile:            *scrapeKeyFile,
+			ServerName:         *scrapeServerName,
+			InsecureSkipVerify: *scrapeInsecureSkipVerify,
+		},
+		BearerTokenFile:    *scrapeBearerTokenFile,
+		PoolCredentialsDir: *scrapeCredentialsDir,
+	}
+	pmf := backendmetrics.NewPodMetricsFactoryWithOptions(pmc, backendmetrics.ScrapeOptions{
+		Interval:   *refreshMetricsInterval,
+		Workers:    *scrapeWorkers,
+		Jitter:     backendmetrics.DefaultScrapeJitter,
+		MaxBackoff: *scrapeMaxBackoff,
+		Smoothing:  smoothing,
+	})
 	// Setup runner.
 	datastore := datastore.NewDatastore(ctx, pmf)
 
@@ -174,6 +315,15 @@ func run() error {
 		SecureServing:                            *secureServing,
 		CertPath:                                 *certPath,
 		RefreshPrometheusMetricsInterval:         *refreshPrometheusMetricsInterval,
+		PoolStatusUpdateInterval:                 *poolStatusUpdateInterval,
+		EnableValidationWebhook:                  *enableValidationWebhook,
+		InjectStreamUsage:                        *injectStreamUsage,
+		FilterModelsByCapacity:                   *filterModelsByCapacity,
+		UnregisteredModelPolicy:                  handlers.UnregisteredModelPolicy(*unregisteredModelPolicy),
+		DefaultModelName:                         *defaultModelName,
+		ModelNameHeader:                          *modelNameHeader,
+		MaxRequestBodyBytes:                      *maxRequestBodyBytes,
+		UseSmoothedMetrics:                       smoothing.Enabled(),
 	}
 	if err := serverRunner.SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "Failed to setup ext-proc controllers")
@@ -296,10 +446,91 @@ func validateFlags() error {
 	if *poolName == "" {
 		return fmt.Errorf("required %q flag not set", "poolName")
 	}
+	if scheme := v1alpha2.MetricsScheme(strings.ToUpper(*scrapeScheme)); scheme != v1alpha2.MetricsSchemeHTTP && scheme != v1alpha2.MetricsSchemeHTTPS {
+		return fmt.Errorf("invalid %q flag %q, must be HTTP or HTTPS", "scrapeScheme", *scrapeScheme)
+	}
+	if *scrapeBearerTokenFile != "" && v1alpha2.MetricsScheme(strings.ToUpper(*scrapeScheme)) != v1alpha2.MetricsSchemeHTTPS {
+		return fmt.Errorf("%q flag requires the %q flag to be HTTPS", "scrapeBearerTokenFile", "scrapeScheme")
+	}
+	if (*scrapeCertFile == "") != (*scrapeKeyFile == "") {
+		return fmt.Errorf("%q and %q flags must be set together", "scrapeCertFile", "scrapeKeyFile")
+	}
+	policy, err := handlers.ParseUnregisteredModelPolicy(*unregisteredModelPolicy)
+	if err != nil {
+		return fmt.Errorf("invalid %q flag: %w", "unregisteredModelPolicy", err)
+	}
+	if policy == handlers.UnregisteredModelDefault && *defaultModelName == "" {
+		return fmt.Errorf("%q flag must be set with %q policy %q", "defaultModelName", "unregisteredModelPolicy", policy)
+	}
+	if *refreshMetricsInterval <= 0 {
+		return fmt.Errorf("%q flag must be positive", "refreshMetricsInterval")
+	}
+	if *maxRequestBodyBytes < 0 {
+		return fmt.Errorf("%q flag must not be negative", "maxRequestBodyBytes")
+	}
 
 	return nil
 }
 
+// smoothingFromFlags returns the smoothing of the metrics used for scheduling.
+func smoothingFromFlags() (backendmetrics.SmoothingConfig, error) {
+	queue, err := backendmetrics.ParseSmoothingSpec(*queueSmoothing)
+	if err != nil {
+		return backendmetrics.SmoothingConfig{}, fmt.Errorf("invalid --queueSmoothing: %w", err)
+	}
+	kvCache, err := backendmetrics.ParseSmoothingSpec(*kvCacheSmoothing)
+	if err != nil {
+		return backendmetrics.SmoothingConfig{}, fmt.Errorf("invalid --kvCacheSmoothing: %w", err)
+	}
+	return backendmetrics.SmoothingConfig{WaitingQueueSize: queue, KVCacheUsagePercent: kvCache}, nil
+}
+
+// metricMappingFromFlags returns the mapping of the model server protocol, overridden by the metric
+// flags that are set explicitly.
+func metricMappingFromFlags() (*backendmetrics.MetricMapping, error) {
+	protocol, err := backendmetrics.ParseModelServerProtocol(*modelServerProtocol)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := backendmetrics.MetricMappingForProtocol(protocol)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	explicit := func(name, value string) string {
+		if set[name] {
+			return value
+		}
+		return ""
+	}
+	overrides, err := backendmetrics.NewMetricMapping(
+		explicit("totalQueuedRequestsMetric", *totalQueuedRequestsMetric),
+		explicit("kvCacheUsagePercentageMetric", *kvCacheUsagePercentageMetric),
+		explicit("loraInfoMetric", *loraInfoMetric),
+	)
+	if err != nil {
+		return nil, err
+	}
+	// An explicitly empty flag disables the metric.
+	if set["totalQueuedRequestsMetric"] {
+		mapping.TotalQueuedRequests = overrides.TotalQueuedRequests
+	}
+	if set["kvCacheUsagePercentageMetric"] {
+		mapping.KVCacheUtilization = overrides.KVCacheUtilization
+	}
+	if set["loraInfoMetric"] {
+		mapping.LoraRequestInfo = overrides.LoraRequestInfo
+	}
+	if mapping.Custom, err = backendmetrics.ParseCustomMetricSpecs(*customMetrics); err != nil {
+		return nil, fmt.Errorf("invalid --customMetrics: %w", err)
+	}
+	return mapping, nil
+}
+
 func verifyMetricMapping(mapping backendmetrics.MetricMapping, logger logr.Logger) {
 	if mapping.TotalQueuedRequests == nil {
 		logger.Info("Not scraping metric: TotalQueuedRequests")
@@ -310,5 +541,8 @@ func verifyMetricMapping(mapping backendmetrics.MetricMapping, logger logr.Logge
 	if mapping.LoraRequestInfo == nil {
 		logger.Info("Not scraping metric: LoraRequestInfo")
 	}
+	for name, spec := range mapping.Custom {
+		logger.Info("Scraping custom metric", "name", name, "metric", spec.MetricName)
+	}
 
 }
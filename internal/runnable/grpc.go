@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sync/atomic"
 
 	"google.golang.org/grpc"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -13,6 +14,12 @@ import (
 // GRPCServer converts the given gRPC server into a runnable.
 // The server name is just being used for logging.
 func GRPCServer(name string, srv *grpc.Server, port int) manager.Runnable {
+	return ServingGRPCServer(name, srv, port, nil)
+}
+
+// ServingGRPCServer converts the given gRPC server into a runnable, like GRPCServer. It also
+// reports in serving, if not nil, whether the server is listening.
+func ServingGRPCServer(name string, srv *grpc.Server, port int, serving *atomic.Bool) manager.Runnable {
 	return manager.RunnableFunc(func(ctx context.Context) error {
 		// Use "name" key as that is what manager.Server does as well.
 		log := ctrl.Log.WithValues("name", name)
@@ -26,6 +33,10 @@ func GRPCServer(name string, srv *grpc.Server, port int) manager.Runnable {
 		}
 
 		log.Info("gRPC server listening", "port", port)
+		if serving != nil {
+			serving.Store(true)
+			defer serving.Store(false)
+		}
 
 		// Shutdown on context closed.
 		// Terminate the server on context closed.
@@ -0,0 +1,401 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"google.golang.org/protobuf/proto"
+)
+
+// scrapeAcceptHeader negotiates the exposition format of the metrics endpoints, preferring the
+// formats that are the cheapest to parse.
+const scrapeAcceptHeader = `application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.7,` +
+	`application/openmetrics-text;version=1.0.0;q=0.6,` +
+	`text/plain;version=0.0.4;q=0.5,*/*;q=0.1`
+
+// maxExpositionLineSize bounds the size of a line of the text formats.
+const maxExpositionLineSize = 1 << 20
+
+// expositionFormat is the format of the body of a metrics endpoint.
+type expositionFormat int
+
+const (
+	// formatText is the Prometheus text format, also used when the Content-Type is unknown.
+	formatText expositionFormat = iota
+	// formatOpenMetrics is the OpenMetrics text format.
+	formatOpenMetrics
+	// formatProtoDelim is the length-delimited protobuf format.
+	formatProtoDelim
+)
+
+func (f expositionFormat) String() string {
+	switch f {
+	case formatOpenMetrics:
+		return "openmetrics"
+	case formatProtoDelim:
+		return "protobuf"
+	default:
+		return "text"
+	}
+}
+
+// responseFormat returns the exposition format of a response with the Content-Type.
+func responseFormat(contentType string) expositionFormat {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return formatText
+	}
+	switch mediaType {
+	case expfmt.OpenMetricsType:
+		return formatOpenMetrics
+	case expfmt.ProtoType:
+		if params["proto"] == expfmt.ProtoProtocol && params["encoding"] == "delimited" {
+			return formatProtoDelim
+		}
+	}
+	return formatText
+}
+
+// parseMetricFamilies parses the metric families of the wanted metrics, or all of them if wanted is
+// nil. The parsing stops as soon as all the wanted metrics were found. The families are keyed by the
+// names of their samples, as in the Prometheus text format, so that the counters of the OpenMetrics
+// format are found with their "_total" suffix.
+func parseMetricFamilies(r io.Reader, format expositionFormat, wanted map[string]bool) (map[string]*dto.MetricFamily, error) {
+	if format == formatProtoDelim {
+		return parseProtoDelim(r, wanted)
+	}
+	p := &textExpositionParser{
+		openMetrics: format == formatOpenMetrics,
+		wanted:      wanted,
+		families:    map[string]*dto.MetricFamily{},
+		found:       map[string]bool{},
+	}
+	return p.parse(r)
+}
+
+func parseProtoDelim(r io.Reader, wanted map[string]bool) (map[string]*dto.MetricFamily, error) {
+	families := map[string]*dto.MetricFamily{}
+	decoder := expfmt.NewDecoder(r, expfmt.NewFormat(expfmt.TypeProtoDelim))
+	for wanted == nil || len(families) < len(wanted) {
+		mf := &dto.MetricFamily{}
+		if err := decoder.Decode(mf); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		for _, series := range seriesFamilies(mf) {
+			if wanted == nil || wanted[series.GetName()] {
+				families[series.GetName()] = series
+			}
+		}
+	}
+	return families, nil
+}
+
+// seriesFamilies splits the histograms and summaries into untyped families named after their
+// series, as they are parsed from the text formats. The other families are returned as is.
+func seriesFamilies(mf *dto.MetricFamily) []*dto.MetricFamily {
+	if mf.GetType() != dto.MetricType_HISTOGRAM && mf.GetType() != dto.MetricType_SUMMARY {
+		return []*dto.MetricFamily{mf}
+	}
+	series := map[string]*dto.MetricFamily{}
+	add := func(suffix string, m *dto.Metric, value float64, labels ...*dto.LabelPair) {
+		name := mf.GetName() + suffix
+		if series[name] == nil {
+			series[name] = &dto.MetricFamily{Name: proto.String(name), Type: dto.MetricType_UNTYPED.Enum()}
+		}
+		series[name].Metric = append(series[name].Metric, &dto.Metric{
+			Label:       append(append([]*dto.LabelPair{}, m.GetLabel()...), labels...),
+			Untyped:     &dto.Untyped{Value: proto.Float64(value)},
+			TimestampMs: m.TimestampMs,
+		})
+	}
+	for _, m := range mf.GetMetric() {
+		if h := m.GetHistogram(); h != nil {
+			add("_count", m, float64(h.GetSampleCount()))
+			add("_sum", m, h.GetSampleSum())
+			for _, b := range h.GetBucket() {
+				le := strconv.FormatFloat(b.GetUpperBound(), 'g', -1, 64)
+				add("_bucket", m, float64(b.GetCumulativeCount()), &dto.LabelPair{Name: proto.String("le"), Value: proto.String(le)})
+			}
+		}
+		if summary := m.GetSummary(); summary != nil {
+			add("_count", m, float64(summary.GetSampleCount()))
+			add("_sum", m, summary.GetSampleSum())
+		}
+	}
+	result := make([]*dto.MetricFamily, 0, len(series))
+	for _, family := range series {
+		result = append(result, family)
+	}
+	return result
+}
+
+// textExpositionParser parses the Prometheus and OpenMetrics text formats line by line, only
+// keeping the samples of the wanted metrics. Histograms and summaries are kept as untyped samples
+// named after their series, such as "latency_seconds_count".
+type textExpositionParser struct {
+	openMetrics bool
+	wanted      map[string]bool
+
+	families map[string]*dto.MetricFamily
+	// found are the wanted metrics with at least a sample.
+	found map[string]bool
+	// family and familyType are the name and type of the metric family being parsed.
+	family     string
+	familyType dto.MetricType
+}
+
+func (p *textExpositionParser) parse(r io.Reader) (map[string]*dto.MetricFamily, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxExpositionLineSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var err error
+		var done bool
+		if strings.HasPrefix(line, "#") {
+			done, err = p.parseComment(line)
+		} else {
+			done, err = p.parseSample(line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid line %q: %w", line, err)
+		}
+		if done {
+			return p.families, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return p.families, nil
+}
+
+// complete returns true if all the wanted metrics were found.
+func (p *textExpositionParser) complete() bool {
+	return p.wanted != nil && len(p.found) == len(p.wanted)
+}
+
+// startFamily switches to a new metric family, done is true if all the wanted metrics were found
+// in the previous ones.
+func (p *textExpositionParser) startFamily(name string, metricType dto.MetricType) (done bool) {
+	if name == p.family {
+		return false
+	}
+	p.family, p.familyType = name, metricType
+	return p.complete()
+}
+
+func (p *textExpositionParser) parseComment(line string) (bool, error) {
+	fields := strings.Fields(strings.TrimPrefix(line, "#"))
+	if len(fields) == 1 && fields[0] == "EOF" && p.openMetrics {
+		return true, nil
+	}
+	if len(fields) < 2 {
+		return false, nil
+	}
+	switch fields[0] {
+	case "TYPE":
+		if len(fields) < 3 {
+			return false, errors.New("missing metric type")
+		}
+		if p.startFamily(fields[1], p.familyType) {
+			return true, nil
+		}
+		p.familyType = metricTypes[strings.ToLower(fields[2])]
+	case "HELP", "UNIT":
+		if p.startFamily(fields[1], dto.MetricType_UNTYPED) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// metricTypes maps the types of the text formats to the protobuf ones. The types that are missing
+// are parsed as untyped.
+var metricTypes = map[string]dto.MetricType{
+	"counter": dto.MetricType_COUNTER,
+	"gauge":   dto.MetricType_GAUGE,
+}
+
+// sampleSuffixes are the suffixes of the series of the metric families.
+var sampleSuffixes = []string{"_total", "_created", "_bucket", "_sum", "_count", "_gcount", "_gsum", "_info"}
+
+func (p *textExpositionParser) parseSample(line string) (bool, error) {
+	name, rest := splitMetricName(line)
+	if name == "" {
+		return false, errors.New("missing metric name")
+	}
+	if !p.inFamily(name) && p.startFamily(name, dto.MetricType_UNTYPED) {
+		return true, nil
+	}
+	if p.openMetrics && name == p.family+"_created" {
+		// The creation time of the OpenMetrics counters, histograms and summaries.
+		return false, nil
+	}
+	if p.wanted != nil && !p.wanted[name] {
+		return false, nil
+	}
+
+	labels, rest, err := parseLabels(rest)
+	if err != nil {
+		return false, err
+	}
+	if p.openMetrics {
+		// Drop the exemplar.
+		if i := strings.Index(rest, "#"); i >= 0 {
+			rest = rest[:i]
+		}
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || len(fields) > 2 {
+		return false, errors.New("expected a value and an optional timestamp")
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid value: %w", err)
+	}
+	metric := &dto.Metric{Label: labels}
+	if len(fields) == 2 {
+		ts, err := p.parseTimestamp(fields[1])
+		if err != nil {
+			return false, err
+		}
+		metric.TimestampMs = proto.Int64(ts)
+	}
+
+	metricType := dto.MetricType_UNTYPED
+	switch {
+	case p.familyType == dto.MetricType_GAUGE && name == p.family:
+		metricType = dto.MetricType_GAUGE
+		metric.Gauge = &dto.Gauge{Value: proto.Float64(value)}
+	case p.familyType == dto.MetricType_COUNTER && (name == p.family || name == p.family+"_total"):
+		metricType = dto.MetricType_COUNTER
+		metric.Counter = &dto.Counter{Value: proto.Float64(value)}
+	default:
+		metric.Untyped = &dto.Untyped{Value: proto.Float64(value)}
+	}
+
+	mf, ok := p.families[name]
+	if !ok {
+		mf = &dto.MetricFamily{Name: proto.String(name), Type: metricType.Enum()}
+		p.families[name] = mf
+	}
+	mf.Metric = append(mf.Metric, metric)
+	p.found[name] = true
+	return false, nil
+}
+
+// inFamily returns true if the sample belongs to the metric family being parsed.
+func (p *textExpositionParser) inFamily(name string) bool {
+	if name == p.family {
+		return true
+	}
+	if suffix, ok := strings.CutPrefix(name, p.family); ok && p.family != "" {
+		for _, s := range sampleSuffixes {
+			if suffix == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseTimestamp returns the timestamp in milliseconds, the OpenMetrics timestamps being seconds.
+func (p *textExpositionParser) parseTimestamp(s string) (int64, error) {
+	if !p.openMetrics {
+		ts, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp: %w", err)
+		}
+		return ts, nil
+	}
+	ts, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(ts) || math.IsInf(ts, 0) {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	return int64(ts * 1000), nil
+}
+
+// splitMetricName splits a sample line after the metric name.
+func splitMetricName(line string) (name, rest string) {
+	end := strings.IndexAny(line, "{ \t")
+	if end < 0 {
+		return line, ""
+	}
+	return line[:end], line[end:]
+}
+
+// parseLabels parses the optional label set at the start of s, and returns the rest of s.
+func parseLabels(s string) ([]*dto.LabelPair, string, error) {
+	if !strings.HasPrefix(s, "{") {
+		return nil, s, nil
+	}
+	var labels []*dto.LabelPair
+	i := 1
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == ',') {
+			i++
+		}
+		if i < len(s) && s[i] == '}' {
+			return labels, s[i+1:], nil
+		}
+		eq := strings.IndexByte(s[i:], '=')
+		if eq < 0 {
+			return nil, "", errors.New("invalid label set")
+		}
+		name := strings.TrimSpace(s[i : i+eq])
+		i += eq + 1
+		if i >= len(s) || s[i] != '"' {
+			return nil, "", fmt.Errorf("label %q has no quoted value", name)
+		}
+		var value strings.Builder
+		i++
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(s[i])
+				}
+				continue
+			}
+			value.WriteByte(s[i])
+		}
+		if i >= len(s) {
+			return nil, "", fmt.Errorf("label %q has an unterminated value", name)
+		}
+		i++
+		labels = append(labels, &dto.LabelPair{Name: proto.String(name), Value: proto.String(value.String())})
+	}
+}
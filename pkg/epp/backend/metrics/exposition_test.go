@@ -0,0 +1,306 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+	logutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/logging"
+)
+
+const testPrometheusText = `# HELP vllm:num_requests_waiting Number of requests waiting.
+# TYPE vllm:num_requests_waiting gauge
+vllm:num_requests_waiting{model_name="llama"} 3 1700000000000
+# HELP vllm:generation_tokens_total Number of generation tokens.
+# TYPE vllm:generation_tokens_total counter
+vllm:generation_tokens_total{model_name="llama"} 1200
+# HELP vllm:e2e_request_latency_seconds Latency.
+# TYPE vllm:e2e_request_latency_seconds histogram
+vllm:e2e_request_latency_seconds_bucket{le="1.0"} 4
+vllm:e2e_request_latency_seconds_bucket{le="+Inf"} 5
+vllm:e2e_request_latency_seconds_sum 3.5
+vllm:e2e_request_latency_seconds_count 5
+vllm:lora_requests_info{max_lora="4",running_lora_adapters="a,b",waiting_lora_adapters=""} 1.7e+09
+`
+
+const testOpenMetricsText = `# HELP vllm:num_requests_waiting Number of requests waiting.
+# TYPE vllm:num_requests_waiting gauge
+vllm:num_requests_waiting{model_name="llama"} 3 1700000000.0
+# HELP vllm:generation_tokens Number of generation tokens.
+# TYPE vllm:generation_tokens counter
+# UNIT vllm:generation_tokens tokens
+vllm:generation_tokens_total{model_name="llama"} 1200 # {trace_id="abc \"quoted\""} 1.0 1700000000.5
+vllm:generation_tokens_created{model_name="llama"} 1.6e+09
+# TYPE vllm:e2e_request_latency_seconds histogram
+vllm:e2e_request_latency_seconds_bucket{le="1.0"} 4
+vllm:e2e_request_latency_seconds_bucket{le="+Inf"} 5
+vllm:e2e_request_latency_seconds_sum 3.5
+vllm:e2e_request_latency_seconds_count 5
+vllm:e2e_request_latency_seconds_created 1.6e+09
+# TYPE vllm:lora_requests_info gauge
+vllm:lora_requests_info{max_lora="4",running_lora_adapters="a,b",waiting_lora_adapters=""} 1.7e+09
+# EOF
+`
+
+// testProtoDelim returns the metrics of testPrometheusText in the protobuf delimited format.
+func testProtoDelim(t *testing.T) []byte {
+	t.Helper()
+	parser := expfmt.TextParser{}
+	families, err := parser.TextToMetricFamilies(strings.NewReader(testPrometheusText))
+	if err != nil {
+		t.Fatalf("Failed to parse metrics: %v", err)
+	}
+	var buf bytes.Buffer
+	encoder := expfmt.NewEncoder(&buf, expfmt.NewFormat(expfmt.TypeProtoDelim))
+	for _, mf := range families {
+		if err := encoder.Encode(mf); err != nil {
+			t.Fatalf("Failed to encode metrics: %v", err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestResponseFormat(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        expositionFormat
+	}{
+		{contentType: "", want: formatText},
+		{contentType: "text/plain; version=0.0.4; charset=utf-8", want: formatText},
+		{contentType: "application/openmetrics-text; version=1.0.0; charset=utf-8", want: formatOpenMetrics},
+		{contentType: "application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=delimited", want: formatProtoDelim},
+		{contentType: "application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=text", want: formatText},
+		{contentType: "invalid;;", want: formatText},
+	}
+	for _, tt := range tests {
+		if got := responseFormat(tt.contentType); got != tt.want {
+			t.Errorf("responseFormat(%q) = %v, want %v", tt.contentType, got, tt.want)
+		}
+	}
+}
+
+func TestParseMetricFamilies(t *testing.T) {
+	wanted := map[string]bool{
+		"vllm:num_requests_waiting":              true,
+		"vllm:generation_tokens_total":           true,
+		"vllm:e2e_request_latency_seconds_count": true,
+		"vllm:lora_requests_info":                true,
+	}
+	tests := []struct {
+		name   string
+		format expositionFormat
+		body   []byte
+	}{
+		{name: "text", format: formatText, body: []byte(testPrometheusText)},
+		{name: "openmetrics", format: formatOpenMetrics, body: []byte(testOpenMetricsText)},
+		{name: "protobuf", format: formatProtoDelim, body: testProtoDelim(t)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			families, err := parseMetricFamilies(bytes.NewReader(tt.body), tt.format, wanted)
+			if err != nil {
+				t.Fatalf("parseMetricFamilies() unexpected error: %v", err)
+			}
+			p := &PodMetricsClientImpl{}
+			for name, want := range map[string]float64{
+				"vllm:num_requests_waiting":              3,
+				"vllm:generation_tokens_total":           1200,
+				"vllm:e2e_request_latency_seconds_count": 5,
+			} {
+				got, err := p.getMetricValue(families, MetricSpec{MetricName: name}, nil)
+				if err != nil || got != want {
+					t.Errorf("getMetricValue(%q) = %v, %v, want %v", name, got, err, want)
+				}
+			}
+			if got := families["vllm:generation_tokens_total"].GetType(); got != dto.MetricType_COUNTER {
+				t.Errorf("vllm:generation_tokens_total type = %v, want COUNTER", got)
+			}
+			if got := families["vllm:num_requests_waiting"].GetMetric()[0].GetTimestampMs(); got != 1700000000000 {
+				t.Errorf("vllm:num_requests_waiting timestamp = %v, want 1700000000000", got)
+			}
+			lora, err := p.getLatestLoraMetric(families, &MetricMapping{LoraRequestInfo: &MetricSpec{MetricName: "vllm:lora_requests_info"}})
+			if err != nil || lora == nil {
+				t.Fatalf("getLatestLoraMetric() = %v, %v", lora, err)
+			}
+			if _, ok := families["vllm:e2e_request_latency_seconds_bucket"]; ok {
+				t.Errorf("parseMetricFamilies() parsed a family that isn't wanted")
+			}
+		})
+	}
+}
+
+func TestParseMetricFamiliesAll(t *testing.T) {
+	families, err := parseMetricFamilies(strings.NewReader(testOpenMetricsText), formatOpenMetrics, nil)
+	if err != nil {
+		t.Fatalf("parseMetricFamilies() unexpected error: %v", err)
+	}
+	var names []string
+	for name := range families {
+		names = append(names, name)
+	}
+	want := []string{
+		"vllm:e2e_request_latency_seconds_bucket",
+		"vllm:e2e_request_latency_seconds_count",
+		"vllm:e2e_request_latency_seconds_sum",
+		"vllm:generation_tokens_total",
+		"vllm:lora_requests_info",
+		"vllm:num_requests_waiting",
+	}
+	if diff := cmp.Diff(want, names, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("Unexpected families (-want +got): %s", diff)
+	}
+	bucket := families["vllm:e2e_request_latency_seconds_bucket"].GetMetric()[1]
+	if got := bucket.GetLabel()[0]; got.GetName() != "le" || got.GetValue() != "+Inf" {
+		t.Errorf("Unexpected bucket label %v", got)
+	}
+}
+
+func TestParseMetricFamiliesStopsEarly(t *testing.T) {
+	// Reading past the second family fails.
+	body := io.MultiReader(strings.NewReader(`# TYPE queue gauge
+queue 2
+# TYPE kv_cache gauge
+kv_cache 0.5
+# TYPE other gauge
+`), iotestErrReader{})
+	wanted := map[string]bool{"queue": true, "kv_cache": true}
+	for _, format := range []expositionFormat{formatText, formatOpenMetrics} {
+		families, err := parseMetricFamilies(body, format, wanted)
+		if err != nil {
+			t.Fatalf("parseMetricFamilies(%v) unexpected error: %v", format, err)
+		}
+		if len(families) != 2 {
+			t.Errorf("parseMetricFamilies(%v) = %v, want 2 families", format, families)
+		}
+		body = io.MultiReader(strings.NewReader("queue 2\nkv_cache{a=\"b\"} 0.5\nother 1\n"), iotestErrReader{})
+	}
+}
+
+type iotestErrReader struct{}
+
+func (iotestErrReader) Read([]byte) (int, error) {
+	return 0, errors.New("read past the wanted metrics")
+}
+
+func TestParseMetricFamiliesErrors(t *testing.T) {
+	wanted := map[string]bool{"queue": true}
+	for _, body := range []string{
+		"queue{a=b} 1",
+		"queue{a=\"b} 1",
+		"queue",
+		"queue one",
+		"queue 1 2 3",
+		"queue 1 1.5",
+		"# TYPE queue",
+	} {
+		if _, err := parseMetricFamilies(strings.NewReader(body), formatText, wanted); err == nil {
+			t.Errorf("parseMetricFamilies(%q) expected error", body)
+		}
+	}
+	// The samples that aren't wanted aren't parsed.
+	if _, err := parseMetricFamilies(strings.NewReader("other{a=b} 1\nqueue 1\n"), formatText, wanted); err != nil {
+		t.Errorf("parseMetricFamilies() unexpected error: %v", err)
+	}
+}
+
+func TestFetchMetricsContentNegotiation(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+	}{
+		{name: "text", contentType: "text/plain; version=0.0.4; charset=utf-8", body: []byte(testPrometheusText)},
+		{name: "openmetrics", contentType: "application/openmetrics-text; version=1.0.0; charset=utf-8", body: []byte(testOpenMetricsText)},
+		{name: "protobuf", contentType: string(expfmt.NewFormat(expfmt.TypeProtoDelim)), body: testProtoDelim(t)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if accept := r.Header.Get("Accept"); !strings.Contains(accept, "application/openmetrics-text") {
+					t.Errorf("Unexpected Accept header %q", accept)
+				}
+				w.Header().Set("Content-Type", tt.contentType)
+				_, _ = w.Write(tt.body)
+			}))
+			defer server.Close()
+
+			serverURL, err := url.Parse(server.URL)
+			if err != nil {
+				t.Fatalf("Failed to parse server URL: %v", err)
+			}
+			port, err := strconv.Atoi(serverURL.Port())
+			if err != nil {
+				t.Fatalf("Failed to parse server port: %v", err)
+			}
+			ctx := logutil.NewTestLoggerIntoContext(context.Background())
+			pool := &v1alpha2.InferencePool{Spec: v1alpha2.InferencePoolSpec{TargetPortNumber: int32(port)}}
+			mapping := &MetricMapping{
+				TotalQueuedRequests: &MetricSpec{MetricName: "vllm:num_requests_waiting"},
+				TokenThroughput:     &MetricSpec{MetricName: "vllm:generation_tokens_total", Rate: true},
+				LoraRequestInfo:     &MetricSpec{MetricName: "vllm:lora_requests_info"},
+			}
+			p := &PodMetricsClientImpl{MetricMapping: mapping}
+			got, err := p.FetchMetrics(ctx, &Pod{Address: serverURL.Hostname()}, &Metrics{}, pool)
+			if err != nil {
+				t.Fatalf("FetchMetrics() unexpected error: %v", err)
+			}
+			want := &Metrics{
+				WaitingQueueSize: 3,
+				ActiveModels:     map[string]int{"a": 0, "b": 0},
+				WaitingModels:    map[string]int{},
+				MaxActiveModels:  4,
+				CounterSamples:   map[string]CounterSample{"vllm:generation_tokens_total": {Value: 1200, Time: got.CounterSamples["vllm:generation_tokens_total"].Time}},
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Unexpected metrics (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestMetricNames(t *testing.T) {
+	mapping := &MetricMapping{
+		TotalQueuedRequests: &MetricSpec{MetricName: "orca.named_metrics.queue"},
+		KVCacheUtilization: &MetricSpec{
+			MetricName: "used",
+			Over:       &MetricSpec{MetricName: "max"},
+		},
+		LoraRequestInfo: &MetricSpec{MetricName: "lora"},
+		Custom:          map[string]*MetricSpec{"fill": {MetricName: "fill_ratio"}},
+	}
+	want := map[string]bool{"used": true, "max": true, "lora": true, "fill_ratio": true}
+	if diff := cmp.Diff(want, mapping.metricNames()); diff != "" {
+		t.Errorf("Unexpected metric names (-want +got): %s", diff)
+	}
+	if names := (*MetricMapping)(nil).metricNames(); names != nil {
+		t.Errorf("metricNames() = %v, want nil", names)
+	}
+}
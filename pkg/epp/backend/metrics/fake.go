@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"sync"
 
+	xdsorca "github.com/cncf/xds/go/xds/data/orca/v3"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -32,6 +33,7 @@ import (
 type FakePodMetrics struct {
 	Pod     *Pod
 	Metrics *Metrics
+	Health  *ScrapeHealth
 }
 
 func (fpm *FakePodMetrics) String() string {
@@ -44,10 +46,14 @@ func (fpm *FakePodMetrics) GetPod() *Pod {
 func (fpm *FakePodMetrics) GetMetrics() *Metrics {
 	return fpm.Metrics
 }
+func (fpm *FakePodMetrics) GetScrapeHealth() *ScrapeHealth {
+	return fpm.Health
+}
 func (fpm *FakePodMetrics) UpdatePod(pod *corev1.Pod) {
 	fpm.Pod = toInternalPod(pod)
 }
-func (fpm *FakePodMetrics) StopRefreshLoop() {} // noop
+func (fpm *FakePodMetrics) UpdateLoadReport(*xdsorca.OrcaLoadReport) {} // noop
+func (fpm *FakePodMetrics) StopRefreshLoop()                         {} // noop
 
 type FakePodMetricsClient struct {
 	errMu sync.RWMutex
@@ -56,7 +62,7 @@ type FakePodMetricsClient struct {
 	Res   map[types.NamespacedName]*Metrics
 }
 
-func (f *FakePodMetricsClient) FetchMetrics(ctx context.Context, pod *Pod, existing *Metrics, port int32) (*Metrics, error) {
+func (f *FakePodMetricsClient) FetchMetrics(ctx context.Context, pod *Pod, existing *Metrics, pool *v1alpha2.InferencePool) (*Metrics, error) {
 	f.errMu.RLock()
 	err, ok := f.Err[pod.NamespacedName]
 	f.errMu.RUnlock()
@@ -29,9 +29,10 @@ import (
 )
 
 const (
+	// MetricsValidityPeriod is how long scraped metrics are considered fresh.
 	// Note currently the EPP treats stale metrics same as fresh.
 	// TODO: https://github.com/kubernetes-sigs/gateway-api-inference-extension/issues/336
-	metricsValidityPeriod = 5 * time.Second
+	MetricsValidityPeriod = 5 * time.Second
 	debugPrintInterval    = 5 * time.Second
 )
 
@@ -73,10 +74,10 @@ func StartMetricsLogger(ctx context.Context, datastore Datastore, refreshPrometh
 					return
 				case <-ticker.C:
 					podsWithFreshMetrics := datastore.PodList(func(pm PodMetrics) bool {
-						return time.Since(pm.GetMetrics().UpdateTime) <= metricsValidityPeriod
+						return !IsStale(pm, time.Now())
 					})
 					podsWithStaleMetrics := datastore.PodList(func(pm PodMetrics) bool {
-						return time.Since(pm.GetMetrics().UpdateTime) > metricsValidityPeriod
+						return IsStale(pm, time.Now())
 					})
 					s := fmt.Sprintf("Current Pods and metrics gathered. Fresh metrics: %+v, Stale metrics: %+v", podsWithFreshMetrics, podsWithStaleMetrics)
 					logger.V(logutil.VERBOSE).Info(s)
@@ -86,6 +87,11 @@ func StartMetricsLogger(ctx context.Context, datastore Datastore, refreshPrometh
 	}
 }
 
+// IsStale returns true if the metrics of the given pod were not refreshed within MetricsValidityPeriod.
+func IsStale(pm PodMetrics, now time.Time) bool {
+	return now.Sub(pm.GetMetrics().UpdateTime) > MetricsValidityPeriod
+}
+
 func flushPrometheusMetricsOnce(logger logr.Logger, datastore Datastore) {
 	pool, err := datastore.PoolGet()
 	if err != nil {
@@ -103,13 +109,26 @@ func flushPrometheusMetricsOnce(logger logr.Logger, datastore Datastore) {
 		return
 	}
 
+	failingPods := 0
+	podsMissingMetric := map[string]int{}
 	for _, pod := range podMetrics {
 		kvCacheTotal += pod.GetMetrics().KVCacheUsagePercent
 		queueTotal += pod.GetMetrics().WaitingQueueSize
+		health := pod.GetScrapeHealth()
+		if !health.Healthy() {
+			failingPods++
+		}
+		if health != nil {
+			for _, metric := range health.MissingMetrics {
+				podsMissingMetric[metric]++
+			}
+		}
 	}
 
 	podTotalCount := len(podMetrics)
 	metrics.RecordInferencePoolAvgKVCache(pool.Name, kvCacheTotal/float64(podTotalCount))
 	metrics.RecordInferencePoolAvgQueueSize(pool.Name, float64(queueTotal/podTotalCount))
 	metrics.RecordinferencePoolReadyPods(pool.Name, float64(podTotalCount))
+	metrics.RecordInferencePoolScrapeFailingPods(pool.Name, float64(failingPods))
+	metrics.RecordInferencePoolPodsMissingMetrics(pool.Name, podsMissingMetric)
 }
@@ -18,14 +18,20 @@ package metrics
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	dto "github.com/prometheus/client_model/go"
-	"github.com/prometheus/common/expfmt"
 	"go.uber.org/multierr"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
 )
 
 const (
@@ -33,10 +39,37 @@ const (
 	LoraInfoRunningAdaptersMetricName = "running_lora_adapters"
 	LoraInfoWaitingAdaptersMetricName = "waiting_lora_adapters"
 	LoraInfoMaxAdaptersMetricName     = "max_lora"
+
+	// DefaultMetricsPath is the path of the metrics endpoint when the InferencePool doesn't set one.
+	DefaultMetricsPath = "/metrics"
+
+	// maxDrainedBodySize bounds the unparsed part of a metrics response read to reuse the connection.
+	maxDrainedBodySize = 4 << 20
 )
 
 type PodMetricsClientImpl struct {
+	// MetricMapping is used for the signals that the InferencePool doesn't map itself.
 	MetricMapping *MetricMapping
+
+	// Client is the HTTP client used to scrape the pods. Defaults to a client with a transport tuned
+	// for frequently scraping many endpoints. It is not used to scrape over TLS with a ScrapeTLSConfig.
+	Client *http.Client
+
+	// Scheme, TLS and BearerTokenFile configure the scrapes of the pods when the InferencePool doesn't.
+	// The Scheme defaults to HTTP.
+	Scheme          v1alpha2.MetricsScheme
+	TLS             ScrapeTLSConfig
+	BearerTokenFile string
+	// PoolCredentialsDir is the directory holding the files that the InferencePools may set for their
+	// scrapes. The files set by the pools are refused if empty.
+	PoolCredentialsDir string
+
+	// poolMappings caches the mappings resolved from the InferencePool spec, keyed by poolMappingKey.
+	poolMappings sync.Map
+	// tlsClients caches the tlsClients keyed by ScrapeTLSConfig.
+	tlsClients sync.Map
+	// tokenFiles caches the bearer token files keyed by path.
+	tokenFiles sync.Map
 }
 
 // FetchMetrics fetches metrics from a given pod, clones the existing metrics object and returns an
@@ -45,22 +78,40 @@ func (p *PodMetricsClientImpl) FetchMetrics(
 	ctx context.Context,
 	pod *Pod,
 	existing *Metrics,
-	port int32,
+	pool *v1alpha2.InferencePool,
 ) (*Metrics, error) {
+	mapping, err := p.metricMapping(pool)
+	if err != nil {
+		return nil, err
+	}
 
-	// Currently the metrics endpoint is hard-coded, which works with vLLM.
-	// TODO(https://github.com/kubernetes-sigs/gateway-api-inference-extension/issues/16): Consume this from InferencePool config.
-	url := "http://" + pod.Address + ":" + strconv.Itoa(int(port)) + "/metrics"
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	scheme, tlsConfig, tokenFile, err := p.scrapeConfig(pool)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scrape configuration for %s: %w", pod.NamespacedName, err)
+	}
+	client, err := p.scrapeClient(scheme, tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS configuration to scrape %s: %w", pod.NamespacedName, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metricsURL(pod, pool, scheme), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %v", err)
 	}
-	resp, err := http.DefaultClient.Do(req)
+	if tokenFile != "" {
+		token, err := p.bearerToken(tokenFile)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Accept", scrapeAcceptHeader)
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch metrics from %s: %w", pod.NamespacedName, err)
 	}
 	defer func() {
+		// The parsing may stop before the end of the body, drain it to reuse the connection.
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainedBodySize))
 		_ = resp.Body.Close()
 	}()
 
@@ -68,44 +119,153 @@ func (p *PodMetricsClientImpl) FetchMetrics(
 		return nil, fmt.Errorf("unexpected status code from %s: %v", pod.NamespacedName, resp.StatusCode)
 	}
 
-	parser := expfmt.TextParser{}
-	metricFamilies, err := parser.TextToMetricFamilies(resp.Body)
+	format := responseFormat(resp.Header.Get("Content-Type"))
+	metricFamilies, err := parseMetricFamilies(resp.Body, format, mapping.metricNames())
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to parse the %s metrics of %s: %w", format, pod.NamespacedName, err)
+	}
+	return p.promToPodMetrics(metricFamilies, existing, mapping)
+}
+
+func (p *PodMetricsClientImpl) httpClient() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	return defaultScrapeClient
+}
+
+// defaultScrapeClient keeps connections to the model servers alive between scrapes, and bounds the
+// time spent on a single unresponsive pod.
+var defaultScrapeClient = &http.Client{Transport: defaultScrapeTransport}
+
+var defaultScrapeTransport = &http.Transport{
+	Proxy: http.ProxyFromEnvironment,
+	DialContext: (&net.Dialer{
+		Timeout:   time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext,
+	MaxIdleConns:          1000,
+	MaxIdleConnsPerHost:   2,
+	IdleConnTimeout:       90 * time.Second,
+	ResponseHeaderTimeout: 3 * time.Second,
+	DisableCompression:    true,
+}
+
+// metricsURL returns the URL of the metrics endpoint of the pod, as configured on the InferencePool.
+func metricsURL(pod *Pod, pool *v1alpha2.InferencePool, scheme string) string {
+	port, path := pool.Spec.TargetPortNumber, DefaultMetricsPath
+	if m := pool.Spec.Metrics; m != nil {
+		if m.PortNumber != nil {
+			port = int32(*m.PortNumber)
+		}
+		if m.Path != nil {
+			path = *m.Path
+		}
 	}
-	return p.promToPodMetrics(metricFamilies, existing)
+	u := url.URL{
+		Scheme: scheme,
+		Host:   net.JoinHostPort(pod.Address, strconv.Itoa(int(port))),
+		Path:   path,
+	}
+	return u.String()
+}
+
+// metricMapping returns the mapping configured on the InferencePool. Signals it doesn't map come
+// from the protocol of the pool if set, or else from the default mapping.
+func (p *PodMetricsClientImpl) metricMapping(pool *v1alpha2.InferencePool) (*MetricMapping, error) {
+	metrics := pool.Spec.Metrics
+	if metrics == nil || (metrics.Protocol == nil && metrics.Mapping == nil) {
+		return p.MetricMapping, nil
+	}
+	key := poolMappingKey{}
+	if metrics.Protocol != nil {
+		key.protocol = *metrics.Protocol
+	}
+	if metrics.Mapping != nil {
+		key.mapping = *metrics.Mapping
+	}
+	if cached, ok := p.poolMappings.Load(key); ok {
+		return cached.(*MetricMapping), nil
+	}
+
+	base := p.MetricMapping
+	if key.protocol != "" {
+		var err error
+		if base, err = MetricMappingForProtocol(key.protocol); err != nil {
+			return nil, fmt.Errorf("invalid protocol in InferencePool %s/%s: %w", pool.Namespace, pool.Name, err)
+		}
+	}
+	mapping, err := NewMetricMapping(key.mapping.TotalQueuedRequests, key.mapping.KVCacheUtilization, key.mapping.LoRARequestInfo)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metric mapping in InferencePool %s/%s: %w", pool.Namespace, pool.Name, err)
+	}
+	if base != nil {
+		if mapping.TotalQueuedRequests == nil {
+			mapping.TotalQueuedRequests = base.TotalQueuedRequests
+		}
+		if mapping.KVCacheUtilization == nil {
+			mapping.KVCacheUtilization = base.KVCacheUtilization
+		}
+		if mapping.LoraRequestInfo == nil {
+			mapping.LoraRequestInfo = base.LoraRequestInfo
+		}
+		// The InferencePool doesn't map the optional signals.
+		mapping.TotalRunningRequests = base.TotalRunningRequests
+		mapping.KVCacheMaxTokenCapacity = base.KVCacheMaxTokenCapacity
+		mapping.PrefixCacheHitRate = base.PrefixCacheHitRate
+		mapping.PreemptionRate = base.PreemptionRate
+		mapping.TokenThroughput = base.TokenThroughput
+	}
+	// The custom metrics are configured on the endpoint picker, whatever the protocol of the pool.
+	if p.MetricMapping != nil {
+		mapping.Custom = p.MetricMapping.Custom
+	}
+	p.poolMappings.Store(key, mapping)
+	return mapping, nil
+}
+
+// poolMappingKey identifies the metric mapping configured on an InferencePool.
+type poolMappingKey struct {
+	protocol v1alpha2.ModelServerProtocol
+	mapping  v1alpha2.ModelServerMetricMapping
 }
 
 // promToPodMetrics updates internal pod metrics with scraped Prometheus metrics.
 func (p *PodMetricsClientImpl) promToPodMetrics(
 	metricFamilies map[string]*dto.MetricFamily,
 	existing *Metrics,
+	mapping *MetricMapping,
 ) (*Metrics, error) {
 	var errs error
 	updated := existing.Clone()
-
-	if p.MetricMapping.TotalQueuedRequests != nil {
-		queued, err := p.getMetric(metricFamilies, *p.MetricMapping.TotalQueuedRequests)
-		if err == nil {
-			updated.WaitingQueueSize = int(queued.GetGauge().GetValue())
-		} else {
-			errs = multierr.Append(errs, err)
-		}
+	if mapping == nil {
+		return updated, nil
 	}
 
-	if p.MetricMapping.KVCacheUtilization != nil {
-		usage, err := p.getMetric(metricFamilies, *p.MetricMapping.KVCacheUtilization)
-		if err == nil {
-			updated.KVCacheUsagePercent = usage.GetGauge().GetValue()
-		} else {
-			errs = multierr.Append(errs, err)
+	rates := &rateState{now: time.Now(), prev: existing.CounterSamples}
+	for _, signal := range mapping.signals(updated) {
+		// The signals mapped to ORCA metrics are pushed by the model servers, and not scraped.
+		if signal.spec == nil || signal.spec.isORCA() {
+			continue
+		}
+		value, err := p.getMetricValue(metricFamilies, *signal.spec, rates)
+		switch {
+		case err == nil:
+			signal.set(value)
+		case errors.Is(err, errRateUnavailable):
+			// Keep the previous value until the rate can be derived.
+		default:
+			errs = multierr.Append(errs, &MissingMetricError{Metric: signal.spec.MetricName, Err: err})
 		}
 	}
+	updated.CounterSamples = rates.next
 
 	// Handle LoRA metrics (only if all LoRA MetricSpecs are present)
-	if p.MetricMapping.LoraRequestInfo != nil {
-		loraMetrics, err := p.getLatestLoraMetric(metricFamilies)
-		errs = multierr.Append(errs, err)
+	if mapping.LoraRequestInfo != nil {
+		loraMetrics, err := p.getLatestLoraMetric(metricFamilies, mapping)
+		if err != nil {
+			errs = multierr.Append(errs, &MissingMetricError{Metric: mapping.LoraRequestInfo.MetricName, Err: err})
+		}
 
 		if loraMetrics != nil {
 			updated.ActiveModels = make(map[string]int)
@@ -142,18 +302,45 @@ func (p *PodMetricsClientImpl) promToPodMetrics(
 	return updated, errs
 }
 
+// MissingMetricError reports a mapped metric that couldn't be read from the metrics scraped from a
+// pod. The other metrics of the scrape are still updated.
+type MissingMetricError struct {
+	Metric string
+	Err    error
+}
+
+func (e *MissingMetricError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *MissingMetricError) Unwrap() error {
+	return e.Err
+}
+
+// missingMetrics returns the names of the metrics reported missing by a FetchMetrics error.
+func missingMetrics(err error) []string {
+	var missing []string
+	for _, err := range multierr.Errors(err) {
+		var mme *MissingMetricError
+		if errors.As(err, &mme) {
+			missing = append(missing, mme.Metric)
+		}
+	}
+	return missing
+}
+
 // getLatestLoraMetric gets latest lora metric series in gauge metric family `vllm:lora_requests_info`
 // reason its specially fetched is because each label key value pair permutation generates new series
 // and only most recent is useful. The value of each series is the creation timestamp so we can
 // retrieve the latest by sorting the value.
-func (p *PodMetricsClientImpl) getLatestLoraMetric(metricFamilies map[string]*dto.MetricFamily) (*dto.Metric, error) {
-	if p.MetricMapping.LoraRequestInfo == nil {
+func (p *PodMetricsClientImpl) getLatestLoraMetric(metricFamilies map[string]*dto.MetricFamily, mapping *MetricMapping) (*dto.Metric, error) {
+	if mapping.LoraRequestInfo == nil {
 		return nil, nil // No LoRA metrics configured
 	}
 
-	loraRequests, ok := metricFamilies[p.MetricMapping.LoraRequestInfo.MetricName]
+	loraRequests, ok := metricFamilies[mapping.LoraRequestInfo.MetricName]
 	if !ok {
-		return nil, fmt.Errorf("metric family %q not found", p.MetricMapping.LoraRequestInfo.MetricName)
+		return nil, fmt.Errorf("metric family %q not found", mapping.LoraRequestInfo.MetricName)
 	}
 
 	var latest *dto.Metric
@@ -178,8 +365,8 @@ func (p *PodMetricsClientImpl) getLatestLoraMetric(metricFamilies map[string]*dt
 		}
 
 		// Select the metric with the *largest Gauge Value* (which represents the timestamp).
-		if m.GetGauge().GetValue() > latestTs {
-			latestTs = m.GetGauge().GetValue()
+		if metricValue(m) > latestTs {
+			latestTs = metricValue(m)
 			latest = m
 		}
 	}
@@ -204,6 +391,92 @@ func (p *PodMetricsClientImpl) getMetric(metricFamilies map[string]*dto.MetricFa
 	return getLatestMetric(mf, &spec)
 }
 
+// errRateUnavailable is returned for a Rate spec until two samples of the counter were scraped, or
+// when the denominator of a ratio of rates didn't increase between two scrapes.
+var errRateUnavailable = errors.New("rate is not available yet")
+
+// rateState holds the counter samples of the previous scrape, and collects those of the current one.
+type rateState struct {
+	now  time.Time
+	prev map[string]CounterSample
+	next map[string]CounterSample
+}
+
+// rate records the counter value of the series, and returns its per-second rate of increase since
+// the previous scrape. A counter reset is handled as an increase from zero.
+func (r *rateState) rate(key string, value float64) (float64, error) {
+	if r.next == nil {
+		r.next = map[string]CounterSample{}
+	}
+	r.next[key] = CounterSample{Value: value, Time: r.now}
+	prev, ok := r.prev[key]
+	elapsed := r.now.Sub(prev.Time).Seconds()
+	if !ok || elapsed <= 0 {
+		return 0, errRateUnavailable
+	}
+	increase := value - prev.Value
+	if increase < 0 {
+		increase = value
+	}
+	return increase / elapsed, nil
+}
+
+// getMetricValue retrieves the value of a metric based on MetricSpec, applying the derivations and
+// unit conversion of the spec. The rates are derived from the counter samples of rates, which may
+// be nil if the spec doesn't use any.
+func (p *PodMetricsClientImpl) getMetricValue(metricFamilies map[string]*dto.MetricFamily, spec MetricSpec, rates *rateState) (float64, error) {
+	m, err := p.getMetric(metricFamilies, spec)
+	if err != nil {
+		return 0, err
+	}
+	value := metricValue(m)
+	var rateErr error
+	if spec.Rate {
+		if rates == nil {
+			return 0, fmt.Errorf("rate of %q can't be derived from a single scrape", spec.MetricName)
+		}
+		// Keep going on errRateUnavailable, so that the samples of the denominator are recorded too.
+		value, rateErr = rates.rate(spec.key(), value)
+	}
+
+	if spec.Over != nil {
+		over, err := p.getMetricValue(metricFamilies, *spec.Over, rates)
+		if err != nil {
+			return 0, err
+		}
+		if rateErr != nil {
+			return 0, rateErr
+		}
+		if over == 0 {
+			if spec.Over.Rate {
+				// No increase of the denominator since the last scrape, the ratio is undefined.
+				return 0, errRateUnavailable
+			}
+			return 0, fmt.Errorf("metric %q used as a denominator for %q is zero", spec.Over.MetricName, spec.MetricName)
+		}
+		value /= over
+	}
+	if rateErr != nil {
+		return 0, rateErr
+	}
+	if spec.Scale != 0 {
+		value *= spec.Scale
+	}
+	return value, nil
+}
+
+// metricValue returns the value of a gauge, counter or untyped metric.
+func metricValue(m *dto.Metric) float64 {
+	switch {
+	case m.GetGauge() != nil:
+		return m.GetGauge().GetValue()
+	case m.GetCounter() != nil:
+		return m.GetCounter().GetValue()
+	default:
+		return m.GetUntyped().GetValue()
+	}
+}
+
 // getLabeledMetric gets the latest metric with matching labels.
 func getLatestMetric(mf *dto.MetricFamily, spec *MetricSpec) (*dto.Metric, error) {
 	var latestMetric *dto.Metric
@@ -18,6 +18,8 @@ package metrics
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -25,6 +27,35 @@ import (
 type MetricSpec struct {
 	MetricName string
 	Labels     map[string]string // Label name -> Label value
+
+	// Scale is applied to the value of the metric, for example 0.01 to convert a percentage to a
+	// fraction. Zero means no scaling.
+	Scale float64
+	// Over, if set, derives the value as a ratio over the value of another metric, for servers that
+	// only expose totals.
+	Over *MetricSpec
+	// Rate, if set, derives the value as the per-second rate of increase of a counter between two
+	// scrapes. The value is only available from the second scrape.
+	Rate bool
+}
+
+// key identifies the time series selected by the spec.
+func (s *MetricSpec) key() string {
+	if len(s.Labels) == 0 {
+		return s.MetricName
+	}
+	pairs := make([]string, 0, len(s.Labels))
+	for name, value := range s.Labels {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return s.MetricName + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// isORCA returns true if the spec refers to a metric of the ORCA load reports of the model server,
+// rather than to a Prometheus metric.
+func (s *MetricSpec) isORCA() bool {
+	return s != nil && strings.HasPrefix(s.MetricName, ORCAMetricPrefix)
 }
 
 // MetricMapping holds named MetricSpecs.
@@ -32,12 +63,80 @@ type MetricMapping struct {
 	TotalQueuedRequests *MetricSpec
 	KVCacheUtilization  *MetricSpec
 	LoraRequestInfo     *MetricSpec
+
+	// The following signals are optional, they are not used for scheduling yet.
+	TotalRunningRequests    *MetricSpec
+	KVCacheMaxTokenCapacity *MetricSpec
+	// PrefixCacheHitRate is the fraction (from 0 to 1) of the prompt tokens found in the prefix cache.
+	PrefixCacheHitRate *MetricSpec
+	// PreemptionRate is the number of requests preempted per second, usually a Rate spec.
+	PreemptionRate *MetricSpec
+	// TokenThroughput is the number of tokens generated per second, usually a Rate spec.
+	TokenThroughput *MetricSpec
+
+	// Custom are the user-named signals of the model servers, stored in Metrics.Custom under the
+	// same names.
+	Custom map[string]*MetricSpec
+}
+
+// signal is a numeric field of Metrics read through a MetricSpec.
+type signal struct {
+	spec *MetricSpec
+	set  func(float64)
+}
+
+// signals returns the numeric signals of the mapping, setting the fields of m. The signals that are
+// not mapped have a nil spec.
+func (mapping *MetricMapping) signals(m *Metrics) []signal {
+	signals := []signal{
+		{spec: mapping.TotalQueuedRequests, set: func(v float64) { m.WaitingQueueSize = int(v) }},
+		{spec: mapping.KVCacheUtilization, set: func(v float64) { m.KVCacheUsagePercent = v }},
+		{spec: mapping.TotalRunningRequests, set: func(v float64) { m.RunningQueueSize = int(v) }},
+		{spec: mapping.KVCacheMaxTokenCapacity, set: func(v float64) { m.KvCacheMaxTokenCapacity = int(v) }},
+		{spec: mapping.PrefixCacheHitRate, set: func(v float64) { m.PrefixCacheHitRate = v }},
+		{spec: mapping.PreemptionRate, set: func(v float64) { m.PreemptionRate = v }},
+		{spec: mapping.TokenThroughput, set: func(v float64) { m.TokenThroughput = v }},
+	}
+	names := make([]string, 0, len(mapping.Custom))
+	for name := range mapping.Custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		signals = append(signals, signal{spec: mapping.Custom[name], set: func(v float64) {
+			if m.Custom == nil {
+				m.Custom = map[string]float64{}
+			}
+			m.Custom[name] = v
+		}})
+	}
+	return signals
+}
+
+// metricNames returns the names of the Prometheus metrics that the mapping reads, including the
+// denominators of the ratios. It returns nil for a nil mapping.
+func (mapping *MetricMapping) metricNames() map[string]bool {
+	if mapping == nil {
+		return nil
+	}
+	names := map[string]bool{}
+	specs := []*MetricSpec{mapping.LoraRequestInfo}
+	for _, signal := range mapping.signals(&Metrics{}) {
+		specs = append(specs, signal.spec)
+	}
+	for _, spec := range specs {
+		for ; spec != nil && !spec.isORCA(); spec = spec.Over {
+			names[spec.MetricName] = true
+		}
+	}
+	return names
 }
 
 // stringToMetricSpec converts a string to a MetricSpec.
 // Example inputs:
 //
 //	"metric_name"
+//	"rate(metric_name_total)"
 //	"metric_name{label1=value1}"
 //	"metric_name{label1=value1,label2=value2}"
 func stringToMetricSpec(specStr string) (*MetricSpec, error) {
@@ -45,6 +144,20 @@ func stringToMetricSpec(specStr string) (*MetricSpec, error) {
 		return nil, nil // Allow empty strings to represent nil MetricSpecs
 	}
 	specStr = strings.TrimSpace(specStr)
+	if inner, ok := strings.CutPrefix(specStr, "rate("); ok {
+		if !strings.HasSuffix(inner, ")") {
+			return nil, fmt.Errorf("invalid metric spec string: %q, missing closing parenthesis", specStr)
+		}
+		spec, err := stringToMetricSpec(strings.TrimSuffix(inner, ")"))
+		if err != nil {
+			return nil, err
+		}
+		if spec == nil {
+			return nil, fmt.Errorf("empty metric name in spec: %q", specStr)
+		}
+		spec.Rate = true
+		return spec, nil
+	}
 	metricName := specStr
 	labels := make(map[string]string)
 
@@ -106,6 +219,14 @@ func NewMetricMapping(queuedStr, kvUsageStr, loraReqInfoStr string) (*MetricMapp
 	if err != nil {
 		return nil, fmt.Errorf("error parsing loraReqInfoStr: %w", err)
 	}
+	for _, spec := range []*MetricSpec{queuedSpec, kvUsageSpec} {
+		if err := validateORCA(spec); err != nil {
+			return nil, err
+		}
+	}
+	if loraReqInfoSpec.isORCA() {
+		return nil, fmt.Errorf("LoRA request info can't be read from ORCA metric %q", loraReqInfoSpec.MetricName)
+	}
 	mapping := &MetricMapping{
 		TotalQueuedRequests: queuedSpec,
 		KVCacheUtilization:  kvUsageSpec,
@@ -114,3 +235,61 @@ func NewMetricMapping(queuedStr, kvUsageStr, loraReqInfoStr string) (*MetricMapp
 
 	return mapping, nil
 }
+
+// validateORCA checks that a spec referring to an ORCA metric can be read from the load reports.
+func validateORCA(spec *MetricSpec) error {
+	if !spec.isORCA() {
+		return nil
+	}
+	if len(spec.Labels) > 0 {
+		return fmt.Errorf("ORCA metric %q can't select labels", spec.MetricName)
+	}
+	if spec.Rate {
+		return fmt.Errorf("rate of ORCA metric %q can't be derived, ORCA reports carry gauges", spec.MetricName)
+	}
+	return nil
+}
+
+// customMetricNameRegexp matches the names of the custom metrics, which must be usable as
+// identifiers in the scheduler expressions.
+var customMetricNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ParseCustomMetricSpecs parses the custom metrics of a MetricMapping from a list of
+// semicolon-separated name=spec pairs, where spec uses the syntax of the other metric flags.
+// Example input:
+//
+//	"batch_fill_ratio=batch_fill_ratio{model=llama};admitted=rate(admitted_requests_total)"
+func ParseCustomMetricSpecs(specsStr string) (map[string]*MetricSpec, error) {
+	if strings.TrimSpace(specsStr) == "" {
+		return nil, nil
+	}
+	specs := make(map[string]*MetricSpec)
+	for _, pair := range strings.Split(specsStr, ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, specStr, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid custom metric %q, must be name=spec", pair)
+		}
+		name = strings.TrimSpace(name)
+		if !customMetricNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid custom metric name %q, must match %s", name, customMetricNameRegexp)
+		}
+		if _, ok := specs[name]; ok {
+			return nil, fmt.Errorf("duplicate custom metric %q", name)
+		}
+		spec, err := stringToMetricSpec(specStr)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing custom metric %q: %w", name, err)
+		}
+		if spec == nil {
+			return nil, fmt.Errorf("empty spec for custom metric %q", name)
+		}
+		if err := validateORCA(spec); err != nil {
+			return nil, err
+		}
+		specs[name] = spec
+	}
+	return specs, nil
+}
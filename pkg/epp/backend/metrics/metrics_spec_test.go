@@ -66,6 +66,30 @@ func TestStringToMetricSpec(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:  "rate",
+			input: "rate(my_metric_total{label1=value1})",
+			want: &MetricSpec{
+				MetricName: "my_metric_total",
+				Labels: map[string]string{
+					"label1": "value1",
+				},
+				Rate: true,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "rate missing closing parenthesis",
+			input:   "rate(my_metric_total",
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "rate of nothing",
+			input:   "rate()",
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name:  "extra whitespace",
 			input: "  my_metric  {  label1  =  value1  ,  label2  =  value2  }  ",
@@ -168,3 +192,76 @@ func TestStringToMetricSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCustomMetricSpecs(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]*MetricSpec
+		wantErr bool
+	}{
+		{
+			name:  "empty string",
+			input: "",
+			want:  nil,
+		},
+		{
+			name:  "multiple metrics",
+			input: "batch_fill_ratio=batch_fill_ratio{model=llama,pool=a}; admitted = rate(admitted_requests_total);",
+			want: map[string]*MetricSpec{
+				"batch_fill_ratio": {
+					MetricName: "batch_fill_ratio",
+					Labels:     map[string]string{"model": "llama", "pool": "a"},
+				},
+				"admitted": {
+					MetricName: "admitted_requests_total",
+					Labels:     map[string]string{},
+					Rate:       true,
+				},
+			},
+		},
+		{
+			name:  "orca metric",
+			input: "util=orca.named_metrics.util",
+			want: map[string]*MetricSpec{
+				"util": {MetricName: "orca.named_metrics.util", Labels: map[string]string{}},
+			},
+		},
+		{
+			name:    "missing spec",
+			input:   "batch_fill_ratio",
+			wantErr: true,
+		},
+		{
+			name:    "empty spec",
+			input:   "batch_fill_ratio=",
+			wantErr: true,
+		},
+		{
+			name:    "invalid name",
+			input:   "batch.fill=batch_fill_ratio",
+			wantErr: true,
+		},
+		{
+			name:    "duplicate name",
+			input:   "a=metric_a;a=metric_b",
+			wantErr: true,
+		},
+		{
+			name:    "orca rate",
+			input:   "util=rate(orca.named_metrics.util)",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCustomMetricSpecs(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCustomMetricSpecs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCustomMetricSpecs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -19,16 +19,23 @@ package metrics
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/multierr"
 	"google.golang.org/protobuf/proto"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
 
 	logutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/logging"
 )
@@ -312,7 +319,7 @@ func TestGetLatestLoraMetric(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			p := &PodMetricsClientImpl{MetricMapping: tc.mapping}
-			loraMetric, err := p.getLatestLoraMetric(tc.metricFamilies)
+			loraMetric, err := p.getLatestLoraMetric(tc.metricFamilies, tc.mapping)
 
 			if tc.expectedErr != nil {
 				if err == nil || err.Error() != tc.expectedErr.Error() {
@@ -470,7 +477,7 @@ func TestPromToPodMetrics(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			p := &PodMetricsClientImpl{MetricMapping: tc.mapping}
-			updated, err := p.promToPodMetrics(tc.metricFamilies, tc.existingMetrics)
+			updated, err := p.promToPodMetrics(tc.metricFamilies, tc.existingMetrics, tc.mapping)
 			if tc.expectedErr != nil {
 				assert.Error(t, err)
 				assert.EqualError(t, err, tc.expectedErr.Error())
@@ -482,6 +489,131 @@ func TestPromToPodMetrics(t *testing.T) {
 	}
 }
 
+func TestPromToPodMetricsRates(t *testing.T) {
+	mapping := &MetricMapping{
+		PrefixCacheHitRate: &MetricSpec{
+			MetricName: "hits_total",
+			Rate:       true,
+			Over:       &MetricSpec{MetricName: "queries_total", Rate: true},
+		},
+		PreemptionRate: &MetricSpec{MetricName: "preemptions_total", Rate: true},
+	}
+	scrape := func(hits, queries, preemptions float64) map[string]*dto.MetricFamily {
+		return map[string]*dto.MetricFamily{
+			"hits_total":        makeMetricFamily("hits_total", makeMetric(nil, hits, 0)),
+			"queries_total":     makeMetricFamily("queries_total", makeMetric(nil, queries, 0)),
+			"preemptions_total": makeMetricFamily("preemptions_total", makeMetric(nil, preemptions, 0)),
+		}
+	}
+	// samples returns the counter samples of a scrape done elapsed ago.
+	samples := func(elapsed time.Duration, hits, queries, preemptions float64) map[string]CounterSample {
+		at := time.Now().Add(-elapsed)
+		return map[string]CounterSample{
+			"hits_total":        {Value: hits, Time: at},
+			"queries_total":     {Value: queries, Time: at},
+			"preemptions_total": {Value: preemptions, Time: at},
+		}
+	}
+
+	tests := []struct {
+		name               string
+		existing           *Metrics
+		metricFamilies     map[string]*dto.MetricFamily
+		wantHitRate        float64
+		wantPreemptionRate float64
+	}{
+		{
+			name:           "first scrape",
+			existing:       &Metrics{},
+			metricFamilies: scrape(10, 100, 4),
+		},
+		{
+			name:               "increase",
+			existing:           &Metrics{CounterSamples: samples(10*time.Second, 10, 100, 4)},
+			metricFamilies:     scrape(40, 200, 24),
+			wantHitRate:        0.3,
+			wantPreemptionRate: 2,
+		},
+		{
+			name:               "counter reset",
+			existing:           &Metrics{CounterSamples: samples(10*time.Second, 10, 100, 40)},
+			metricFamilies:     scrape(40, 200, 10),
+			wantHitRate:        0.3,
+			wantPreemptionRate: 1,
+		},
+		{
+			name:           "no query keeps the previous hit rate",
+			existing:       &Metrics{PrefixCacheHitRate: 0.5, CounterSamples: samples(10*time.Second, 10, 100, 4)},
+			metricFamilies: scrape(10, 100, 4),
+			wantHitRate:    0.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &PodMetricsClientImpl{MetricMapping: mapping}
+			got, err := p.promToPodMetrics(tt.metricFamilies, tt.existing, mapping)
+			if err != nil {
+				t.Fatalf("promToPodMetrics() unexpected error: %v", err)
+			}
+			assert.InDelta(t, tt.wantHitRate, got.PrefixCacheHitRate, 0.01)
+			assert.InDelta(t, tt.wantPreemptionRate, got.PreemptionRate, 0.01)
+			for _, key := range []string{"hits_total", "queries_total", "preemptions_total"} {
+				if _, ok := got.CounterSamples[key]; !ok {
+					t.Errorf("Missing counter sample %q", key)
+				}
+			}
+		})
+	}
+}
+
+func TestPromToPodMetricsCustom(t *testing.T) {
+	mapping := &MetricMapping{
+		Custom: map[string]*MetricSpec{
+			"batch_fill_ratio": {MetricName: "batch_fill_ratio", Labels: map[string]string{"model": "llama"}},
+			"missing":          {MetricName: "missing_metric"},
+		},
+	}
+	metricFamilies := map[string]*dto.MetricFamily{
+		"batch_fill_ratio": makeMetricFamily("batch_fill_ratio",
+			makeMetric(map[string]string{"model": "llama"}, 0.75, 1000),
+			makeMetric(map[string]string{"model": "other"}, 0.25, 1000),
+		),
+	}
+	existing := &Metrics{Custom: map[string]float64{"missing": 3}}
+	p := &PodMetricsClientImpl{MetricMapping: mapping}
+	got, err := p.promToPodMetrics(metricFamilies, existing, mapping)
+	var missing *MissingMetricError
+	if !errors.As(err, &missing) || missing.Metric != "missing_metric" {
+		t.Errorf("promToPodMetrics() error = %v, want a MissingMetricError for missing_metric", err)
+	}
+	want := map[string]float64{"batch_fill_ratio": 0.75, "missing": 3}
+	if !reflect.DeepEqual(got.Custom, want) {
+		t.Errorf("promToPodMetrics() custom = %v, want %v", got.Custom, want)
+	}
+	if existing.Custom["batch_fill_ratio"] != 0 {
+		t.Errorf("promToPodMetrics() modified the existing metrics")
+	}
+}
+
+func TestMetricsCloneMaps(t *testing.T) {
+	m := &Metrics{
+		Custom:         map[string]float64{"batch_fill_ratio": 1},
+		CounterSamples: map[string]CounterSample{"requests_total": {Value: 1, Time: time.Now()}},
+	}
+	clone := m.Clone()
+	clone.Custom["batch_fill_ratio"] = 2
+	clone.CounterSamples["requests_total"] = CounterSample{Value: 2}
+	if got := m.Custom["batch_fill_ratio"]; got != 1 {
+		t.Errorf("Clone() shares the custom metrics, got %v want 1", got)
+	}
+	if got := m.CounterSamples["requests_total"].Value; got != 1 {
+		t.Errorf("Clone() shares the counter samples, got %v want 1", got)
+	}
+	if clone := (&Metrics{}).Clone(); clone.Custom != nil || clone.CounterSamples != nil {
+		t.Errorf("Clone() = %+v, want nil maps", clone)
+	}
+}
+
 // TestFetchMetrics is a basic integration test. It assumes
 // there's no server running on the specified port.
 func TestFetchMetrics(t *testing.T) {
@@ -496,7 +628,8 @@ func TestFetchMetrics(t *testing.T) {
 	existing := &Metrics{}
 	p := &PodMetricsClientImpl{} // No MetricMapping needed for this basic test
 
-	_, err := p.FetchMetrics(ctx, pod, existing, 9999) // Use a port that's unlikely to be in use.
+	pool := &v1alpha2.InferencePool{Spec: v1alpha2.InferencePoolSpec{TargetPortNumber: 9999}} // Use a port that's unlikely to be in use.
+	_, err := p.FetchMetrics(ctx, pod, existing, pool)
 	if err == nil {
 		t.Errorf("FetchMetrics() expected error, got nil")
 	}
@@ -506,3 +639,172 @@ func TestFetchMetrics(t *testing.T) {
 		t.Errorf("FetchMetrics() error = %v, want error containing %q", err, expectedSubstr)
 	}
 }
+
+func TestMetricsURL(t *testing.T) {
+	pod := &Pod{Address: "10.0.0.1"}
+	tests := []struct {
+		name          string
+		defaultScheme v1alpha2.MetricsScheme
+		metrics       *v1alpha2.ModelServerMetrics
+		want          string
+	}{
+		{
+			name: "defaults",
+			want: "http://10.0.0.1:8000/metrics",
+		},
+		{
+			name:          "default scheme of the client",
+			defaultScheme: v1alpha2.MetricsSchemeHTTPS,
+			want:          "https://10.0.0.1:8000/metrics",
+		},
+		{
+			name:          "scheme of the pool overrides the client",
+			defaultScheme: v1alpha2.MetricsSchemeHTTPS,
+			metrics:       &v1alpha2.ModelServerMetrics{Scheme: ptr.To(v1alpha2.MetricsSchemeHTTP)},
+			want:          "http://10.0.0.1:8000/metrics",
+		},
+		{
+			name: "all fields set",
+			metrics: &v1alpha2.ModelServerMetrics{
+				PortNumber: ptr.To(v1alpha2.PortNumber(9090)),
+				Path:       ptr.To("/v2/metrics"),
+				Scheme:     ptr.To(v1alpha2.MetricsSchemeHTTPS),
+			},
+			want: "https://10.0.0.1:9090/v2/metrics",
+		},
+		{
+			name:    "only mapping set",
+			metrics: &v1alpha2.ModelServerMetrics{Mapping: &v1alpha2.ModelServerMetricMapping{}},
+			want:    "http://10.0.0.1:8000/metrics",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := &v1alpha2.InferencePool{Spec: v1alpha2.InferencePoolSpec{TargetPortNumber: 8000, Metrics: tt.metrics}}
+			p := &PodMetricsClientImpl{Scheme: tt.defaultScheme}
+			scheme, _, _, err := p.scrapeConfig(pool)
+			if err != nil {
+				t.Fatalf("scrapeConfig() unexpected error: %v", err)
+			}
+			if got := metricsURL(pod, pool, scheme); got != tt.want {
+				t.Errorf("metricsURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetricMappingFromPool(t *testing.T) {
+	defaults, err := NewMetricMapping("vllm:num_requests_waiting", "vllm:gpu_cache_usage_perc", "vllm:lora_requests_info")
+	if err != nil {
+		t.Fatalf("NewMetricMapping() unexpected error: %v", err)
+	}
+	defaults.Custom = map[string]*MetricSpec{"batch_fill_ratio": {MetricName: "vllm:batch_fill_ratio"}}
+	tests := []struct {
+		name     string
+		protocol *v1alpha2.ModelServerProtocol
+		mapping  *v1alpha2.ModelServerMetricMapping
+		want     *MetricMapping
+		wantErr  bool
+	}{
+		{
+			name: "no mapping uses defaults",
+			want: defaults,
+		},
+		{
+			name: "partial mapping falls back to defaults",
+			mapping: &v1alpha2.ModelServerMetricMapping{
+				TotalQueuedRequests: "tgi_queue_size",
+			},
+			want: &MetricMapping{
+				TotalQueuedRequests: &MetricSpec{MetricName: "tgi_queue_size", Labels: map[string]string{}},
+				KVCacheUtilization:  defaults.KVCacheUtilization,
+				LoraRequestInfo:     defaults.LoraRequestInfo,
+				Custom:              defaults.Custom,
+			},
+		},
+		{
+			name:     "protocol replaces defaults",
+			protocol: ptr.To(v1alpha2.ModelServerProtocolSGLang),
+			mapping: &v1alpha2.ModelServerMetricMapping{
+				TotalQueuedRequests: "sglang:num_queue_reqs{model_name=llama}",
+			},
+			want: &MetricMapping{
+				TotalQueuedRequests:     &MetricSpec{MetricName: "sglang:num_queue_reqs", Labels: map[string]string{"model_name": "llama"}},
+				KVCacheUtilization:      &MetricSpec{MetricName: "sglang:token_usage"},
+				TotalRunningRequests:    &MetricSpec{MetricName: "sglang:num_running_reqs"},
+				KVCacheMaxTokenCapacity: &MetricSpec{MetricName: "sglang:max_total_num_tokens"},
+				PrefixCacheHitRate:      &MetricSpec{MetricName: "sglang:cache_hit_rate"},
+				TokenThroughput:         &MetricSpec{MetricName: "sglang:gen_throughput"},
+				Custom:                  defaults.Custom,
+			},
+		},
+		{
+			name: "invalid mapping",
+			mapping: &v1alpha2.ModelServerMetricMapping{
+				KVCacheUtilization: "kv_usage{model}",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &PodMetricsClientImpl{MetricMapping: defaults}
+			pool := &v1alpha2.InferencePool{}
+			if tt.protocol != nil || tt.mapping != nil {
+				pool.Spec.Metrics = &v1alpha2.ModelServerMetrics{Protocol: tt.protocol, Mapping: tt.mapping}
+			}
+			// Resolve twice to exercise the cache.
+			for range 2 {
+				got, err := p.metricMapping(pool)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("metricMapping() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("metricMapping() = %+v, want %+v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchMetricsFromPoolEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/custom/metrics" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, "# TYPE tgi_queue_size gauge")
+		fmt.Fprintln(w, "tgi_queue_size 7")
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+	port, err := strconv.Atoi(serverURL.Port())
+	if err != nil {
+		t.Fatalf("Failed to parse server port: %v", err)
+	}
+
+	ctx := logutil.NewTestLoggerIntoContext(context.Background())
+	pod := &Pod{Address: serverURL.Hostname()}
+	pool := &v1alpha2.InferencePool{
+		Spec: v1alpha2.InferencePoolSpec{
+			TargetPortNumber: 1, // The model server port must not be used for scraping.
+			Metrics: &v1alpha2.ModelServerMetrics{
+				PortNumber: ptr.To(v1alpha2.PortNumber(port)),
+				Path:       ptr.To("/custom/metrics"),
+				Mapping:    &v1alpha2.ModelServerMetricMapping{TotalQueuedRequests: "tgi_queue_size"},
+			},
+		},
+	}
+	p := &PodMetricsClientImpl{MetricMapping: &MetricMapping{}}
+	got, err := p.FetchMetrics(ctx, pod, &Metrics{}, pool)
+	if err != nil {
+		t.Fatalf("FetchMetrics() unexpected error: %v", err)
+	}
+	if got.WaitingQueueSize != 7 {
+		t.Errorf("FetchMetrics() WaitingQueueSize = %d, want 7", got.WaitingQueueSize)
+	}
+}
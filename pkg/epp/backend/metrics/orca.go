@@ -0,0 +1,222 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	xdsorca "github.com/cncf/xds/go/xds/data/orca/v3"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+)
+
+const (
+	// ORCALoadReportHeader is the response header carrying an ORCA load report, in one of the TEXT,
+	// JSON or BIN formats. See
+	// https://github.com/envoyproxy/envoy/blob/main/source/common/orca/orca_parser.h
+	ORCALoadReportHeader = "endpoint-load-metrics"
+	// ORCALoadReportBinHeader is the response header carrying a base64 encoded binary ORCA load report.
+	ORCALoadReportBinHeader = "endpoint-load-metrics-bin"
+
+	// ORCAMetricPrefix prefixes the metric specs that refer to a metric of the ORCA load reports
+	// attached by the model servers to their responses, rather than to a scraped Prometheus metric.
+	// For example "orca.named_metrics.kv_cache_utilization" or "orca.application_utilization".
+	ORCAMetricPrefix = "orca."
+
+	orcaFormatText = "TEXT"
+	orcaFormatJSON = "JSON"
+	orcaFormatBin  = "BIN"
+)
+
+// ParseORCALoadReport parses the ORCA load report from the value of the given response header.
+func ParseORCALoadReport(header, value string) (*xdsorca.OrcaLoadReport, error) {
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(header, ORCALoadReportBinHeader) {
+		return parseORCABinary(value)
+	}
+
+	format, payload, _ := strings.Cut(value, " ")
+	switch strings.ToUpper(format) {
+	case orcaFormatText:
+		return parseORCAText(payload)
+	case orcaFormatJSON:
+		report := &xdsorca.OrcaLoadReport{}
+		if err := protojson.Unmarshal([]byte(payload), report); err != nil {
+			return nil, fmt.Errorf("invalid JSON ORCA load report: %w", err)
+		}
+		return report, nil
+	case orcaFormatBin:
+		return parseORCABinary(strings.TrimSpace(payload))
+	default:
+		return nil, fmt.Errorf("unsupported ORCA load report format %q", format)
+	}
+}
+
+func parseORCABinary(value string) (*xdsorca.OrcaLoadReport, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 ORCA load report: %w", err)
+	}
+	report := &xdsorca.OrcaLoadReport{}
+	if err := proto.Unmarshal(data, report); err != nil {
+		return nil, fmt.Errorf("invalid binary ORCA load report: %w", err)
+	}
+	return report, nil
+}
+
+// parseORCAText parses the TEXT format, a comma separated list of key=value pairs such as
+// "cpu_utilization=0.3, named_metrics.kv_cache_utilization=0.4".
+func parseORCAText(payload string) (*xdsorca.OrcaLoadReport, error) {
+	report := &xdsorca.OrcaLoadReport{}
+	for _, pair := range strings.Split(payload, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, valueStr, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid ORCA load report metric %q", pair)
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(valueStr), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of ORCA load report metric %q: %w", pair, err)
+		}
+		key = strings.TrimSpace(key)
+		if !setORCAMetric(report, key, value) {
+			return nil, fmt.Errorf("unknown ORCA load report metric %q", key)
+		}
+	}
+	return report, nil
+}
+
+func setORCAMetric(report *xdsorca.OrcaLoadReport, key string, value float64) bool {
+	if prefix, name, ok := strings.Cut(key, "."); ok && name != "" {
+		var m *map[string]float64
+		switch prefix {
+		case "named_metrics":
+			m = &report.NamedMetrics
+		case "utilization":
+			m = &report.Utilization
+		case "request_cost":
+			m = &report.RequestCost
+		default:
+			return false
+		}
+		if *m == nil {
+			*m = map[string]float64{}
+		}
+		(*m)[name] = value
+		return true
+	}
+	switch key {
+	case "cpu_utilization":
+		report.CpuUtilization = value
+	case "mem_utilization":
+		report.MemUtilization = value
+	case "application_utilization":
+		report.ApplicationUtilization = value
+	case "rps_fractional":
+		report.RpsFractional = value
+	case "eps":
+		report.Eps = value
+	default:
+		return false
+	}
+	return true
+}
+
+// orcaMetricValue returns the value of the metric of the load report named by the spec, without the
+// ORCAMetricPrefix.
+func orcaMetricValue(report *xdsorca.OrcaLoadReport, spec MetricSpec) (float64, bool) {
+	key := strings.TrimPrefix(spec.MetricName, ORCAMetricPrefix)
+	var value float64
+	if prefix, name, ok := strings.Cut(key, "."); ok {
+		var m map[string]float64
+		switch prefix {
+		case "named_metrics":
+			m = report.GetNamedMetrics()
+		case "utilization":
+			m = report.GetUtilization()
+		case "request_cost":
+			m = report.GetRequestCost()
+		}
+		if value, ok = m[name]; !ok {
+			return 0, false
+		}
+	} else {
+		switch key {
+		case "cpu_utilization":
+			value = report.GetCpuUtilization()
+		case "mem_utilization":
+			value = report.GetMemUtilization()
+		case "application_utilization":
+			value = report.GetApplicationUtilization()
+		case "rps_fractional":
+			value = report.GetRpsFractional()
+		case "eps":
+			value = report.GetEps()
+		default:
+			return 0, false
+		}
+	}
+	if spec.Scale != 0 {
+		value *= spec.Scale
+	}
+	return value, true
+}
+
+// MetricsFromLoadReport clones the existing metrics object and returns one updated with the signals
+// that the metric mapping of the pool maps to ORCA metrics. applied is true if the load report
+// provided at least one signal of the mapping, and complete if it provided every signal of the
+// mapping, in which case there is nothing left to scrape.
+func (p *PodMetricsClientImpl) MetricsFromLoadReport(
+	report *xdsorca.OrcaLoadReport,
+	existing *Metrics,
+	pool *v1alpha2.InferencePool,
+) (updated *Metrics, applied, complete bool, err error) {
+	mapping, err := p.metricMapping(pool)
+	if err != nil {
+		return nil, false, false, err
+	}
+	updated = existing.Clone()
+	if mapping == nil {
+		return updated, false, false, nil
+	}
+
+	complete = mapping.LoraRequestInfo == nil
+	for _, signal := range mapping.signals(updated) {
+		if signal.spec == nil {
+			continue
+		}
+		if !signal.spec.isORCA() {
+			complete = false
+			continue
+		}
+		value, ok := orcaMetricValue(report, *signal.spec)
+		if !ok {
+			complete = false
+			continue
+		}
+		signal.set(value)
+		applied = true
+	}
+	return updated, applied, complete, nil
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	xdsorca "github.com/cncf/xds/go/xds/data/orca/v3"
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+)
+
+func TestParseORCALoadReport(t *testing.T) {
+	want := &xdsorca.OrcaLoadReport{
+		CpuUtilization: 0.3,
+		NamedMetrics:   map[string]float64{"kv_cache_utilization": 0.4, "num_requests_waiting": 7},
+	}
+	bin, err := proto.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(bin)
+
+	tests := []struct {
+		name    string
+		header  string
+		value   string
+		want    *xdsorca.OrcaLoadReport
+		wantErr bool
+	}{
+		{
+			name:   "text",
+			header: ORCALoadReportHeader,
+			value:  "TEXT cpu_utilization=0.3, named_metrics.kv_cache_utilization=0.4,named_metrics.num_requests_waiting=7",
+			want:   want,
+		},
+		{
+			name:   "json",
+			header: ORCALoadReportHeader,
+			value:  `JSON {"cpu_utilization": 0.3, "named_metrics": {"kv_cache_utilization": 0.4, "num_requests_waiting": 7}}`,
+			want:   want,
+		},
+		{
+			name:   "binary",
+			header: ORCALoadReportHeader,
+			value:  "BIN " + encoded,
+			want:   want,
+		},
+		{
+			name:   "binary header",
+			header: ORCALoadReportBinHeader,
+			value:  encoded,
+			want:   want,
+		},
+		{
+			name:    "unknown format",
+			header:  ORCALoadReportHeader,
+			value:   "XML <report/>",
+			wantErr: true,
+		},
+		{
+			name:    "unknown text metric",
+			header:  ORCALoadReportHeader,
+			value:   "TEXT gpu_utilization=0.3",
+			wantErr: true,
+		},
+		{
+			name:    "invalid text value",
+			header:  ORCALoadReportHeader,
+			value:   "TEXT cpu_utilization=high",
+			wantErr: true,
+		},
+		{
+			name:    "invalid base64",
+			header:  ORCALoadReportBinHeader,
+			value:   "not base64!",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseORCALoadReport(test.header, test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParseORCALoadReport() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if diff := cmp.Diff(test.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Unexpected report (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestMetricsFromLoadReport(t *testing.T) {
+	report := &xdsorca.OrcaLoadReport{
+		ApplicationUtilization: 0.6,
+		NamedMetrics:           map[string]float64{"num_requests_waiting": 7},
+	}
+	existing := &Metrics{WaitingQueueSize: 1, KVCacheUsagePercent: 0.1, ActiveModels: map[string]int{}, WaitingModels: map[string]int{}}
+	pool := &v1alpha2.InferencePool{}
+
+	tests := []struct {
+		name         string
+		mapping      *MetricMapping
+		want         *Metrics
+		wantApplied  bool
+		wantComplete bool
+	}{
+		{
+			name: "all signals reported",
+			mapping: &MetricMapping{
+				TotalQueuedRequests: &MetricSpec{MetricName: "orca.named_metrics.num_requests_waiting"},
+				KVCacheUtilization:  &MetricSpec{MetricName: "orca.application_utilization"},
+			},
+			want:         &Metrics{WaitingQueueSize: 7, KVCacheUsagePercent: 0.6, ActiveModels: map[string]int{}, WaitingModels: map[string]int{}},
+			wantApplied:  true,
+			wantComplete: true,
+		},
+		{
+			name: "scaled signal",
+			mapping: &MetricMapping{
+				KVCacheUtilization: &MetricSpec{MetricName: "orca.application_utilization", Scale: 0.5},
+			},
+			want:         &Metrics{WaitingQueueSize: 1, KVCacheUsagePercent: 0.3, ActiveModels: map[string]int{}, WaitingModels: map[string]int{}},
+			wantApplied:  true,
+			wantComplete: true,
+		},
+		{
+			name: "prometheus signal is left to the scrapes",
+			mapping: &MetricMapping{
+				TotalQueuedRequests: &MetricSpec{MetricName: "orca.named_metrics.num_requests_waiting"},
+				KVCacheUtilization:  &MetricSpec{MetricName: "vllm:gpu_cache_usage_perc"},
+			},
+			want:         &Metrics{WaitingQueueSize: 7, KVCacheUsagePercent: 0.1, ActiveModels: map[string]int{}, WaitingModels: map[string]int{}},
+			wantApplied:  true,
+			wantComplete: false,
+		},
+		{
+			name: "named metric not reported",
+			mapping: &MetricMapping{
+				TotalQueuedRequests: &MetricSpec{MetricName: "orca.named_metrics.num_requests_running"},
+			},
+			want:         existing,
+			wantComplete: false,
+		},
+		{
+			name: "custom signal",
+			mapping: &MetricMapping{
+				Custom: map[string]*MetricSpec{"waiting": {MetricName: "orca.named_metrics.num_requests_waiting"}},
+			},
+			want: &Metrics{
+				WaitingQueueSize:    1,
+				KVCacheUsagePercent: 0.1,
+				ActiveModels:        map[string]int{},
+				WaitingModels:       map[string]int{},
+				Custom:              map[string]float64{"waiting": 7},
+			},
+			wantApplied:  true,
+			wantComplete: true,
+		},
+		{
+			name: "lora info is always scraped",
+			mapping: &MetricMapping{
+				TotalQueuedRequests: &MetricSpec{MetricName: "orca.named_metrics.num_requests_waiting"},
+				LoraRequestInfo:     &MetricSpec{MetricName: "vllm:lora_requests_info"},
+			},
+			want:         &Metrics{WaitingQueueSize: 7, KVCacheUsagePercent: 0.1, ActiveModels: map[string]int{}, WaitingModels: map[string]int{}},
+			wantApplied:  true,
+			wantComplete: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := &PodMetricsClientImpl{MetricMapping: test.mapping}
+			got, applied, complete, err := p.MetricsFromLoadReport(report, existing, pool)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if applied != test.wantApplied {
+				t.Errorf("applied = %v, want %v", applied, test.wantApplied)
+			}
+			if complete != test.wantComplete {
+				t.Errorf("complete = %v, want %v", complete, test.wantComplete)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Unexpected metrics (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestNewMetricMappingORCA(t *testing.T) {
+	tests := []struct {
+		name    string
+		queued  string
+		kvUsage string
+		lora    string
+		wantErr bool
+	}{
+		{name: "orca signals", queued: "orca.named_metrics.num_requests_waiting", kvUsage: "orca.application_utilization"},
+		{name: "orca with labels", queued: "orca.named_metrics.num_requests_waiting{model=foo}", wantErr: true},
+		{name: "orca lora info", lora: "orca.named_metrics.lora", wantErr: true},
+		{name: "orca rate", queued: "rate(orca.named_metrics.num_requests_waiting)", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewMetricMapping(test.queued, test.kvUsage, test.lora)
+			if (err != nil) != test.wantErr {
+				t.Errorf("NewMetricMapping() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadReportSkipsScrape(t *testing.T) {
+	ctx := context.Background()
+	pmc := &countingLoadReportClient{
+		PodMetricsClientImpl: PodMetricsClientImpl{MetricMapping: &MetricMapping{
+			TotalQueuedRequests: &MetricSpec{MetricName: "orca.named_metrics.num_requests_waiting"},
+		}},
+	}
+	pm := &podMetrics{
+		pmc:       pmc,
+		ds:        &fakeDataStore{},
+		parentCtx: ctx,
+		done:      make(chan struct{}),
+		logger:    logr.Discard(),
+	}
+	pm.pod.Store(toInternalPod(pod1))
+	pm.metrics.Store(newMetrics())
+
+	pm.scrape(ctx)
+	assert.Equal(t, 1, pmc.fetches)
+
+	pm.UpdateLoadReport(&xdsorca.OrcaLoadReport{NamedMetrics: map[string]float64{"num_requests_waiting": 4}})
+	assert.Equal(t, 4, pm.GetMetrics().WaitingQueueSize)
+	// The load report provided all the metrics since the last scrape.
+	pm.scrape(ctx)
+	assert.Equal(t, 1, pmc.fetches)
+	// Without a new load report, the pod is scraped again.
+	pm.scrape(ctx)
+	assert.Equal(t, 2, pmc.fetches)
+}
+
+func TestLoadReportWithoutSignalKeepsMetrics(t *testing.T) {
+	pm := &podMetrics{
+		pmc: &PodMetricsClientImpl{MetricMapping: &MetricMapping{
+			TotalQueuedRequests: &MetricSpec{MetricName: "orca.named_metrics.num_requests_waiting"},
+		}},
+		ds:     &fakeDataStore{},
+		done:   make(chan struct{}),
+		logger: logr.Discard(),
+	}
+	pm.pod.Store(toInternalPod(pod1))
+	scraped := newMetrics()
+	scraped.UpdateTime = time.Now().Add(-time.Minute)
+	pm.metrics.Store(scraped)
+
+	// The metrics are left untouched, so that they are still reported as stale.
+	pm.UpdateLoadReport(&xdsorca.OrcaLoadReport{NamedMetrics: map[string]float64{"num_requests_running": 4}})
+	if got := pm.GetMetrics(); got != scraped {
+		t.Errorf("UpdateLoadReport() stored %+v, want the scraped metrics", got)
+	}
+	if pm.lastCompleteLoadReport.Load() != 0 {
+		t.Error("UpdateLoadReport() recorded a complete load report")
+	}
+}
+
+// countingLoadReportClient counts the scrapes, and maps the load reports as PodMetricsClientImpl.
+type countingLoadReportClient struct {
+	PodMetricsClientImpl
+	fetches int
+}
+
+func (c *countingLoadReportClient) FetchMetrics(_ context.Context, _ *Pod, existing *Metrics, _ *v1alpha2.InferencePool) (*Metrics, error) {
+	c.fetches++
+	return existing.Clone(), nil
+}
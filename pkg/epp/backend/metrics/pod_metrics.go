@@ -19,14 +19,16 @@ package metrics
 import (
 	"context"
 	"fmt"
-	"sync"
+	"slices"
 	"sync/atomic"
 	"time"
 
+	xdsorca "github.com/cncf/xds/go/xds/data/orca/v3"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
-
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/metrics"
 	logutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/logging"
 )
 
@@ -35,21 +37,38 @@ const (
 )
 
 type podMetrics struct {
-	pod      atomic.Pointer[Pod]
-	metrics  atomic.Pointer[Metrics]
-	pmc      PodMetricsClient
-	ds       Datastore
-	interval time.Duration
+	pod     atomic.Pointer[Pod]
+	metrics atomic.Pointer[Metrics]
+	pmc     PodMetricsClient
+	ds      Datastore
+
+	// health is nil until the pod is first scraped. It is only written by the scrape in flight, the
+	// scrape scheduler never runs two scrapes of the same pod concurrently.
+	health atomic.Pointer[ScrapeHealth]
+	// poolName is the name of the pool of the last scrape, to clean up the per-pod Prometheus metrics.
+	poolName atomic.Pointer[string]
+	// lastScrape and lastCompleteLoadReport are in UnixNano. A scrape is skipped if a load report
+	// provided all the metrics since the previous scrape, polling is only a fallback for the pods
+	// that don't report their load, or don't receive traffic.
+	lastScrape             atomic.Int64
+	lastCompleteLoadReport atomic.Int64
+
+	smoothing *podSmoothing
 
 	parentCtx context.Context
-	once      sync.Once // ensure the StartRefreshLoop is only called once.
 	done      chan struct{}
 
 	logger logr.Logger
 }
 
 type PodMetricsClient interface {
-	FetchMetrics(ctx context.Context, pod *Pod, existing *Metrics, port int32) (*Metrics, error)
+	FetchMetrics(ctx context.Context, pod *Pod, existing *Metrics, pool *v1alpha2.InferencePool) (*Metrics, error)
+}
+
+// LoadReportClient is implemented by the PodMetricsClients that can also update the metrics from
+// the ORCA load reports pushed by the model servers.
+type LoadReportClient interface {
+	MetricsFromLoadReport(report *xdsorca.OrcaLoadReport, existing *Metrics, pool *v1alpha2.InferencePool) (updated *Metrics, applied, complete bool, err error)
 }
 
 func (pm *podMetrics) String() string {
@@ -64,6 +83,10 @@ func (pm *podMetrics) GetMetrics() *Metrics {
 	return pm.metrics.Load()
 }
 
+func (pm *podMetrics) GetScrapeHealth() *ScrapeHealth {
+	return pm.health.Load()
+}
+
 func (pm *podMetrics) UpdatePod(in *corev1.Pod) {
 	pm.pod.Store(toInternalPod(in))
 }
@@ -78,60 +101,134 @@ func toInternalPod(in *corev1.Pod) *Pod {
 	}
 }
 
-// start starts a goroutine exactly once to periodically update metrics. The goroutine will be
-// stopped either when stop() is called, or the parentCtx is cancelled.
-func (pm *podMetrics) startRefreshLoop() {
-	pm.once.Do(func() {
-		go func() {
-			pm.logger.V(logutil.DEFAULT).Info("Starting refresher", "pod", pm.GetPod())
-			ticker := time.NewTicker(pm.interval)
-			defer ticker.Stop()
-			for {
-				select {
-				case <-pm.done:
-					return
-				case <-pm.parentCtx.Done():
-					return
-				case <-ticker.C: // refresh metrics periodically
-					if err := pm.refreshMetrics(); err != nil {
-						pm.logger.V(logutil.TRACE).Error(err, "Failed to refresh metrics", "pod", pm.GetPod())
-					}
-				}
-			}
-		}()
-	})
-}
-
-func (pm *podMetrics) refreshMetrics() error {
+// stopped returns true once the pod is no longer scraped, either because StopRefreshLoop was
+// called or the parentCtx is cancelled.
+func (pm *podMetrics) stopped() bool {
+	select {
+	case <-pm.done:
+		return true
+	case <-pm.parentCtx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// scrape refreshes the metrics of the pod, records the outcome in its ScrapeHealth and returns the
+// number of consecutive failures.
+func (pm *podMetrics) scrape(ctx context.Context) int {
 	pool, err := pm.ds.PoolGet()
 	if err != nil {
-		// No inference pool or not initialize.
-		return err
+		// No inference pool or not initialize, there is nothing to scrape yet.
+		return 0
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), fetchMetricsTimeout)
-	defer cancel()
-	updated, err := pm.pmc.FetchMetrics(ctx, pm.GetPod(), pm.GetMetrics(), pool.Spec.TargetPortNumber)
-	if err != nil {
-		pm.logger.V(logutil.TRACE).Info("Failed to refreshed metrics:", "err", err)
+	pm.poolName.Store(&pool.Name)
+	start := time.Now()
+	if prev := pm.lastScrape.Swap(start.UnixNano()); pm.lastCompleteLoadReport.Load() > prev {
+		pm.logger.V(logutil.TRACE).Info("Skipping scrape, metrics were reported in a response")
+		return 0
 	}
+	refreshed, err := pm.refreshMetrics(ctx, pool)
+	metrics.RecordPodScrapeLatency(pool.Name, refreshed, time.Since(start))
+	return pm.recordHealth(pool.Name, refreshed, err)
+}
+
+// refreshMetrics fetches the metrics of the pod, and returns whether they were updated.
+func (pm *podMetrics) refreshMetrics(ctx context.Context, pool *v1alpha2.InferencePool) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchMetricsTimeout)
+	defer cancel()
+	updated, err := pm.pmc.FetchMetrics(ctx, pm.GetPod(), pm.GetMetrics(), pool)
 	// Optimistically update metrics even if there was an error.
 	// The FetchMetrics can return an error for the following reasons:
 	// 1. As refresher is running in the background, it's possible that the pod is deleted but
-	// the refresh goroutine doesn't read the done channel yet. In this case, the updated
-	// metrics object will be nil. And the refresher will soon be stopped.
+	// the scrape was already dispatched. In this case, the updated metrics object will be nil.
+	// And the pod will not be scheduled again.
 	// 2. The FetchMetrics call can partially fail. For example, due to one metric missing. In
 	// this case, the updated metrics object will have partial updates. A partial update is
-	// considered better than no updates.
-	if updated != nil {
-		updated.UpdateTime = time.Now()
-		pm.logger.V(logutil.TRACE).Info("Refreshed metrics", "updated", updated)
-		pm.metrics.Store(updated)
+	// considered better than no updates, the missing metrics are recorded in the ScrapeHealth.
+	if updated == nil {
+		return false, err
+	}
+	pm.storeMetrics(time.Now(), updated)
+	pm.logger.V(logutil.TRACE).Info("Refreshed metrics", "updated", updated)
+	return true, err
+}
+
+// storeMetrics smooths and stores the metrics updated at the given time.
+func (pm *podMetrics) storeMetrics(now time.Time, updated *Metrics) {
+	updated.UpdateTime = now
+	pm.smoothing.apply(now, updated)
+	pm.metrics.Store(updated)
+}
+
+// UpdateLoadReport updates the metrics of the pod from an ORCA load report attached to one of its
+// responses.
+func (pm *podMetrics) UpdateLoadReport(report *xdsorca.OrcaLoadReport) {
+	lrc, ok := pm.pmc.(LoadReportClient)
+	if !ok {
+		return
+	}
+	pool, err := pm.ds.PoolGet()
+	if err != nil {
+		return
+	}
+	updated, applied, complete, err := lrc.MetricsFromLoadReport(report, pm.GetMetrics(), pool)
+	if err != nil {
+		pm.logger.V(logutil.DEBUG).Error(err, "Failed to update metrics from load report")
+		return
+	}
+	if !applied {
+		// Storing the metrics would refresh their UpdateTime, hiding that they are stale.
+		return
+	}
+	now := time.Now()
+	pm.storeMetrics(now, updated)
+	if complete {
+		pm.lastCompleteLoadReport.Store(now.UnixNano())
+	}
+	pm.logger.V(logutil.TRACE).Info("Updated metrics from load report", "updated", updated)
+}
+
+// recordHealth updates the ScrapeHealth of the pod with the outcome of a scrape. Transitions are
+// logged at the default level, so that a pod failing to be scraped, or missing metrics, is noticed.
+func (pm *podMetrics) recordHealth(poolName string, refreshed bool, err error) int {
+	prev := pm.health.Load()
+	health := prev.Clone()
+	if health == nil {
+		health = &ScrapeHealth{}
+	}
+	health.LastError = ""
+	if err != nil {
+		health.LastError = err.Error()
 	}
 
-	return nil
+	if !refreshed {
+		health.ConsecutiveFailures++
+		metrics.RecordPodScrapeError(poolName, pm.GetPod().NamespacedName.Name)
+		if health.ConsecutiveFailures == 1 {
+			pm.logger.V(logutil.DEFAULT).Error(err, "Failed to scrape metrics")
+		} else {
+			pm.logger.V(logutil.TRACE).Error(err, "Failed to scrape metrics", "consecutiveFailures", health.ConsecutiveFailures)
+		}
+	} else {
+		if !prev.Healthy() {
+			pm.logger.V(logutil.DEFAULT).Info("Recovered scraping metrics", "failedScrapes", prev.ConsecutiveFailures)
+		}
+		health.ConsecutiveFailures = 0
+		health.LastSuccessTime = time.Now()
+		health.MissingMetrics = missingMetrics(err)
+		if len(health.MissingMetrics) > 0 && (prev == nil || !slices.Equal(prev.MissingMetrics, health.MissingMetrics)) {
+			pm.logger.V(logutil.DEFAULT).Info("Mapped metrics are missing from the scraped metrics", "missing", health.MissingMetrics, "err", err)
+		}
+	}
+	pm.health.Store(health)
+	return health.ConsecutiveFailures
 }
 
 func (pm *podMetrics) StopRefreshLoop() {
 	pm.logger.V(logutil.DEFAULT).Info("Stopping refresher", "pod", pm.GetPod())
 	close(pm.done)
+	if poolName := pm.poolName.Load(); poolName != nil {
+		metrics.DeletePodScrapeErrors(*poolName, pm.GetPod().NamespacedName.Name)
+	}
 }
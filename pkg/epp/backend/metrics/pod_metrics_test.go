@@ -17,12 +17,15 @@ package metrics
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/multierr"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -71,7 +74,7 @@ func TestMetricsRefresh(t *testing.T) {
 	// Verify that the metrics are updated.
 	pmc.SetRes(map[types.NamespacedName]*Metrics{namespacedName: initial})
 	condition := func(collect *assert.CollectT) {
-		assert.True(collect, cmp.Equal(pm.GetMetrics(), initial, cmpopts.IgnoreFields(Metrics{}, "UpdateTime")))
+		assert.True(collect, cmp.Equal(pm.GetMetrics(), initial, cmpopts.IgnoreFields(Metrics{}, "UpdateTime", "SmoothedWaitingQueueSize", "SmoothedKVCacheUsagePercent")))
 	}
 	assert.EventuallyWithT(t, condition, time.Second, time.Millisecond)
 
@@ -83,6 +86,68 @@ func TestMetricsRefresh(t *testing.T) {
 	assert.EventuallyWithT(t, condition, time.Second, time.Millisecond)
 }
 
+func TestScrapeHealth(t *testing.T) {
+	ctx := context.Background()
+	pmc := &scriptedPodMetricsClient{}
+	pm := &podMetrics{
+		pmc:       pmc,
+		ds:        &fakeDataStore{},
+		parentCtx: ctx,
+		done:      make(chan struct{}),
+		logger:    logr.Discard(),
+	}
+	pm.pod.Store(toInternalPod(pod1))
+	pm.metrics.Store(newMetrics())
+
+	if got := pm.GetScrapeHealth(); got != nil {
+		t.Fatalf("Expected no health before the first scrape, got %v", got)
+	}
+
+	// Two failed scrapes.
+	pmc.res, pmc.err = nil, errors.New("connection refused")
+	assert.Equal(t, 1, pm.scrape(ctx))
+	assert.Equal(t, 2, pm.scrape(ctx))
+	health := pm.GetScrapeHealth()
+	assert.Equal(t, 2, health.ConsecutiveFailures)
+	assert.Equal(t, "connection refused", health.LastError)
+	assert.True(t, health.LastSuccessTime.IsZero())
+	assert.False(t, health.Healthy())
+
+	// A partial scrape resets the failures, and records the missing metrics.
+	pmc.res = updated
+	pmc.err = multierr.Append(
+		&MissingMetricError{Metric: "vllm:gpu_cache_usage_perc", Err: errors.New("metric family not found")},
+		errors.New("invalid max_lora"))
+	assert.Equal(t, 0, pm.scrape(ctx))
+	health = pm.GetScrapeHealth()
+	assert.Equal(t, 0, health.ConsecutiveFailures)
+	assert.False(t, health.LastSuccessTime.IsZero())
+	assert.Equal(t, []string{"vllm:gpu_cache_usage_perc"}, health.MissingMetrics)
+	assert.Equal(t, pmc.err.Error(), health.LastError)
+	assert.True(t, health.Healthy())
+	assert.True(t, cmp.Equal(pm.GetMetrics(), updated, cmpopts.IgnoreFields(Metrics{}, "UpdateTime", "SmoothedWaitingQueueSize", "SmoothedKVCacheUsagePercent")))
+
+	// A complete scrape clears the diagnostics.
+	pmc.err = nil
+	assert.Equal(t, 0, pm.scrape(ctx))
+	health = pm.GetScrapeHealth()
+	assert.Empty(t, health.MissingMetrics)
+	assert.Empty(t, health.LastError)
+}
+
+// scriptedPodMetricsClient returns the configured result for every pod.
+type scriptedPodMetricsClient struct {
+	res *Metrics
+	err error
+}
+
+func (c *scriptedPodMetricsClient) FetchMetrics(context.Context, *Pod, *Metrics, *v1alpha2.InferencePool) (*Metrics, error) {
+	if c.res == nil {
+		return nil, c.err
+	}
+	return c.res.Clone(), c.err
+}
+
 type fakeDataStore struct{}
 
 func (f *fakeDataStore) PoolGet() (*v1alpha2.InferencePool, error) {
@@ -0,0 +1,127 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+)
+
+// profiles are the built-in metric mappings of well known model servers, they return a new
+// mapping on each call so that callers can freely modify it.
+var profiles = map[v1alpha2.ModelServerProtocol]func() *MetricMapping{
+	v1alpha2.ModelServerProtocolVLLM: func() *MetricMapping {
+		return &MetricMapping{
+			TotalQueuedRequests: &MetricSpec{MetricName: "vllm:num_requests_waiting"},
+			KVCacheUtilization:  &MetricSpec{MetricName: "vllm:gpu_cache_usage_perc"},
+			LoraRequestInfo:     &MetricSpec{MetricName: "vllm:lora_requests_info"},
+
+			TotalRunningRequests: &MetricSpec{MetricName: "vllm:num_requests_running"},
+			// The hit rate is the share of the prefix cache queries that hit since the last scrape.
+			PrefixCacheHitRate: &MetricSpec{
+				MetricName: "vllm:gpu_prefix_cache_hits_total",
+				Rate:       true,
+				Over:       &MetricSpec{MetricName: "vllm:gpu_prefix_cache_queries_total", Rate: true},
+			},
+			PreemptionRate:  &MetricSpec{MetricName: "vllm:num_preemptions_total", Rate: true},
+			TokenThroughput: &MetricSpec{MetricName: "vllm:generation_tokens_total", Rate: true},
+		}
+	},
+	v1alpha2.ModelServerProtocolSGLang: func() *MetricMapping {
+		return &MetricMapping{
+			TotalQueuedRequests: &MetricSpec{MetricName: "sglang:num_queue_reqs"},
+			// token_usage is the fraction of the KV-cache token pool in use.
+			KVCacheUtilization: &MetricSpec{MetricName: "sglang:token_usage"},
+
+			TotalRunningRequests:    &MetricSpec{MetricName: "sglang:num_running_reqs"},
+			KVCacheMaxTokenCapacity: &MetricSpec{MetricName: "sglang:max_total_num_tokens"},
+			// SGLang computes the hit rate and the throughput itself.
+			PrefixCacheHitRate: &MetricSpec{MetricName: "sglang:cache_hit_rate"},
+			TokenThroughput:    &MetricSpec{MetricName: "sglang:gen_throughput"},
+		}
+	},
+	v1alpha2.ModelServerProtocolTGI: func() *MetricMapping {
+		// TGI doesn't expose the KV-cache utilization.
+		return &MetricMapping{
+			TotalQueuedRequests:  &MetricSpec{MetricName: "tgi_queue_size"},
+			TotalRunningRequests: &MetricSpec{MetricName: "tgi_batch_current_size"},
+		}
+	},
+	v1alpha2.ModelServerProtocolTritonTensorRTLLM: func() *MetricMapping {
+		// The utilization is derived from the used and max KV-cache block totals, which are exposed
+		// by all versions of the TensorRT-LLM backend.
+		return &MetricMapping{
+			TotalQueuedRequests: &MetricSpec{
+				MetricName: "nv_trt_llm_request_metrics",
+				Labels:     map[string]string{"request_type": "waiting"},
+			},
+			TotalRunningRequests: &MetricSpec{
+				MetricName: "nv_trt_llm_request_metrics",
+				Labels:     map[string]string{"request_type": "active"},
+			},
+			KVCacheUtilization: &MetricSpec{
+				MetricName: "nv_trt_llm_kv_cache_block_metrics",
+				Labels:     map[string]string{"kv_cache_block_type": "used"},
+				Over: &MetricSpec{
+					MetricName: "nv_trt_llm_kv_cache_block_metrics",
+					Labels:     map[string]string{"kv_cache_block_type": "max"},
+				},
+			},
+		}
+	},
+	v1alpha2.ModelServerProtocolJetStream: func() *MetricMapping {
+		return &MetricMapping{
+			TotalQueuedRequests: &MetricSpec{MetricName: "jetstream_prefill_backlog_size"},
+			// The share of decode slots in use is reported as a percentage (0-100).
+			KVCacheUtilization: &MetricSpec{MetricName: "jetstream_slots_used_percentage", Scale: 0.01},
+		}
+	},
+}
+
+// ModelServerProtocols returns the protocols with a built-in metric mapping.
+func ModelServerProtocols() []v1alpha2.ModelServerProtocol {
+	return []v1alpha2.ModelServerProtocol{
+		v1alpha2.ModelServerProtocolVLLM,
+		v1alpha2.ModelServerProtocolSGLang,
+		v1alpha2.ModelServerProtocolTGI,
+		v1alpha2.ModelServerProtocolTritonTensorRTLLM,
+		v1alpha2.ModelServerProtocolJetStream,
+	}
+}
+
+// ParseModelServerProtocol returns the protocol matching the given name, ignoring case and
+// separators, so that both "TritonTensorRTLLM" and "triton-tensorrt-llm" are accepted.
+func ParseModelServerProtocol(name string) (v1alpha2.ModelServerProtocol, error) {
+	normalized := strings.NewReplacer("-", "", "_", "").Replace(name)
+	for _, protocol := range ModelServerProtocols() {
+		if strings.EqualFold(normalized, string(protocol)) {
+			return protocol, nil
+		}
+	}
+	return "", fmt.Errorf("unknown model server protocol %q, must be one of %v", name, ModelServerProtocols())
+}
+
+// MetricMappingForProtocol returns the built-in metric mapping of the protocol.
+func MetricMappingForProtocol(protocol v1alpha2.ModelServerProtocol) (*MetricMapping, error) {
+	profile, ok := profiles[protocol]
+	if !ok {
+		return nil, fmt.Errorf("no metric mapping for model server protocol %q", protocol)
+	}
+	return profile(), nil
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/prometheus/common/expfmt"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+)
+
+func TestProfiles(t *testing.T) {
+	tests := []struct {
+		protocol v1alpha2.ModelServerProtocol
+		fixture  string
+		want     *Metrics
+		// wantCounters are the counters sampled to derive the rates at the next scrape.
+		wantCounters []string
+	}{
+		{
+			protocol: v1alpha2.ModelServerProtocolVLLM,
+			fixture:  "vllm.txt",
+			want: &Metrics{
+				WaitingQueueSize:    3,
+				RunningQueueSize:    4,
+				KVCacheUsagePercent: 0.42,
+				ActiveModels:        map[string]int{"sql-lora": 0, "tweet-summary": 0},
+				WaitingModels:       map[string]int{"sql-lora-v2": 0},
+				MaxActiveModels:     4,
+			},
+			wantCounters: []string{
+				"vllm:generation_tokens_total",
+				"vllm:gpu_prefix_cache_hits_total",
+				"vllm:gpu_prefix_cache_queries_total",
+				"vllm:num_preemptions_total",
+			},
+		},
+		{
+			protocol: v1alpha2.ModelServerProtocolSGLang,
+			fixture:  "sglang.txt",
+			want: &Metrics{
+				WaitingQueueSize:        5,
+				RunningQueueSize:        6,
+				KVCacheUsagePercent:     0.28,
+				KvCacheMaxTokenCapacity: 65536,
+				PrefixCacheHitRate:      0.35,
+				TokenThroughput:         812.5,
+			},
+		},
+		{
+			protocol: v1alpha2.ModelServerProtocolTGI,
+			fixture:  "tgi.txt",
+			want: &Metrics{
+				WaitingQueueSize: 2,
+				RunningQueueSize: 8,
+			},
+		},
+		{
+			protocol: v1alpha2.ModelServerProtocolTritonTensorRTLLM,
+			fixture:  "triton-tensorrt-llm.txt",
+			want: &Metrics{
+				WaitingQueueSize:    4,
+				RunningQueueSize:    10,
+				KVCacheUsagePercent: 0.25,
+			},
+		},
+		{
+			protocol: v1alpha2.ModelServerProtocolJetStream,
+			fixture:  "jetstream.txt",
+			want: &Metrics{
+				WaitingQueueSize:    9,
+				KVCacheUsagePercent: 0.75,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.protocol), func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatalf("Failed to open fixture: %v", err)
+			}
+			defer func() {
+				_ = f.Close()
+			}()
+			mapping, err := MetricMappingForProtocol(tt.protocol)
+			if err != nil {
+				t.Fatalf("MetricMappingForProtocol() unexpected error: %v", err)
+			}
+			metricFamilies, err := parseMetricFamilies(f, formatText, mapping.metricNames())
+			if err != nil {
+				t.Fatalf("Failed to parse fixture: %v", err)
+			}
+			p := &PodMetricsClientImpl{MetricMapping: mapping}
+			got, err := p.promToPodMetrics(metricFamilies, newMetrics(), mapping)
+			if err != nil {
+				t.Fatalf("promToPodMetrics() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got, cmpopts.EquateEmpty(), cmpopts.EquateApprox(0, 1e-9), cmpopts.IgnoreFields(Metrics{}, "CounterSamples")); diff != "" {
+				t.Errorf("Unexpected metrics (-want +got): %s", diff)
+			}
+			gotCounters := make([]string, 0, len(got.CounterSamples))
+			for key := range got.CounterSamples {
+				gotCounters = append(gotCounters, key)
+			}
+			if diff := cmp.Diff(tt.wantCounters, gotCounters, cmpopts.EquateEmpty(), cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("Unexpected counter samples (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestParseModelServerProtocol(t *testing.T) {
+	for _, protocol := range ModelServerProtocols() {
+		got, err := ParseModelServerProtocol(string(protocol))
+		if err != nil || got != protocol {
+			t.Errorf("ParseModelServerProtocol(%q) = %q, %v", protocol, got, err)
+		}
+		if _, ok := profiles[protocol]; !ok {
+			t.Errorf("No profile for protocol %q", protocol)
+		}
+	}
+	if got, err := ParseModelServerProtocol("triton-tensorrt-llm"); err != nil || got != v1alpha2.ModelServerProtocolTritonTensorRTLLM {
+		t.Errorf("ParseModelServerProtocol() should ignore case and separators, got %q, %v", got, err)
+	}
+	if _, err := ParseModelServerProtocol("ollama"); err == nil {
+		t.Errorf("ParseModelServerProtocol() expected error for unknown protocol")
+	}
+}
+
+func TestGetMetricValueDerivedZeroDenominator(t *testing.T) {
+	mapping, _ := MetricMappingForProtocol(v1alpha2.ModelServerProtocolTritonTensorRTLLM)
+	parser := expfmt.TextParser{}
+	metricFamilies, err := parser.TextToMetricFamilies(strings.NewReader(`# TYPE nv_trt_llm_kv_cache_block_metrics gauge
+nv_trt_llm_kv_cache_block_metrics{kv_cache_block_type="used"} 0
+nv_trt_llm_kv_cache_block_metrics{kv_cache_block_type="max"} 0
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse metrics: %v", err)
+	}
+	p := &PodMetricsClientImpl{}
+	if _, err := p.getMetricValue(metricFamilies, *mapping.KVCacheUtilization, nil); err == nil {
+		t.Errorf("getMetricValue() expected error for a zero denominator")
+	}
+}
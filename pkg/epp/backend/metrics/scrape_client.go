@@ -0,0 +1,285 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+)
+
+// ScrapeTLSConfig configures the TLS client scraping the model servers over HTTPS. The files are
+// read from the local file system, and re-read when they change.
+type ScrapeTLSConfig struct {
+	// CAFile is the PEM encoded CA bundle verifying the model servers. Defaults to the system roots.
+	CAFile string
+	// CertFile and KeyFile are the PEM encoded client certificate and key, for mutual TLS.
+	CertFile string
+	KeyFile  string
+	// ServerName verifies the certificates of the model servers, which are scraped by IP address.
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// Validate checks that the client certificate and key are set together.
+func (c ScrapeTLSConfig) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("the client certificate and key files must be set together")
+	}
+	return nil
+}
+
+// scrapeConfig returns the scheme, TLS configuration and bearer token file of the scrapes of the
+// pool. The fields the InferencePool doesn't set come from the client. The files set by the pool
+// must be in the PoolCredentialsDir, and a bearer token is only sent over HTTPS.
+func (p *PodMetricsClientImpl) scrapeConfig(pool *v1alpha2.InferencePool) (scheme string, tlsConfig ScrapeTLSConfig, tokenFile string, err error) {
+	scheme, tlsConfig, tokenFile = "http", p.TLS, p.BearerTokenFile
+	if p.Scheme != "" {
+		scheme = strings.ToLower(string(p.Scheme))
+	}
+	if m := pool.Spec.Metrics; m != nil {
+		if m.Scheme != nil {
+			scheme = strings.ToLower(string(*m.Scheme))
+		}
+		if m.BearerTokenFile != nil {
+			if tokenFile, err = p.poolCredentialsFile(*m.BearerTokenFile); err != nil {
+				return "", ScrapeTLSConfig{}, "", err
+			}
+		}
+		if t := m.TLS; t != nil {
+			if t.CAFile != nil {
+				if tlsConfig.CAFile, err = p.poolCredentialsFile(*t.CAFile); err != nil {
+					return "", ScrapeTLSConfig{}, "", err
+				}
+			}
+			// The client certificate and key are always set together.
+			if t.CertFile != nil && t.KeyFile != nil {
+				if tlsConfig.CertFile, err = p.poolCredentialsFile(*t.CertFile); err != nil {
+					return "", ScrapeTLSConfig{}, "", err
+				}
+				if tlsConfig.KeyFile, err = p.poolCredentialsFile(*t.KeyFile); err != nil {
+					return "", ScrapeTLSConfig{}, "", err
+				}
+			}
+			if t.ServerName != nil {
+				tlsConfig.ServerName = *t.ServerName
+			}
+			if t.InsecureSkipVerify != nil {
+				tlsConfig.InsecureSkipVerify = *t.InsecureSkipVerify
+			}
+		}
+	}
+	if tokenFile != "" && scheme != "https" {
+		return "", ScrapeTLSConfig{}, "", errors.New("a bearer token is only sent over HTTPS")
+	}
+	return scheme, tlsConfig, tokenFile, nil
+}
+
+// poolCredentialsFile checks that a file set by an InferencePool is in the PoolCredentialsDir,
+// following the symbolic links, and returns its path.
+func (p *PodMetricsClientImpl) poolCredentialsFile(path string) (string, error) {
+	if p.PoolCredentialsDir == "" {
+		return "", fmt.Errorf("file %s set by the InferencePool is refused without a pool credentials directory", path)
+	}
+	dir, err := filepath.EvalSymlinks(p.PoolCredentialsDir)
+	if err != nil {
+		return "", fmt.Errorf("invalid pool credentials directory: %w", err)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.PoolCredentialsDir, path)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(dir, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("file %s set by the InferencePool is not in the pool credentials directory %s", path, p.PoolCredentialsDir)
+	}
+	return path, nil
+}
+
+// scrapeClient returns the HTTP client scraping with the TLS configuration. The clients are cached
+// per configuration, so that the connections are reused across scrapes.
+func (p *PodMetricsClientImpl) scrapeClient(scheme string, tlsConfig ScrapeTLSConfig) (*http.Client, error) {
+	if scheme != "https" || tlsConfig == (ScrapeTLSConfig{}) {
+		return p.httpClient(), nil
+	}
+	if err := tlsConfig.Validate(); err != nil {
+		return nil, err
+	}
+	c, ok := p.tlsClients.Load(tlsConfig)
+	if !ok {
+		c, _ = p.tlsClients.LoadOrStore(tlsConfig, newTLSClient(tlsConfig))
+	}
+	return c.(*tlsClient).get()
+}
+
+// tlsClient builds the HTTP client of a TLS configuration, building it again when the CA bundle
+// changes. The client certificate is reloaded by the client itself.
+type tlsClient struct {
+	config ScrapeTLSConfig
+	ca     *watchedFile
+	certs  *certificateLoader
+
+	mu     sync.Mutex
+	caPEM  []byte
+	client *http.Client
+}
+
+func newTLSClient(config ScrapeTLSConfig) *tlsClient {
+	c := &tlsClient{config: config}
+	if config.CAFile != "" {
+		c.ca = &watchedFile{path: config.CAFile}
+	}
+	if config.CertFile != "" {
+		c.certs = &certificateLoader{cert: &watchedFile{path: config.CertFile}, key: &watchedFile{path: config.KeyFile}}
+	}
+	return c
+}
+
+func (c *tlsClient) get() (*http.Client, error) {
+	var caPEM []byte
+	if c.ca != nil {
+		var err error
+		if caPEM, err = c.ca.read(); err != nil {
+			return nil, fmt.Errorf("failed to read the CA file: %w", err)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client != nil && bytes.Equal(caPEM, c.caPEM) {
+		return c.client, nil
+	}
+
+	config := &tls.Config{
+		ServerName:         c.config.ServerName,
+		InsecureSkipVerify: c.config.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if caPEM != nil {
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(caPEM) {
+			// The bundle may be in the middle of an update, keep the previous client.
+			if c.client != nil {
+				return c.client, nil
+			}
+			return nil, fmt.Errorf("no PEM encoded certificate in the CA file %s", c.config.CAFile)
+		}
+	}
+	if c.certs != nil {
+		if _, err := c.certs.load(); err != nil {
+			return nil, err
+		}
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return c.certs.load()
+		}
+	}
+	transport := defaultScrapeTransport.Clone()
+	transport.TLSClientConfig = config
+	if c.client != nil {
+		c.client.CloseIdleConnections()
+	}
+	c.client, c.caPEM = &http.Client{Transport: transport}, caPEM
+	return c.client, nil
+}
+
+// bearerToken returns the content of the bearer token file, re-read when the file changes.
+func (p *PodMetricsClientImpl) bearerToken(path string) (string, error) {
+	f, _ := p.tokenFiles.LoadOrStore(path, &watchedFile{path: path})
+	content, err := f.(*watchedFile).read()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the bearer token file: %w", err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// watchedFile caches the content of a file, which is re-read when its modification time or size
+// changes. This picks up the credentials rotated in mounted Secrets, as os.Stat follows the
+// symbolic links that the kubelet swaps on updates.
+type watchedFile struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	content []byte
+}
+
+// read returns the content of the file.
+func (f *watchedFile) read() ([]byte, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.content != nil && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.content, nil
+	}
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	f.content, f.modTime, f.size = content, info.ModTime(), info.Size()
+	return content, nil
+}
+
+// certificateLoader loads a client certificate, parsing it again when its files change.
+type certificateLoader struct {
+	cert, key *watchedFile
+
+	mu      sync.Mutex
+	certPEM []byte
+	keyPEM  []byte
+	parsed  *tls.Certificate
+}
+
+func (l *certificateLoader) load() (*tls.Certificate, error) {
+	certPEM, err := l.cert.read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the client certificate: %w", err)
+	}
+	keyPEM, err := l.key.read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the client key: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.parsed != nil && bytes.Equal(certPEM, l.certPEM) && bytes.Equal(keyPEM, l.keyPEM) {
+		return l.parsed, nil
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		// The certificate and the key may not be updated at the same time, keep the previous pair.
+		if l.parsed != nil {
+			return l.parsed, nil
+		}
+		return nil, fmt.Errorf("failed to load the client certificate: %w", err)
+	}
+	l.parsed, l.certPEM, l.keyPEM = &cert, certPEM, keyPEM
+	return l.parsed, nil
+}
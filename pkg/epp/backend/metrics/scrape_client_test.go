@@ -0,0 +1,430 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+	logutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/logging"
+)
+
+func TestScrapeConfig(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"tls.crt", "tls.key", "token"} {
+		writeFile(t, dir, name, []byte(name))
+	}
+	outside := t.TempDir()
+	writeFile(t, outside, "token", []byte("token"))
+	if err := os.Symlink(filepath.Join(outside, "token"), filepath.Join(dir, "escape")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	p := &PodMetricsClientImpl{
+		Scheme:             v1alpha2.MetricsSchemeHTTPS,
+		TLS:                ScrapeTLSConfig{CAFile: "/etc/ca.pem", CertFile: "/etc/tls.crt", KeyFile: "/etc/tls.key"},
+		BearerTokenFile:    "/etc/token",
+		PoolCredentialsDir: dir,
+	}
+	tests := []struct {
+		name          string
+		client        *PodMetricsClientImpl
+		metrics       *v1alpha2.ModelServerMetrics
+		wantScheme    string
+		wantTLS       ScrapeTLSConfig
+		wantTokenFile string
+		wantErr       bool
+	}{
+		{
+			name:          "defaults of the client",
+			wantScheme:    "https",
+			wantTLS:       p.TLS,
+			wantTokenFile: "/etc/token",
+		},
+		{
+			name: "pool overrides",
+			metrics: &v1alpha2.ModelServerMetrics{
+				TLS: &v1alpha2.MetricsTLSConfig{
+					CertFile:           ptr.To(filepath.Join(dir, "tls.crt")),
+					KeyFile:            ptr.To("tls.key"),
+					ServerName:         ptr.To("model-server"),
+					InsecureSkipVerify: ptr.To(true),
+				},
+				BearerTokenFile: ptr.To(filepath.Join(dir, "token")),
+			},
+			wantScheme: "https",
+			wantTLS: ScrapeTLSConfig{
+				CAFile:             "/etc/ca.pem",
+				CertFile:           filepath.Join(dir, "tls.crt"),
+				KeyFile:            filepath.Join(dir, "tls.key"),
+				ServerName:         "model-server",
+				InsecureSkipVerify: true,
+			},
+			wantTokenFile: filepath.Join(dir, "token"),
+		},
+		{
+			name:    "bearer token over HTTP",
+			metrics: &v1alpha2.ModelServerMetrics{Scheme: ptr.To(v1alpha2.MetricsSchemeHTTP)},
+			wantErr: true,
+		},
+		{
+			name:       "HTTP without bearer token",
+			client:     &PodMetricsClientImpl{},
+			wantScheme: "http",
+		},
+		{
+			name:    "file outside of the credentials directory",
+			metrics: &v1alpha2.ModelServerMetrics{BearerTokenFile: ptr.To(filepath.Join(outside, "token"))},
+			wantErr: true,
+		},
+		{
+			name:    "relative path escaping the credentials directory",
+			metrics: &v1alpha2.ModelServerMetrics{BearerTokenFile: ptr.To("../" + filepath.Base(outside) + "/token")},
+			wantErr: true,
+		},
+		{
+			name:    "symbolic link escaping the credentials directory",
+			metrics: &v1alpha2.ModelServerMetrics{BearerTokenFile: ptr.To(filepath.Join(dir, "escape"))},
+			wantErr: true,
+		},
+		{
+			name:    "service account token",
+			metrics: &v1alpha2.ModelServerMetrics{BearerTokenFile: ptr.To("/var/run/secrets/kubernetes.io/serviceaccount/token")},
+			wantErr: true,
+		},
+		{
+			name:    "pool files without credentials directory",
+			client:  &PodMetricsClientImpl{Scheme: v1alpha2.MetricsSchemeHTTPS},
+			metrics: &v1alpha2.ModelServerMetrics{TLS: &v1alpha2.MetricsTLSConfig{CAFile: ptr.To(filepath.Join(dir, "tls.crt"))}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := p
+			if tt.client != nil {
+				client = tt.client
+			}
+			pool := &v1alpha2.InferencePool{Spec: v1alpha2.InferencePoolSpec{Metrics: tt.metrics}}
+			scheme, tlsConfig, tokenFile, err := client.scrapeConfig(pool)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("scrapeConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if scheme != tt.wantScheme || tokenFile != tt.wantTokenFile {
+				t.Errorf("scrapeConfig() = %q, %q, want %q, %q", scheme, tokenFile, tt.wantScheme, tt.wantTokenFile)
+			}
+			if diff := cmp.Diff(tt.wantTLS, tlsConfig); diff != "" {
+				t.Errorf("Unexpected TLS config (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestFetchMetricsTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	writeFile(t, dir, "ca.pem", ca.certPEM)
+	clientCert, clientKey := ca.issue(t, "epp")
+	writeFile(t, dir, "tls.crt", clientCert)
+	writeFile(t, dir, "tls.key", clientKey)
+	writeFile(t, dir, "token", []byte("first-token\n"))
+
+	var gotAuthorization string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuthorization = r.Header.Get("Authorization")
+		fmt.Fprintln(w, "# TYPE tgi_queue_size gauge")
+		fmt.Fprintln(w, "tgi_queue_size 7")
+	}))
+	serverCert, serverKey := ca.issue(t, "model-server")
+	cert, err := tls.X509KeyPair(serverCert, serverKey)
+	if err != nil {
+		t.Fatalf("Failed to load the server certificate: %v", err)
+	}
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    ca.pool(),
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+	port, err := strconv.Atoi(serverURL.Port())
+	if err != nil {
+		t.Fatalf("Failed to parse server port: %v", err)
+	}
+	ctx := logutil.NewTestLoggerIntoContext(context.Background())
+	pod := &Pod{Address: serverURL.Hostname()}
+	pool := &v1alpha2.InferencePool{Spec: v1alpha2.InferencePoolSpec{TargetPortNumber: int32(port)}}
+	mapping := &MetricMapping{TotalQueuedRequests: &MetricSpec{MetricName: "tgi_queue_size"}}
+
+	tests := []struct {
+		name    string
+		tls     ScrapeTLSConfig
+		wantErr bool
+	}{
+		{
+			name: "mutual TLS",
+			tls: ScrapeTLSConfig{
+				CAFile:   filepath.Join(dir, "ca.pem"),
+				CertFile: filepath.Join(dir, "tls.crt"),
+				KeyFile:  filepath.Join(dir, "tls.key"),
+			},
+		},
+		{
+			name: "insecure skip verify",
+			tls: ScrapeTLSConfig{
+				CertFile:           filepath.Join(dir, "tls.crt"),
+				KeyFile:            filepath.Join(dir, "tls.key"),
+				InsecureSkipVerify: true,
+			},
+		},
+		{
+			name:    "unknown authority",
+			tls:     ScrapeTLSConfig{CertFile: filepath.Join(dir, "tls.crt"), KeyFile: filepath.Join(dir, "tls.key")},
+			wantErr: true,
+		},
+		{
+			name:    "no client certificate",
+			tls:     ScrapeTLSConfig{CAFile: filepath.Join(dir, "ca.pem")},
+			wantErr: true,
+		},
+		{
+			name:    "certificate without key",
+			tls:     ScrapeTLSConfig{CAFile: filepath.Join(dir, "ca.pem"), CertFile: filepath.Join(dir, "tls.crt")},
+			wantErr: true,
+		},
+		{
+			name:    "missing CA file",
+			tls:     ScrapeTLSConfig{CAFile: filepath.Join(dir, "missing.pem")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &PodMetricsClientImpl{
+				MetricMapping:   mapping,
+				Scheme:          v1alpha2.MetricsSchemeHTTPS,
+				TLS:             tt.tls,
+				BearerTokenFile: filepath.Join(dir, "token"),
+			}
+			got, err := p.FetchMetrics(ctx, pod, &Metrics{}, pool)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FetchMetrics() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.WaitingQueueSize != 7 {
+				t.Errorf("FetchMetrics() WaitingQueueSize = %d, want 7", got.WaitingQueueSize)
+			}
+			if gotAuthorization != "Bearer first-token" {
+				t.Errorf("Authorization = %q, want %q", gotAuthorization, "Bearer first-token")
+			}
+		})
+	}
+
+	t.Run("rotated token", func(t *testing.T) {
+		p := &PodMetricsClientImpl{
+			MetricMapping:   mapping,
+			Scheme:          v1alpha2.MetricsSchemeHTTPS,
+			TLS:             ScrapeTLSConfig{CAFile: filepath.Join(dir, "ca.pem"), CertFile: filepath.Join(dir, "tls.crt"), KeyFile: filepath.Join(dir, "tls.key")},
+			BearerTokenFile: filepath.Join(dir, "token"),
+		}
+		for _, token := range []string{"first-token", "rotated-token-2"} {
+			writeFile(t, dir, "token", []byte(token))
+			if _, err := p.FetchMetrics(ctx, pod, &Metrics{}, pool); err != nil {
+				t.Fatalf("FetchMetrics() unexpected error: %v", err)
+			}
+			if want := "Bearer " + token; gotAuthorization != want {
+				t.Errorf("Authorization = %q, want %q", gotAuthorization, want)
+			}
+		}
+	})
+}
+
+func TestTLSClientCARotation(t *testing.T) {
+	dir := t.TempDir()
+	first := newTestCA(t)
+	writeFile(t, dir, "ca.pem", first.certPEM)
+	c := newTLSClient(ScrapeTLSConfig{CAFile: filepath.Join(dir, "ca.pem")})
+	client, err := c.get()
+	if err != nil {
+		t.Fatalf("get() unexpected error: %v", err)
+	}
+	if got, err := c.get(); err != nil || got != client {
+		t.Errorf("get() = %v, %v, want the cached client", got, err)
+	}
+
+	// An invalid bundle, e.g. in the middle of an update, keeps the previous client.
+	writeFile(t, dir, "ca.pem", []byte("not a certificate"))
+	if got, err := c.get(); err != nil || got != client {
+		t.Errorf("get() = %v, %v, want the previous client", got, err)
+	}
+
+	second := newTestCA(t)
+	writeFile(t, dir, "ca.pem", second.certPEM)
+	rotated, err := c.get()
+	if err != nil {
+		t.Fatalf("get() unexpected error: %v", err)
+	}
+	if rotated == client {
+		t.Fatal("get() returned the previous client after the CA rotation")
+	}
+	roots := rotated.Transport.(*http.Transport).TLSClientConfig.RootCAs
+	if !roots.Equal(second.pool()) {
+		t.Error("get() client doesn't trust the rotated CA")
+	}
+}
+
+func TestCertificateLoaderRotation(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, "first")
+	writeFile(t, dir, "tls.crt", certPEM)
+	writeFile(t, dir, "tls.key", keyPEM)
+	l := &certificateLoader{cert: &watchedFile{path: filepath.Join(dir, "tls.crt")}, key: &watchedFile{path: filepath.Join(dir, "tls.key")}}
+	first, err := l.load()
+	if err != nil {
+		t.Fatalf("load() unexpected error: %v", err)
+	}
+
+	// Only the certificate is rotated, the previous pair is kept until the key is.
+	certPEM, keyPEM = ca.issue(t, "second")
+	writeFile(t, dir, "tls.crt", certPEM)
+	if got, err := l.load(); err != nil || got != first {
+		t.Errorf("load() = %v, %v, want the previous certificate", got, err)
+	}
+	writeFile(t, dir, "tls.key", keyPEM)
+	got, err := l.load()
+	if err != nil {
+		t.Fatalf("load() unexpected error: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(got.Certificate[0])
+	if err != nil {
+		t.Fatalf("Failed to parse the certificate: %v", err)
+	}
+	if leaf.Subject.CommonName != "second" {
+		t.Errorf("load() = %q, want the rotated certificate", leaf.Subject.CommonName)
+	}
+}
+
+// testCA issues certificates valid for the loopback address.
+type testCA struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	return &testCA{cert: cert, key: key, certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (ca *testCA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+// issue returns a PEM encoded certificate and key, valid for both client and server authentication.
+func (ca *testCA) issue(t *testing.T, name string) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeFile writes a file with a modification time later than the previous writes, so that the
+// changes are detected on file systems with a coarse timestamp granularity.
+func writeFile(t *testing.T, dir, name string, content []byte) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	writes++
+	modTime := time.Now().Add(time.Duration(writes) * time.Second)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Failed to set the modification time of %s: %v", name, err)
+	}
+}
+
+var writes int
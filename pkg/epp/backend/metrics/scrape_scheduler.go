@@ -0,0 +1,228 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"container/heap"
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	logutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/logging"
+)
+
+const (
+	// DefaultScrapeWorkers is the default number of pods that are scraped concurrently.
+	DefaultScrapeWorkers = 32
+	// DefaultScrapeJitter is the default fraction of the interval by which each scrape is randomly shifted.
+	DefaultScrapeJitter = 0.1
+	// DefaultScrapeMaxBackoff is the default upper bound of the interval between scrapes of a failing pod.
+	DefaultScrapeMaxBackoff = 5 * time.Second
+
+	// minScrapeInterval bounds the interval from below, as a non-positive interval can't be
+	// jittered and would have the workers scrape in a busy loop.
+	minScrapeInterval = time.Millisecond
+	// maxBackoffShift bounds the exponent of the back-off, to avoid overflowing the interval.
+	maxBackoffShift = 10
+)
+
+// ScrapeOptions configures how the metrics of the pods are refreshed.
+type ScrapeOptions struct {
+	// Interval is the time between two scrapes of a healthy pod.
+	Interval time.Duration
+	// Workers bounds the number of scrapes in flight.
+	Workers int
+	// Jitter randomly shifts each scheduled scrape by up to this fraction of its interval, so that
+	// the pods added at the same time are not scraped in lockstep.
+	Jitter float64
+	// MaxBackoff bounds the interval between scrapes of a pod that keeps failing. The interval is
+	// doubled on each consecutive failure, and never gets shorter than Interval.
+	MaxBackoff time.Duration
+	// Smoothing configures the smoothing of the refreshed metrics. No smoothing by default.
+	Smoothing SmoothingConfig
+}
+
+// DefaultScrapeOptions returns the ScrapeOptions used by NewPodMetricsFactory.
+func DefaultScrapeOptions(interval time.Duration) ScrapeOptions {
+	return ScrapeOptions{
+		Interval:   interval,
+		Workers:    DefaultScrapeWorkers,
+		Jitter:     DefaultScrapeJitter,
+		MaxBackoff: DefaultScrapeMaxBackoff,
+	}
+}
+
+// scrapeScheduler refreshes the metrics of all the pods from a bounded pool of workers. Pods are
+// kept in a min-heap ordered by the time of their next scrape; a pod is rescheduled only once its
+// scrape completes, so that a slow pod is never scraped concurrently with itself.
+type scrapeScheduler struct {
+	opts ScrapeOptions
+
+	mu    sync.Mutex
+	queue scrapeQueue
+
+	// wake signals the dispatcher that the head of the queue may have changed.
+	wake chan struct{}
+	work chan *podMetrics
+	once sync.Once
+}
+
+func newScrapeScheduler(opts ScrapeOptions) *scrapeScheduler {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultScrapeWorkers
+	}
+	if opts.Interval < minScrapeInterval {
+		opts.Interval = minScrapeInterval
+	}
+	if opts.Jitter < 0 {
+		opts.Jitter = 0
+	}
+	if opts.MaxBackoff < opts.Interval {
+		opts.MaxBackoff = opts.Interval
+	}
+	return &scrapeScheduler{
+		opts: opts,
+		wake: make(chan struct{}, 1),
+		work: make(chan *podMetrics),
+	}
+}
+
+// add schedules the first scrape of the pod at a random point within the first interval. The
+// dispatcher and workers are started on first use, and stop when ctx is cancelled.
+func (s *scrapeScheduler) add(ctx context.Context, pm *podMetrics) {
+	s.once.Do(func() {
+		log.FromContext(ctx).V(logutil.DEFAULT).Info("Starting metrics scrape workers", "workers", s.opts.Workers, "interval", s.opts.Interval)
+		for range s.opts.Workers {
+			go s.runWorker(ctx)
+		}
+		go s.dispatch(ctx)
+	})
+	s.push(pm, time.Now().Add(time.Duration(rand.Int63n(int64(s.opts.Interval)+1))))
+}
+
+func (s *scrapeScheduler) push(pm *podMetrics, next time.Time) {
+	s.mu.Lock()
+	heap.Push(&s.queue, &scrapeItem{pm: pm, next: next})
+	s.mu.Unlock()
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch hands the pods over to the workers as their scrapes become due.
+func (s *scrapeScheduler) dispatch(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		var due *scrapeItem
+		wait := time.Duration(-1)
+		if s.queue.Len() > 0 {
+			if d := time.Until(s.queue[0].next); d <= 0 {
+				due = heap.Pop(&s.queue).(*scrapeItem)
+			} else {
+				wait = d
+			}
+		}
+		s.mu.Unlock()
+
+		if due != nil {
+			if due.pm.stopped() {
+				continue
+			}
+			select {
+			case s.work <- due.pm:
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		var timerC <-chan time.Time
+		if wait >= 0 {
+			timer.Reset(wait)
+			timerC = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-timerC:
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+	}
+}
+
+func (s *scrapeScheduler) runWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case pm := <-s.work:
+			failures := pm.scrape(ctx)
+			if pm.stopped() {
+				continue
+			}
+			s.push(pm, time.Now().Add(s.nextInterval(failures)))
+		}
+	}
+}
+
+// nextInterval returns the time until the next scrape of a pod that failed the given number of
+// consecutive times, with exponential back-off and jitter applied.
+func (s *scrapeScheduler) nextInterval(failures int) time.Duration {
+	interval := s.opts.Interval
+	if failures > 0 {
+		interval <<= min(failures, maxBackoffShift)
+		if interval > s.opts.MaxBackoff || interval <= 0 {
+			interval = s.opts.MaxBackoff
+		}
+	}
+	if s.opts.Jitter > 0 {
+		interval += time.Duration((rand.Float64()*2 - 1) * s.opts.Jitter * float64(interval))
+	}
+	return interval
+}
+
+type scrapeItem struct {
+	pm   *podMetrics
+	next time.Time
+}
+
+// scrapeQueue implements heap.Interface.
+type scrapeQueue []*scrapeItem
+
+func (q scrapeQueue) Len() int           { return len(q) }
+func (q scrapeQueue) Less(i, j int) bool { return q[i].next.Before(q[j].next) }
+func (q scrapeQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *scrapeQueue) Push(x any)        { *q = append(*q, x.(*scrapeItem)) }
+func (q *scrapeQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return item
+}
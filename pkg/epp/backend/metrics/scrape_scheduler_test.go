@@ -0,0 +1,162 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+)
+
+func TestScrapeNextInterval(t *testing.T) {
+	s := newScrapeScheduler(ScrapeOptions{Interval: 100 * time.Millisecond, MaxBackoff: time.Second})
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 100 * time.Millisecond},
+		{failures: 1, want: 200 * time.Millisecond},
+		{failures: 3, want: 800 * time.Millisecond},
+		{failures: 4, want: time.Second},
+		{failures: 100, want: time.Second},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("failures=%d", test.failures), func(t *testing.T) {
+			if got := s.nextInterval(test.failures); got != test.want {
+				t.Errorf("nextInterval(%d) = %v, want %v", test.failures, got, test.want)
+			}
+		})
+	}
+}
+
+func TestScrapeIntervalClamped(t *testing.T) {
+	for _, interval := range []time.Duration{-time.Second, 0} {
+		t.Run(interval.String(), func(t *testing.T) {
+			s := newScrapeScheduler(ScrapeOptions{Interval: interval})
+			if got := s.nextInterval(0); got != minScrapeInterval {
+				t.Errorf("nextInterval(0) = %v, want %v", got, minScrapeInterval)
+			}
+		})
+	}
+}
+
+func TestScrapeNextIntervalJitter(t *testing.T) {
+	s := newScrapeScheduler(ScrapeOptions{Interval: 100 * time.Millisecond, Jitter: 0.1})
+	for range 100 {
+		got := s.nextInterval(0)
+		assert.GreaterOrEqual(t, got, 90*time.Millisecond)
+		assert.LessOrEqual(t, got, 110*time.Millisecond)
+	}
+}
+
+func TestScrapeWorkersBoundConcurrency(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	const workers = 2
+	pmc := &blockingPodMetricsClient{release: make(chan struct{})}
+	pmf := NewPodMetricsFactoryWithOptions(pmc, ScrapeOptions{Interval: time.Millisecond, Workers: workers})
+
+	for i := range 10 {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod%d", i), Namespace: "default"}}
+		pmf.NewPodMetrics(ctx, pod, &fakeDataStore{})
+	}
+	assert.EventuallyWithT(t, func(collect *assert.CollectT) {
+		assert.Equal(collect, int32(workers), pmc.inFlight.Load())
+	}, time.Second, time.Millisecond)
+	// No further scrape may start while the workers are busy.
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(workers), pmc.maxInFlight.Load())
+
+	close(pmc.release)
+	assert.EventuallyWithT(t, func(collect *assert.CollectT) {
+		assert.GreaterOrEqual(collect, pmc.calls.Load(), int32(10))
+	}, time.Second, time.Millisecond)
+	assert.LessOrEqual(t, pmc.maxInFlight.Load(), int32(workers))
+}
+
+func TestScrapeBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pmc := &blockingPodMetricsClient{release: make(chan struct{}), err: errors.New("unreachable")}
+	close(pmc.release)
+	pmf := NewPodMetricsFactoryWithOptions(pmc, ScrapeOptions{Interval: time.Millisecond, MaxBackoff: 50 * time.Millisecond})
+
+	pm := pmf.NewPodMetrics(ctx, pod1, &fakeDataStore{})
+	defer pm.StopRefreshLoop()
+	time.Sleep(200 * time.Millisecond)
+	// Without back-off the pod would have been scraped ~200 times.
+	calls := pmc.calls.Load()
+	assert.Greater(t, calls, int32(3))
+	assert.Less(t, calls, int32(20))
+}
+
+func TestStoppedPodIsNotScraped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pmc := &blockingPodMetricsClient{release: make(chan struct{})}
+	close(pmc.release)
+	pmf := NewPodMetricsFactoryWithOptions(pmc, ScrapeOptions{Interval: time.Millisecond})
+
+	pm := pmf.NewPodMetrics(ctx, pod1, &fakeDataStore{})
+	assert.EventuallyWithT(t, func(collect *assert.CollectT) {
+		assert.Positive(collect, pmc.calls.Load())
+	}, time.Second, time.Millisecond)
+	pm.StopRefreshLoop()
+	time.Sleep(10 * time.Millisecond)
+	calls := pmc.calls.Load()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, calls, pmc.calls.Load())
+}
+
+// blockingPodMetricsClient blocks the scrapes until release is closed, and tracks their concurrency.
+type blockingPodMetricsClient struct {
+	release     chan struct{}
+	err         error
+	mu          sync.Mutex
+	calls       atomic.Int32
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+}
+
+func (c *blockingPodMetricsClient) FetchMetrics(ctx context.Context, _ *Pod, existing *Metrics, _ *v1alpha2.InferencePool) (*Metrics, error) {
+	c.calls.Add(1)
+	n := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	c.mu.Lock()
+	if n > c.maxInFlight.Load() {
+		c.maxInFlight.Store(n)
+	}
+	c.mu.Unlock()
+	select {
+	case <-c.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return existing.Clone(), nil
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SmoothingMethod is the method used to smooth the successive values of a metric.
+type SmoothingMethod string
+
+const (
+	// SmoothingNone uses the last value as is.
+	SmoothingNone SmoothingMethod = "none"
+	// SmoothingEWMA uses an exponentially weighted moving average, where the weight of a value halves
+	// every HalfLife.
+	SmoothingEWMA SmoothingMethod = "ewma"
+	// SmoothingWindowAvg uses the average of the values over the last Window.
+	SmoothingWindowAvg SmoothingMethod = "avg"
+	// SmoothingWindowMax uses the maximum of the values over the last Window.
+	SmoothingWindowMax SmoothingMethod = "max"
+
+	// maxWindowSamples bounds the memory of a window, when metrics are pushed on every response.
+	maxWindowSamples = 1024
+)
+
+// SmoothingSpec configures the smoothing of a metric.
+type SmoothingSpec struct {
+	Method SmoothingMethod
+	// Period is the half-life of SmoothingEWMA, or the window of SmoothingWindowAvg and SmoothingWindowMax.
+	Period time.Duration
+}
+
+// SmoothingConfig configures the smoothing of the metrics used for scheduling.
+type SmoothingConfig struct {
+	WaitingQueueSize    SmoothingSpec
+	KVCacheUsagePercent SmoothingSpec
+}
+
+// ParseSmoothingSpec parses a smoothing spec of the form "<method>:<period>", for example "ewma:2s",
+// "avg:1s" or "max:500ms", or "none".
+func ParseSmoothingSpec(s string) (SmoothingSpec, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.EqualFold(s, string(SmoothingNone)) {
+		return SmoothingSpec{Method: SmoothingNone}, nil
+	}
+	method, periodStr, ok := strings.Cut(s, ":")
+	if !ok {
+		return SmoothingSpec{}, fmt.Errorf("invalid smoothing %q, expected <method>:<period>", s)
+	}
+	spec := SmoothingSpec{Method: SmoothingMethod(strings.ToLower(method))}
+	switch spec.Method {
+	case SmoothingEWMA, SmoothingWindowAvg, SmoothingWindowMax:
+	default:
+		return SmoothingSpec{}, fmt.Errorf("unknown smoothing method %q, expected one of %s, %s, %s or %s",
+			method, SmoothingNone, SmoothingEWMA, SmoothingWindowAvg, SmoothingWindowMax)
+	}
+	period, err := time.ParseDuration(periodStr)
+	if err != nil {
+		return SmoothingSpec{}, fmt.Errorf("invalid smoothing period in %q: %w", s, err)
+	}
+	if period <= 0 {
+		return SmoothingSpec{}, fmt.Errorf("smoothing period in %q must be positive", s)
+	}
+	spec.Period = period
+	return spec, nil
+}
+
+// Enabled reports whether any metric is smoothed.
+func (c SmoothingConfig) Enabled() bool {
+	return c.WaitingQueueSize.enabled() || c.KVCacheUsagePercent.enabled()
+}
+
+func (s SmoothingSpec) enabled() bool {
+	return s.Method != "" && s.Method != SmoothingNone
+}
+
+func (s SmoothingSpec) String() string {
+	if !s.enabled() {
+		return string(SmoothingNone)
+	}
+	return fmt.Sprintf("%s:%s", s.Method, s.Period)
+}
+
+// smoother smooths the successive values of a metric of a pod. It is not safe for concurrent use.
+type smoother interface {
+	// add records the value observed at the given time, and returns the smoothed value.
+	add(now time.Time, value float64) float64
+}
+
+func newSmoother(spec SmoothingSpec) smoother {
+	switch spec.Method {
+	case SmoothingEWMA:
+		return &ewmaSmoother{halfLife: spec.Period}
+	case SmoothingWindowAvg:
+		return &windowSmoother{window: spec.Period}
+	case SmoothingWindowMax:
+		return &windowSmoother{window: spec.Period, max: true}
+	default:
+		return noSmoother{}
+	}
+}
+
+// podSmoothing smooths the metrics of a pod, which are updated concurrently by the scrapes and the
+// load reports.
+type podSmoothing struct {
+	mu                  sync.Mutex
+	waitingQueueSize    smoother
+	kvCacheUsagePercent smoother
+}
+
+func newPodSmoothing(config SmoothingConfig) *podSmoothing {
+	return &podSmoothing{
+		waitingQueueSize:    newSmoother(config.WaitingQueueSize),
+		kvCacheUsagePercent: newSmoother(config.KVCacheUsagePercent),
+	}
+}
+
+// apply sets the smoothed values of the metrics, updated at the given time.
+func (s *podSmoothing) apply(now time.Time, m *Metrics) {
+	if s == nil {
+		m.SmoothedWaitingQueueSize = float64(m.WaitingQueueSize)
+		m.SmoothedKVCacheUsagePercent = m.KVCacheUsagePercent
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m.SmoothedWaitingQueueSize = s.waitingQueueSize.add(now, float64(m.WaitingQueueSize))
+	m.SmoothedKVCacheUsagePercent = s.kvCacheUsagePercent.add(now, m.KVCacheUsagePercent)
+}
+
+type noSmoother struct{}
+
+func (noSmoother) add(_ time.Time, value float64) float64 { return value }
+
+// ewmaSmoother weighs the values by the time elapsed between them, so that the smoothing doesn't
+// depend on how often the metrics are refreshed.
+type ewmaSmoother struct {
+	halfLife time.Duration
+	last     time.Time
+	value    float64
+}
+
+func (s *ewmaSmoother) add(now time.Time, value float64) float64 {
+	if s.last.IsZero() {
+		s.last, s.value = now, value
+		return value
+	}
+	elapsed := now.Sub(s.last)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	alpha := 1 - math.Exp2(-float64(elapsed)/float64(s.halfLife))
+	s.value += alpha * (value - s.value)
+	s.last = now
+	return s.value
+}
+
+type sample struct {
+	time  time.Time
+	value float64
+}
+
+// windowSmoother returns the average, or the maximum, of the values over the last window.
+type windowSmoother struct {
+	window  time.Duration
+	max     bool
+	samples []sample
+}
+
+func (s *windowSmoother) add(now time.Time, value float64) float64 {
+	s.samples = append(s.samples, sample{time: now, value: value})
+	first := 0
+	for first < len(s.samples)-1 && (now.Sub(s.samples[first].time) > s.window || len(s.samples)-first > maxWindowSamples) {
+		first++
+	}
+	if first > 0 {
+		s.samples = append(s.samples[:0], s.samples[first:]...)
+	}
+
+	result := s.samples[0].value
+	sum := 0.0
+	for _, smp := range s.samples {
+		sum += smp.value
+		result = math.Max(result, smp.value)
+	}
+	if s.max {
+		return result
+	}
+	return sum / float64(len(s.samples))
+}
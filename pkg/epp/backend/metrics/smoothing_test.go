@@ -0,0 +1,147 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSmoothingSpec(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    SmoothingSpec
+		wantErr bool
+	}{
+		{input: "", want: SmoothingSpec{Method: SmoothingNone}},
+		{input: "none", want: SmoothingSpec{Method: SmoothingNone}},
+		{input: "ewma:2s", want: SmoothingSpec{Method: SmoothingEWMA, Period: 2 * time.Second}},
+		{input: "AVG:500ms", want: SmoothingSpec{Method: SmoothingWindowAvg, Period: 500 * time.Millisecond}},
+		{input: "max:1s", want: SmoothingSpec{Method: SmoothingWindowMax, Period: time.Second}},
+		{input: "ewma", wantErr: true},
+		{input: "median:1s", wantErr: true},
+		{input: "ewma:fast", wantErr: true},
+		{input: "ewma:0s", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			got, err := ParseSmoothingSpec(test.input)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParseSmoothingSpec() error = %v, wantErr %v", err, test.wantErr)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+// oscillate feeds the smoother with a value alternating between low and high every step, and
+// returns the smoothed values.
+func oscillate(s smoother, low, high float64, step time.Duration, steps int) []float64 {
+	start := time.Unix(0, 0)
+	out := make([]float64, 0, steps)
+	for i := range steps {
+		value := low
+		if i%2 == 1 {
+			value = high
+		}
+		out = append(out, s.add(start.Add(time.Duration(i)*step), value))
+	}
+	return out
+}
+
+func TestSmoothersUnderOscillation(t *testing.T) {
+	const steps = 200
+	step := 50 * time.Millisecond
+
+	tests := []struct {
+		name string
+		spec SmoothingSpec
+		// After warm-up, the smoothed values must stay within [min, max].
+		min, max float64
+	}{
+		{name: "none follows the input", spec: SmoothingSpec{Method: SmoothingNone}, min: 0, max: 100},
+		{name: "ewma converges to the mean", spec: SmoothingSpec{Method: SmoothingEWMA, Period: time.Second}, min: 45, max: 55},
+		{name: "window average is the mean", spec: SmoothingSpec{Method: SmoothingWindowAvg, Period: time.Second}, min: 47, max: 53},
+		{name: "window max holds the peak", spec: SmoothingSpec{Method: SmoothingWindowMax, Period: time.Second}, min: 100, max: 100},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := oscillate(newSmoother(test.spec), 0, 100, step, steps)
+			settled := out[steps/2:]
+			lo, hi := math.Inf(1), math.Inf(-1)
+			for _, v := range settled {
+				lo, hi = math.Min(lo, v), math.Max(hi, v)
+			}
+			assert.GreaterOrEqual(t, lo, test.min)
+			assert.LessOrEqual(t, hi, test.max)
+		})
+	}
+}
+
+func TestEWMAHalfLife(t *testing.T) {
+	s := newSmoother(SmoothingSpec{Method: SmoothingEWMA, Period: time.Second})
+	start := time.Unix(0, 0)
+	assert.Equal(t, 0.0, s.add(start, 0))
+	// After one half-life, the value moved halfway towards a step input, regardless of how many
+	// updates there were in between.
+	assert.InDelta(t, 50, s.add(start.Add(time.Second), 100), 1e-9)
+
+	frequent := newSmoother(SmoothingSpec{Method: SmoothingEWMA, Period: time.Second})
+	frequent.add(start, 0)
+	var got float64
+	for i := 1; i <= 20; i++ {
+		got = frequent.add(start.Add(time.Duration(i)*50*time.Millisecond), 100)
+	}
+	assert.InDelta(t, 50, got, 1e-9)
+}
+
+func TestWindowForgetsOldValues(t *testing.T) {
+	s := newSmoother(SmoothingSpec{Method: SmoothingWindowMax, Period: time.Second})
+	start := time.Unix(0, 0)
+	assert.Equal(t, 100.0, s.add(start, 100))
+	assert.Equal(t, 100.0, s.add(start.Add(500*time.Millisecond), 1))
+	// The peak left the window.
+	assert.Equal(t, 2.0, s.add(start.Add(1200*time.Millisecond), 2))
+	// A single sample is kept even if the updates are further apart than the window.
+	assert.Equal(t, 3.0, s.add(start.Add(time.Hour), 3))
+}
+
+func TestPodSmoothingApply(t *testing.T) {
+	start := time.Unix(0, 0)
+	m := &Metrics{WaitingQueueSize: 10, KVCacheUsagePercent: 0.5}
+	var none *podSmoothing
+	none.apply(start, m)
+	assert.Equal(t, 10.0, m.SmoothedWaitingQueueSize)
+	assert.Equal(t, 0.5, m.SmoothedKVCacheUsagePercent)
+
+	s := newPodSmoothing(SmoothingConfig{WaitingQueueSize: SmoothingSpec{Method: SmoothingWindowAvg, Period: time.Second}})
+	s.apply(start, &Metrics{WaitingQueueSize: 0, KVCacheUsagePercent: 0.1})
+	m = &Metrics{WaitingQueueSize: 10, KVCacheUsagePercent: 0.5}
+	s.apply(start.Add(100*time.Millisecond), m)
+	assert.Equal(t, 5.0, m.SmoothedWaitingQueueSize)
+	// The KV cache usage is not smoothed.
+	assert.Equal(t, 0.5, m.SmoothedKVCacheUsagePercent)
+}
+
+func TestSmoothingConfigEnabled(t *testing.T) {
+	assert.False(t, SmoothingConfig{}.Enabled())
+	assert.False(t, SmoothingConfig{WaitingQueueSize: SmoothingSpec{Method: SmoothingNone}}.Enabled())
+	assert.True(t, SmoothingConfig{KVCacheUsagePercent: SmoothingSpec{Method: SmoothingWindowMax, Period: time.Second}}.Enabled())
+}
@@ -20,24 +20,32 @@ package metrics
 import (
 	"context"
 	"fmt"
-	"sync"
 	"time"
 
+	xdsorca "github.com/cncf/xds/go/xds/data/orca/v3"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 func NewPodMetricsFactory(pmc PodMetricsClient, refreshMetricsInterval time.Duration) *PodMetricsFactory {
+	return NewPodMetricsFactoryWithOptions(pmc, DefaultScrapeOptions(refreshMetricsInterval))
+}
+
+func NewPodMetricsFactoryWithOptions(pmc PodMetricsClient, opts ScrapeOptions) *PodMetricsFactory {
 	return &PodMetricsFactory{
-		pmc:                    pmc,
-		refreshMetricsInterval: refreshMetricsInterval,
+		pmc:       pmc,
+		scheduler: newScrapeScheduler(opts),
+		smoothing: opts.Smoothing,
 	}
 }
 
+// PodMetricsFactory creates the PodMetrics of the pods, and refreshes their metrics from a shared
+// pool of scrape workers.
 type PodMetricsFactory struct {
-	pmc                    PodMetricsClient
-	refreshMetricsInterval time.Duration
+	pmc       PodMetricsClient
+	scheduler *scrapeScheduler
+	smoothing SmoothingConfig
 }
 
 func (f *PodMetricsFactory) NewPodMetrics(parentCtx context.Context, in *corev1.Pod, ds Datastore) PodMetrics {
@@ -45,23 +53,25 @@ func (f *PodMetricsFactory) NewPodMetrics(parentCtx context.Context, in *corev1.
 	pm := &podMetrics{
 		pmc:       f.pmc,
 		ds:        ds,
-		interval:  f.refreshMetricsInterval,
 		parentCtx: parentCtx,
-		once:      sync.Once{},
 		done:      make(chan struct{}),
+		smoothing: newPodSmoothing(f.smoothing),
 		logger:    log.FromContext(parentCtx).WithValues("pod", pod.NamespacedName),
 	}
 	pm.pod.Store(pod)
 	pm.metrics.Store(newMetrics())
 
-	pm.startRefreshLoop()
+	f.scheduler.add(parentCtx, pm)
 	return pm
 }
 
 type PodMetrics interface {
 	GetPod() *Pod
 	GetMetrics() *Metrics
+	GetScrapeHealth() *ScrapeHealth
 	UpdatePod(*corev1.Pod)
+	// UpdateLoadReport updates the metrics from an ORCA load report received in a response of the pod.
+	UpdateLoadReport(*xdsorca.OrcaLoadReport)
 	StopRefreshLoop()
 	String() string
 }
@@ -98,9 +108,34 @@ type Metrics struct {
 	WaitingQueueSize        int
 	KVCacheUsagePercent     float64
 	KvCacheMaxTokenCapacity int
+	// PrefixCacheHitRate is the fraction (from 0 to 1) of the prompt tokens found in the prefix cache.
+	PrefixCacheHitRate float64
+	// PreemptionRate is the number of requests preempted per second.
+	PreemptionRate float64
+	// TokenThroughput is the number of tokens generated per second.
+	TokenThroughput float64
+	// Custom are the values of the custom metrics of the MetricMapping, keyed by name. A custom
+	// metric is absent until it was read once.
+	Custom map[string]float64
+
+	// SmoothedWaitingQueueSize and SmoothedKVCacheUsagePercent are the values of WaitingQueueSize
+	// and KVCacheUsagePercent smoothed over the recent updates, as configured by the SmoothingConfig.
+	// They are equal to the raw values when no smoothing is configured.
+	SmoothedWaitingQueueSize    float64
+	SmoothedKVCacheUsagePercent float64
 
 	// UpdateTime record the last time when the metrics were updated.
 	UpdateTime time.Time
+
+	// CounterSamples are the values of the counters read at the last scrape, keyed by time series,
+	// to derive the rates of the Rate MetricSpecs.
+	CounterSamples map[string]CounterSample
+}
+
+// CounterSample is the value of a counter at a point in time.
+type CounterSample struct {
+	Value float64
+	Time  time.Time
 }
 
 func newMetrics() *Metrics {
@@ -134,7 +169,62 @@ func (m *Metrics) Clone() *Metrics {
 		WaitingQueueSize:        m.WaitingQueueSize,
 		KVCacheUsagePercent:     m.KVCacheUsagePercent,
 		KvCacheMaxTokenCapacity: m.KvCacheMaxTokenCapacity,
-		UpdateTime:              m.UpdateTime,
+		PrefixCacheHitRate:      m.PrefixCacheHitRate,
+		PreemptionRate:          m.PreemptionRate,
+		TokenThroughput:         m.TokenThroughput,
+
+		SmoothedWaitingQueueSize:    m.SmoothedWaitingQueueSize,
+		SmoothedKVCacheUsagePercent: m.SmoothedKVCacheUsagePercent,
+		UpdateTime:                  m.UpdateTime,
+	}
+	if m.Custom != nil {
+		clone.Custom = make(map[string]float64, len(m.Custom))
+		for k, v := range m.Custom {
+			clone.Custom[k] = v
+		}
+	}
+	if m.CounterSamples != nil {
+		clone.CounterSamples = make(map[string]CounterSample, len(m.CounterSamples))
+		for k, v := range m.CounterSamples {
+			clone.CounterSamples[k] = v
+		}
 	}
 	return clone
 }
+
+// ScrapeHealth describes the outcome of the recent metrics scrapes of a pod.
+type ScrapeHealth struct {
+	// LastSuccessTime is the last time the metrics endpoint of the pod was scraped, including when
+	// some mapped metrics were missing.
+	LastSuccessTime time.Time
+	// ConsecutiveFailures is the number of scrapes that failed since LastSuccessTime.
+	ConsecutiveFailures int
+	// LastError is the error of the last scrape, empty if it succeeded completely.
+	LastError string
+	// MissingMetrics lists the mapped metrics that were missing from the last successful scrape.
+	MissingMetrics []string
+}
+
+// Healthy returns true if the last scrape of the pod succeeded. A nil ScrapeHealth, for a pod that
+// was never scraped, is considered healthy.
+func (h *ScrapeHealth) Healthy() bool {
+	return h == nil || h.ConsecutiveFailures == 0
+}
+
+func (h *ScrapeHealth) String() string {
+	if h == nil {
+		return ""
+	}
+	return fmt.Sprintf("%+v", *h)
+}
+
+func (h *ScrapeHealth) Clone() *ScrapeHealth {
+	if h == nil {
+		return nil
+	}
+	clone := *h
+	if h.MissingMetrics != nil {
+		clone.MissingMetrics = append([]string(nil), h.MissingMetrics...)
+	}
+	return &clone
+}
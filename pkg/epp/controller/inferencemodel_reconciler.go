@@ -21,13 +21,22 @@ import (
 	"fmt"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
 	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/datastore"
 	logutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/logging"
@@ -38,6 +47,9 @@ type InferenceModelReconciler struct {
 	Record             record.EventRecorder
 	Datastore          datastore.Datastore
 	PoolNamespacedName types.NamespacedName
+	// Elected is closed once this replica is elected leader. Every replica keeps its datastore in
+	// sync, but only the leader writes InferenceModel status. A nil channel disables status writes.
+	Elected <-chan struct{}
 }
 
 func (c *InferenceModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -64,13 +76,18 @@ func (c *InferenceModelReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 	// Add or update if the InferenceModel instance has a creation timestamp older than the existing entry of the model.
 	logger = logger.WithValues("poolRef", infModel.Spec.PoolRef).WithValues("modelName", infModel.Spec.ModelName)
+	// The aliases of the replaced instance may now be retained by other models.
+	names := modelNames(infModel)
+	if previous := c.Datastore.ModelGet(infModel.Spec.ModelName); previous != nil {
+		names = append(names, previous.Spec.Aliases...)
+	}
 	if !c.Datastore.ModelSetIfOlder(infModel) {
 		logger.Info("Skipping InferenceModel, existing instance has older creation timestamp")
 	} else {
 		logger.Info("Added/Updated InferenceModel")
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{}, c.updateModelStatuses(ctx, names...)
 }
 
 func (c *InferenceModelReconciler) handleModelDeleted(ctx context.Context, req types.NamespacedName) error {
@@ -92,12 +109,158 @@ func (c *InferenceModelReconciler) handleModelDeleted(ctx context.Context, req t
 	if err != nil {
 		return err
 	}
+	names := modelNames(existing)
 	if updated {
 		logger.Info("Model replaced.", "modelName", existing.Spec.ModelName)
+		names = append(names, c.Datastore.ModelGet(existing.Spec.ModelName).Spec.Aliases...)
+	}
+	return c.updateModelStatuses(ctx, names...)
+}
+
+// modelNames returns the modelName and the aliases of the InferenceModel.
+func modelNames(infModel *v1alpha2.InferenceModel) []string {
+	return append([]string{infModel.Spec.ModelName}, infModel.Spec.Aliases...)
+}
+
+// updateModelStatuses sets the Accepted and AliasesAccepted conditions on every InferenceModel of
+// the pool that uses one of the given names as modelName or alias. The instances held by the
+// datastore are accepted, and the others are rejected since they lost the modelName conflict.
+// Accepted instances whose aliases are retained by other InferenceModels lost the alias conflict.
+func (c *InferenceModelReconciler) updateModelStatuses(ctx context.Context, names ...string) error {
+	if !c.isLeader() {
+		return nil
+	}
+
+	poolFound := true
+	if err := c.Get(ctx, c.PoolNamespacedName, &v1alpha2.InferencePool{}); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("getting InferencePool %s: %w", c.PoolNamespacedName, err)
+		}
+		poolFound = false
+	}
+
+	models, err := c.modelsUsingNames(ctx, names)
+	if err != nil {
+		return err
+	}
+	for _, m := range models {
+		if m.Spec.PoolRef.Name != v1alpha2.ObjectName(c.PoolNamespacedName.Name) || !m.DeletionTimestamp.IsZero() {
+			continue
+		}
+		accepted := c.acceptedCondition(m, c.Datastore.ModelGet(m.Spec.ModelName), poolFound)
+		if err := c.patchConditions(ctx, m, accepted, c.aliasesAcceptedCondition(m, accepted)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// modelsUsingNames lists the InferenceModels of the pool namespace using one of the names as
+// modelName or alias.
+func (c *InferenceModelReconciler) modelsUsingNames(ctx context.Context, names []string) ([]*v1alpha2.InferenceModel, error) {
+	seen := make(map[types.NamespacedName]bool)
+	var res []*v1alpha2.InferenceModel
+	for _, name := range names {
+		for _, key := range []string{datastore.ModelNameIndexKey, datastore.ModelAliasIndexKey} {
+			var models v1alpha2.InferenceModelList
+			if err := c.List(ctx, &models, client.MatchingFields{key: name}, client.InNamespace(c.PoolNamespacedName.Namespace)); err != nil {
+				return nil, fmt.Errorf("listing models that match the %s %s: %w", key, name, err)
+			}
+			for i := range models.Items {
+				m := &models.Items[i]
+				if nn := client.ObjectKeyFromObject(m); !seen[nn] {
+					seen[nn] = true
+					res = append(res, m)
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+// acceptedCondition computes the Accepted condition of the given InferenceModel, where active is
+// the InferenceModel currently serving its modelName.
+func (c *InferenceModelReconciler) acceptedCondition(infModel, active *v1alpha2.InferenceModel, poolFound bool) metav1.Condition {
+	cond := metav1.Condition{
+		Type:               string(v1alpha2.ModelConditionAccepted),
+		ObservedGeneration: infModel.Generation,
+	}
+	switch {
+	case !poolFound:
+		cond.Status = metav1.ConditionUnknown
+		cond.Reason = string(v1alpha2.ModelReasonPending)
+		cond.Message = fmt.Sprintf("InferencePool %q not found", c.PoolNamespacedName.Name)
+	case active == nil:
+		cond.Status = metav1.ConditionUnknown
+		cond.Reason = string(v1alpha2.ModelReasonPending)
+		cond.Message = "Waiting for controller"
+	case active.Name == infModel.Name && active.Namespace == infModel.Namespace:
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = string(v1alpha2.ModelReasonAccepted)
+		cond.Message = fmt.Sprintf("Accepted by InferencePool %q", c.PoolNamespacedName.Name)
+	default:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(v1alpha2.ModelReasonNameInUse)
+		cond.Message = fmt.Sprintf("modelName %q is already in use by InferenceModel %q", infModel.Spec.ModelName, active.Name)
+	}
+	return cond
+}
+
+// aliasesAcceptedCondition computes the AliasesAccepted condition of the given InferenceModel, or
+// nil if it has no aliases or isn't accepted.
+func (c *InferenceModelReconciler) aliasesAcceptedCondition(infModel *v1alpha2.InferenceModel, accepted metav1.Condition) *metav1.Condition {
+	if len(infModel.Spec.Aliases) == 0 || accepted.Status != metav1.ConditionTrue {
+		return nil
+	}
+	cond := &metav1.Condition{
+		Type:               string(v1alpha2.ModelConditionAliasesAccepted),
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: infModel.Generation,
+		Reason:             string(v1alpha2.ModelReasonAccepted),
+		Message:            fmt.Sprintf("Aliases accepted by InferencePool %q", c.PoolNamespacedName.Name),
 	}
+	for _, alias := range infModel.Spec.Aliases {
+		owner := c.Datastore.ModelResolve(alias)
+		if owner != nil && (owner.Name != infModel.Name || owner.Namespace != infModel.Namespace) {
+			cond.Status = metav1.ConditionFalse
+			cond.Reason = string(v1alpha2.ModelReasonAliasInUse)
+			cond.Message = fmt.Sprintf("alias %q is already in use by InferenceModel %q", alias, owner.Name)
+			break
+		}
+	}
+	return cond
+}
+
+// patchConditions patches the InferenceModel status if the conditions changed. A nil aliases
+// condition removes it.
+func (c *InferenceModelReconciler) patchConditions(ctx context.Context, infModel *v1alpha2.InferenceModel, accepted metav1.Condition, aliases *metav1.Condition) error {
+	updated := infModel.DeepCopy()
+	changed := meta.SetStatusCondition(&updated.Status.Conditions, accepted)
+	if aliases != nil {
+		changed = meta.SetStatusCondition(&updated.Status.Conditions, *aliases) || changed
+	} else {
+		changed = meta.RemoveStatusCondition(&updated.Status.Conditions, string(v1alpha2.ModelConditionAliasesAccepted)) || changed
+	}
+	if !changed {
+		return nil
+	}
+	if err := c.Status().Patch(ctx, updated, client.MergeFrom(infModel)); err != nil {
+		return fmt.Errorf("patching status of InferenceModel %s: %w", client.ObjectKeyFromObject(infModel), err)
+	}
+	log.FromContext(ctx).V(logutil.DEFAULT).Info("Updated InferenceModel status",
+		"name", client.ObjectKeyFromObject(infModel), "status", accepted.Status, "reason", accepted.Reason)
 	return nil
 }
 
+func (c *InferenceModelReconciler) isLeader() bool {
+	select {
+	case <-c.Elected:
+		return true
+	default:
+		return false
+	}
+}
+
 func indexInferenceModelsByModelName(obj client.Object) []string {
 	m, ok := obj.(*v1alpha2.InferenceModel)
 	if !ok {
@@ -106,25 +269,81 @@ func indexInferenceModelsByModelName(obj client.Object) []string {
 	return []string{m.Spec.ModelName}
 }
 
+func indexInferenceModelsByAlias(obj client.Object) []string {
+	m, ok := obj.(*v1alpha2.InferenceModel)
+	if !ok {
+		return nil
+	}
+	return m.Spec.Aliases
+}
+
 func (c *InferenceModelReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
-	// Create an index on ModelName for InferenceModel objects.
+	// Create indexes on ModelName and Aliases for InferenceModel objects.
 	indexer := mgr.GetFieldIndexer()
 	if err := indexer.IndexField(ctx, &v1alpha2.InferenceModel{}, datastore.ModelNameIndexKey, indexInferenceModelsByModelName); err != nil {
 		return fmt.Errorf("setting index on ModelName for InferenceModel: %w", err)
 	}
+	if err := indexer.IndexField(ctx, &v1alpha2.InferenceModel{}, datastore.ModelAliasIndexKey, indexInferenceModelsByAlias); err != nil {
+		return fmt.Errorf("setting index on Aliases for InferenceModel: %w", err)
+	}
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&v1alpha2.InferenceModel{}).
-		WithEventFilter(predicate.Funcs{
+		For(&v1alpha2.InferenceModel{}, builder.WithPredicates(predicate.Funcs{
 			CreateFunc: func(e event.CreateEvent) bool { return c.eventPredicate(e.Object.(*v1alpha2.InferenceModel)) },
 			UpdateFunc: func(e event.UpdateEvent) bool {
 				return c.eventPredicate(e.ObjectOld.(*v1alpha2.InferenceModel)) || c.eventPredicate(e.ObjectNew.(*v1alpha2.InferenceModel))
 			},
 			DeleteFunc:  func(e event.DeleteEvent) bool { return c.eventPredicate(e.Object.(*v1alpha2.InferenceModel)) },
 			GenericFunc: func(e event.GenericEvent) bool { return c.eventPredicate(e.Object.(*v1alpha2.InferenceModel)) },
-		}).
+		})).
+		// The pool appearing or disappearing changes the status of all the models referencing it.
+		Watches(&v1alpha2.InferencePool{}, handler.EnqueueRequestsFromMapFunc(c.poolModelRequests),
+			builder.WithPredicates(predicate.Funcs{
+				CreateFunc:  func(e event.CreateEvent) bool { return c.poolPredicate(e.Object) },
+				UpdateFunc:  func(e event.UpdateEvent) bool { return false },
+				DeleteFunc:  func(e event.DeleteEvent) bool { return c.poolPredicate(e.Object) },
+				GenericFunc: func(e event.GenericEvent) bool { return false },
+			})).
+		// Status is only written by the leader, so requeue all the models once elected.
+		WatchesRawSource(source.Func(c.enqueueOnElection)).
+		// The datastore must be kept in sync on all replicas, not just the leader.
+		WithOptions(controller.Options{NeedLeaderElection: ptr.To(false)}).
 		Complete(c)
 }
 
 func (c *InferenceModelReconciler) eventPredicate(infModel *v1alpha2.InferenceModel) bool {
 	return string(infModel.Spec.PoolRef.Name) == c.PoolNamespacedName.Name
 }
+
+func (c *InferenceModelReconciler) poolPredicate(obj client.Object) bool {
+	return obj.GetName() == c.PoolNamespacedName.Name && obj.GetNamespace() == c.PoolNamespacedName.Namespace
+}
+
+// poolModelRequests returns reconcile requests for all the InferenceModels referencing the pool.
+func (c *InferenceModelReconciler) poolModelRequests(ctx context.Context, _ client.Object) []reconcile.Request {
+	var models v1alpha2.InferenceModelList
+	if err := c.List(ctx, &models, client.InNamespace(c.PoolNamespacedName.Namespace)); err != nil {
+		log.FromContext(ctx).V(logutil.DEFAULT).Error(err, "Failed to list InferenceModels")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, m := range models.Items {
+		if c.eventPredicate(&m) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&m)})
+		}
+	}
+	return requests
+}
+
+func (c *InferenceModelReconciler) enqueueOnElection(ctx context.Context, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) error {
+	go func() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.Elected:
+		}
+		for _, req := range c.poolModelRequests(ctx, nil) {
+			queue.Add(req)
+		}
+	}()
+	return nil
+}
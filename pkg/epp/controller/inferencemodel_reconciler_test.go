@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -87,6 +88,27 @@ var (
 			ModelName("fake model2").
 			CreationTimestamp(metav1.Unix(1000, 0)).
 			PoolName(pool.Name).ObjRef()
+
+	infModelAliased = utiltest.MakeInferenceModel("model3").
+			Namespace(pool.Namespace).
+			ModelName("fake model3").
+			Aliases("fake alias", "fake-*").
+			CreationTimestamp(metav1.Unix(1000, 0)).
+			PoolName(pool.Name).ObjRef()
+	// Shares an alias with infModelAliased, newer creation timestamp
+	infModelAliasedNewer = utiltest.MakeInferenceModel("model4").
+				Namespace(pool.Namespace).
+				ModelName("fake model4").
+				Aliases("fake alias").
+				CreationTimestamp(metav1.Unix(1001, 0)).
+				PoolName(pool.Name).ObjRef()
+	// Uses the modelName of infModel1 as alias
+	infModelAliasedModelName = utiltest.MakeInferenceModel("model5").
+					Namespace(pool.Namespace).
+					ModelName("fake model5").
+					Aliases("fake model1").
+					CreationTimestamp(metav1.Unix(900, 0)).
+					PoolName(pool.Name).ObjRef()
 )
 
 func TestInferenceModelReconciler(t *testing.T) {
@@ -190,6 +212,7 @@ func TestInferenceModelReconciler(t *testing.T) {
 				WithScheme(scheme).
 				WithObjects(initObjs...).
 				WithIndex(&v1alpha2.InferenceModel{}, datastore.ModelNameIndexKey, indexInferenceModelsByModelName).
+				WithIndex(&v1alpha2.InferenceModel{}, datastore.ModelAliasIndexKey, indexInferenceModelsByAlias).
 				Build()
 			pmf := backendmetrics.NewPodMetricsFactory(&backendmetrics.FakePodMetricsClient{}, time.Second)
 			ds := datastore.NewDatastore(t.Context(), pmf)
@@ -228,3 +251,197 @@ func TestInferenceModelReconciler(t *testing.T) {
 		})
 	}
 }
+
+type wantCondition struct {
+	status  metav1.ConditionStatus
+	reason  v1alpha2.InferenceModelConditionReason
+	message string
+}
+
+// checkModelCondition checks the condition of the InferenceModel, which must be unset if want is nil.
+func checkModelCondition(t *testing.T, c client.Client, name string, condType v1alpha2.InferenceModelConditionType, want *wantCondition) {
+	t.Helper()
+	got := &v1alpha2.InferenceModel{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: name, Namespace: pool.Namespace}, got); err != nil {
+		t.Fatalf("Failed to get InferenceModel %s: %v", name, err)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, string(condType))
+	if want == nil {
+		if cond != nil {
+			t.Errorf("Unexpected %s condition on %s: %+v", condType, name, cond)
+		}
+		return
+	}
+	if cond == nil {
+		t.Fatalf("%s condition not set on %s", condType, name)
+	}
+	if cond.Status != want.status || cond.Reason != string(want.reason) || cond.Message != want.message {
+		t.Errorf("Unexpected %s condition on %s; want: %+v, got: %+v", condType, name, want, cond)
+	}
+}
+
+func TestInferenceModelReconcilerStatus(t *testing.T) {
+	tests := []struct {
+		name              string
+		notLeader         bool
+		poolMissing       bool
+		modelsInStore     []*v1alpha2.InferenceModel
+		modelsInAPIServer []*v1alpha2.InferenceModel
+		incomingReq       types.NamespacedName
+		wantConditions    map[string]*wantCondition
+		// wantAliasConditions holds the expected AliasesAccepted conditions, nil when unset.
+		wantAliasConditions map[string]*wantCondition
+	}{
+		{
+			name:              "Model accepted",
+			modelsInAPIServer: []*v1alpha2.InferenceModel{infModel1},
+			incomingReq:       types.NamespacedName{Name: infModel1.Name, Namespace: infModel1.Namespace},
+			wantConditions: map[string]*wantCondition{
+				infModel1.Name: {status: metav1.ConditionTrue, reason: v1alpha2.ModelReasonAccepted, message: `Accepted by InferencePool "test-pool1"`},
+			},
+		},
+		{
+			name:              "Newer model loses the modelName conflict",
+			modelsInStore:     []*v1alpha2.InferenceModel{infModel1},
+			modelsInAPIServer: []*v1alpha2.InferenceModel{infModel1, infModel1Newer},
+			incomingReq:       types.NamespacedName{Name: infModel1Newer.Name, Namespace: infModel1Newer.Namespace},
+			wantConditions: map[string]*wantCondition{
+				infModel1.Name:      {status: metav1.ConditionTrue, reason: v1alpha2.ModelReasonAccepted, message: `Accepted by InferencePool "test-pool1"`},
+				infModel1Newer.Name: {status: metav1.ConditionFalse, reason: v1alpha2.ModelReasonNameInUse, message: `modelName "fake model1" is already in use by InferenceModel "model1"`},
+			},
+		},
+		{
+			name:              "Older model wins the modelName conflict",
+			modelsInStore:     []*v1alpha2.InferenceModel{infModel1},
+			modelsInAPIServer: []*v1alpha2.InferenceModel{infModel1, infModel1Older},
+			incomingReq:       types.NamespacedName{Name: infModel1Older.Name, Namespace: infModel1Older.Namespace},
+			wantConditions: map[string]*wantCondition{
+				infModel1.Name:      {status: metav1.ConditionFalse, reason: v1alpha2.ModelReasonNameInUse, message: `modelName "fake model1" is already in use by InferenceModel "model1-older"`},
+				infModel1Older.Name: {status: metav1.ConditionTrue, reason: v1alpha2.ModelReasonAccepted, message: `Accepted by InferencePool "test-pool1"`},
+			},
+		},
+		{
+			name:              "Active model deleted, replacement accepted",
+			modelsInStore:     []*v1alpha2.InferenceModel{infModel1},
+			modelsInAPIServer: []*v1alpha2.InferenceModel{infModel1Newer},
+			incomingReq:       types.NamespacedName{Name: infModel1.Name, Namespace: infModel1.Namespace},
+			wantConditions: map[string]*wantCondition{
+				infModel1Newer.Name: {status: metav1.ConditionTrue, reason: v1alpha2.ModelReasonAccepted, message: `Accepted by InferencePool "test-pool1"`},
+			},
+		},
+		{
+			name:              "Model with aliases accepted",
+			modelsInAPIServer: []*v1alpha2.InferenceModel{infModelAliased},
+			incomingReq:       types.NamespacedName{Name: infModelAliased.Name, Namespace: infModelAliased.Namespace},
+			wantConditions: map[string]*wantCondition{
+				infModelAliased.Name: {status: metav1.ConditionTrue, reason: v1alpha2.ModelReasonAccepted, message: `Accepted by InferencePool "test-pool1"`},
+			},
+			wantAliasConditions: map[string]*wantCondition{
+				infModelAliased.Name: {status: metav1.ConditionTrue, reason: v1alpha2.ModelReasonAccepted, message: `Aliases accepted by InferencePool "test-pool1"`},
+			},
+		},
+		{
+			name:              "Newer model loses the alias conflict",
+			modelsInStore:     []*v1alpha2.InferenceModel{infModelAliased},
+			modelsInAPIServer: []*v1alpha2.InferenceModel{infModelAliased, infModelAliasedNewer},
+			incomingReq:       types.NamespacedName{Name: infModelAliasedNewer.Name, Namespace: infModelAliasedNewer.Namespace},
+			wantConditions: map[string]*wantCondition{
+				infModelAliased.Name:      {status: metav1.ConditionTrue, reason: v1alpha2.ModelReasonAccepted, message: `Accepted by InferencePool "test-pool1"`},
+				infModelAliasedNewer.Name: {status: metav1.ConditionTrue, reason: v1alpha2.ModelReasonAccepted, message: `Accepted by InferencePool "test-pool1"`},
+			},
+			wantAliasConditions: map[string]*wantCondition{
+				infModelAliased.Name:      {status: metav1.ConditionTrue, reason: v1alpha2.ModelReasonAccepted, message: `Aliases accepted by InferencePool "test-pool1"`},
+				infModelAliasedNewer.Name: {status: metav1.ConditionFalse, reason: v1alpha2.ModelReasonAliasInUse, message: `alias "fake alias" is already in use by InferenceModel "model3"`},
+			},
+		},
+		{
+			name:              "Alias in use as a modelName",
+			modelsInStore:     []*v1alpha2.InferenceModel{infModel1},
+			modelsInAPIServer: []*v1alpha2.InferenceModel{infModel1, infModelAliasedModelName},
+			incomingReq:       types.NamespacedName{Name: infModelAliasedModelName.Name, Namespace: infModelAliasedModelName.Namespace},
+			wantConditions: map[string]*wantCondition{
+				infModel1.Name:                {status: metav1.ConditionTrue, reason: v1alpha2.ModelReasonAccepted, message: `Accepted by InferencePool "test-pool1"`},
+				infModelAliasedModelName.Name: {status: metav1.ConditionTrue, reason: v1alpha2.ModelReasonAccepted, message: `Accepted by InferencePool "test-pool1"`},
+			},
+			wantAliasConditions: map[string]*wantCondition{
+				infModel1.Name:                nil,
+				infModelAliasedModelName.Name: {status: metav1.ConditionFalse, reason: v1alpha2.ModelReasonAliasInUse, message: `alias "fake model1" is already in use by InferenceModel "model1"`},
+			},
+		},
+		{
+			name:              "Model retaining the alias deleted, alias released",
+			modelsInStore:     []*v1alpha2.InferenceModel{infModelAliased, infModelAliasedNewer},
+			modelsInAPIServer: []*v1alpha2.InferenceModel{infModelAliasedNewer},
+			incomingReq:       types.NamespacedName{Name: infModelAliased.Name, Namespace: infModelAliased.Namespace},
+			wantAliasConditions: map[string]*wantCondition{
+				infModelAliasedNewer.Name: {status: metav1.ConditionTrue, reason: v1alpha2.ModelReasonAccepted, message: `Aliases accepted by InferencePool "test-pool1"`},
+			},
+		},
+		{
+			name:              "Pool missing, model pending",
+			poolMissing:       true,
+			modelsInAPIServer: []*v1alpha2.InferenceModel{infModel2},
+			incomingReq:       types.NamespacedName{Name: infModel2.Name, Namespace: infModel2.Namespace},
+			wantConditions: map[string]*wantCondition{
+				infModel2.Name: {status: metav1.ConditionUnknown, reason: v1alpha2.ModelReasonPending, message: `InferencePool "test-pool1" not found`},
+			},
+		},
+		{
+			name:              "Not leader, status not written",
+			notLeader:         true,
+			modelsInAPIServer: []*v1alpha2.InferenceModel{infModel1},
+			incomingReq:       types.NamespacedName{Name: infModel1.Name, Namespace: infModel1.Namespace},
+			wantConditions: map[string]*wantCondition{
+				infModel1.Name: nil,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = v1alpha2.Install(scheme)
+			initObjs := []client.Object{}
+			if !test.poolMissing {
+				initObjs = append(initObjs, pool.DeepCopy())
+			}
+			for _, m := range test.modelsInAPIServer {
+				initObjs = append(initObjs, m.DeepCopy())
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(initObjs...).
+				WithStatusSubresource(&v1alpha2.InferenceModel{}).
+				WithIndex(&v1alpha2.InferenceModel{}, datastore.ModelNameIndexKey, indexInferenceModelsByModelName).
+				WithIndex(&v1alpha2.InferenceModel{}, datastore.ModelAliasIndexKey, indexInferenceModelsByAlias).
+				Build()
+			pmf := backendmetrics.NewPodMetricsFactory(&backendmetrics.FakePodMetricsClient{}, time.Second)
+			ds := datastore.NewDatastore(t.Context(), pmf)
+			for _, m := range test.modelsInStore {
+				ds.ModelSetIfOlder(m)
+			}
+			ds.PoolSet(pool)
+			elected := make(chan struct{})
+			if !test.notLeader {
+				close(elected)
+			}
+			reconciler := &InferenceModelReconciler{
+				Client:             fakeClient,
+				Record:             record.NewFakeRecorder(10),
+				Datastore:          ds,
+				PoolNamespacedName: types.NamespacedName{Name: pool.Name, Namespace: pool.Namespace},
+				Elected:            elected,
+			}
+
+			if _, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: test.incomingReq}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			for name, want := range test.wantConditions {
+				checkModelCondition(t, fakeClient, name, v1alpha2.ModelConditionAccepted, want)
+			}
+			for name, want := range test.wantAliasConditions {
+				checkModelCondition(t, fakeClient, name, v1alpha2.ModelConditionAliasesAccepted, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+	backendmetrics "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/backend/metrics"
+	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/datastore"
+	logutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/logging"
+)
+
+// InferencePoolStatusUpdater periodically publishes the state of the pool, as observed by the
+// endpoint picker, to the EndpointPicker section of the InferencePool status. It is expected to
+// run on the leader only, so that replicas don't overwrite each other.
+type InferencePoolStatusUpdater struct {
+	client.Client
+	Datastore          datastore.Datastore
+	PoolNamespacedName types.NamespacedName
+	// Interval bounds the rate at which the status is written.
+	Interval time.Duration
+	// Serving reports whether the ext-proc server of this endpoint picker is serving requests. The
+	// pool isn't Ready while it isn't.
+	Serving func() bool
+}
+
+// Start implements manager.Runnable.
+func (u *InferencePoolStatusUpdater) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithValues("inferencePool", u.PoolNamespacedName)
+	ctx = log.IntoContext(ctx, logger)
+	ticker := time.NewTicker(u.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.V(logutil.DEFAULT).Info("Shutting down InferencePool status updater")
+			return nil
+		case <-ticker.C:
+			if err := u.updateStatus(ctx); err != nil {
+				logger.V(logutil.DEFAULT).Error(err, "Failed to update InferencePool status")
+			}
+		}
+	}
+}
+
+func (u *InferencePoolStatusUpdater) updateStatus(ctx context.Context) error {
+	if !u.Datastore.PoolHasSynced() {
+		// Nothing to report until the pool and its endpoints are known.
+		return nil
+	}
+	pool := &v1alpha2.InferencePool{}
+	if err := u.Get(ctx, u.PoolNamespacedName, pool); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	now := time.Now()
+	updated := pool.DeepCopy()
+	updated.Status.EndpointPicker = endpointPickerStatus(pool, u.Datastore.PodGetAll(), u.Serving(), now)
+	if !statusChanged(pool.Status.EndpointPicker, updated.Status.EndpointPicker, now) {
+		return nil
+	}
+	// A merge patch only touches the EndpointPicker section, leaving the parents set by Gateways intact.
+	if err := u.Status().Patch(ctx, updated, client.MergeFrom(pool)); err != nil {
+		return fmt.Errorf("patching status of InferencePool %s: %w", u.PoolNamespacedName, err)
+	}
+	log.FromContext(ctx).V(logutil.TRACE).Info("Updated InferencePool status", "status", updated.Status.EndpointPicker)
+	return nil
+}
+
+// lastSyncTimeRefreshInterval bounds the age of the LastSyncTime of an unchanged status, so that
+// the status isn't written on every tick while still showing that the endpoint picker is alive.
+const lastSyncTimeRefreshInterval = 5 * time.Minute
+
+// statusChanged reports whether the updated status must be written: it differs from the current
+// one other than by its LastSyncTime, or the current LastSyncTime is due for a refresh.
+func statusChanged(current, updated *v1alpha2.EndpointPickerStatus, now time.Time) bool {
+	if current == nil || current.LastSyncTime == nil || now.Sub(current.LastSyncTime.Time) >= lastSyncTimeRefreshInterval {
+		return true
+	}
+	c, u := *current, *updated
+	c.LastSyncTime, u.LastSyncTime = nil, nil
+	return !equality.Semantic.DeepEqual(c, u)
+}
+
+// endpointPickerStatus computes the EndpointPicker status of the pool from the endpoints in the
+// datastore. The endpoints whose metrics are stale aren't counted as ready. The endpoint picker is
+// Ready when its ext-proc server is serving and it has ready endpoints to route to.
+func endpointPickerStatus(pool *v1alpha2.InferencePool, pods []backendmetrics.PodMetrics, serving bool, now time.Time) *v1alpha2.EndpointPickerStatus {
+	// Start from the existing status to preserve the condition transition times.
+	status := pool.Status.EndpointPicker.DeepCopy()
+	if status == nil {
+		status = &v1alpha2.EndpointPickerStatus{}
+	}
+
+	stale := 0
+	for _, pm := range pods {
+		if backendmetrics.IsStale(pm, now) {
+			stale++
+		}
+	}
+	ready := len(pods) - stale
+	status.ReadyEndpoints = int32(ready)
+	status.StaleMetricsEndpoints = int32(stale)
+	syncTime := metav1.NewTime(now)
+	status.LastSyncTime = &syncTime
+
+	cond := metav1.Condition{
+		Type:               string(v1alpha2.EndpointPickerConditionReady),
+		ObservedGeneration: pool.Generation,
+	}
+	switch {
+	case !serving:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(v1alpha2.EndpointPickerReasonNotServing)
+		cond.Message = "Endpoint picker is not serving requests"
+	case ready > 0:
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = string(v1alpha2.EndpointPickerReasonReady)
+		cond.Message = "Endpoint picker has ready endpoints to route to"
+	default:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(v1alpha2.EndpointPickerReasonNoReadyEndpoints)
+		cond.Message = "No endpoints with fresh metrics in the pool"
+	}
+	meta.SetStatusCondition(&status.Conditions, cond)
+	return status
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+	backendmetrics "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/backend/metrics"
+	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/datastore"
+	utiltest "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/testing"
+)
+
+func TestEndpointPickerStatus(t *testing.T) {
+	now := time.Unix(10000, 0)
+	fresh := &backendmetrics.FakePodMetrics{Metrics: &backendmetrics.Metrics{UpdateTime: now.Add(-time.Second)}}
+	stale := &backendmetrics.FakePodMetrics{Metrics: &backendmetrics.Metrics{UpdateTime: now.Add(-time.Minute)}}
+	readyCondition := metav1.Condition{
+		Type:               string(v1alpha2.EndpointPickerConditionReady),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(v1alpha2.EndpointPickerReasonReady),
+		Message:            "Endpoint picker has ready endpoints to route to",
+		LastTransitionTime: metav1.Unix(500, 0),
+	}
+
+	tests := []struct {
+		name       string
+		existing   *v1alpha2.EndpointPickerStatus
+		pods       []backendmetrics.PodMetrics
+		notServing bool
+		want       *v1alpha2.EndpointPickerStatus
+	}{
+		{
+			name: "No endpoints",
+			want: &v1alpha2.EndpointPickerStatus{
+				Conditions: []metav1.Condition{{
+					Type:    string(v1alpha2.EndpointPickerConditionReady),
+					Status:  metav1.ConditionFalse,
+					Reason:  string(v1alpha2.EndpointPickerReasonNoReadyEndpoints),
+					Message: "No endpoints with fresh metrics in the pool",
+				}},
+			},
+		},
+		{
+			name: "Fresh and stale endpoints",
+			pods: []backendmetrics.PodMetrics{fresh, stale, stale},
+			want: &v1alpha2.EndpointPickerStatus{
+				ReadyEndpoints:        1,
+				StaleMetricsEndpoints: 2,
+				Conditions:            []metav1.Condition{readyCondition},
+			},
+		},
+		{
+			name: "Only stale endpoints",
+			pods: []backendmetrics.PodMetrics{stale, stale},
+			want: &v1alpha2.EndpointPickerStatus{
+				StaleMetricsEndpoints: 2,
+				Conditions: []metav1.Condition{{
+					Type:    string(v1alpha2.EndpointPickerConditionReady),
+					Status:  metav1.ConditionFalse,
+					Reason:  string(v1alpha2.EndpointPickerReasonNoReadyEndpoints),
+					Message: "No endpoints with fresh metrics in the pool",
+				}},
+			},
+		},
+		{
+			name:       "Endpoints without serving ext-proc server",
+			pods:       []backendmetrics.PodMetrics{fresh},
+			notServing: true,
+			want: &v1alpha2.EndpointPickerStatus{
+				ReadyEndpoints: 1,
+				Conditions: []metav1.Condition{{
+					Type:    string(v1alpha2.EndpointPickerConditionReady),
+					Status:  metav1.ConditionFalse,
+					Reason:  string(v1alpha2.EndpointPickerReasonNotServing),
+					Message: "Endpoint picker is not serving requests",
+				}},
+			},
+		},
+		{
+			name:     "Transition time preserved when still ready",
+			existing: &v1alpha2.EndpointPickerStatus{ReadyEndpoints: 2, Conditions: []metav1.Condition{readyCondition}},
+			pods:     []backendmetrics.PodMetrics{fresh},
+			want: &v1alpha2.EndpointPickerStatus{
+				ReadyEndpoints: 1,
+				Conditions:     []metav1.Condition{readyCondition},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pool := utiltest.MakeInferencePool("pool").Namespace("default").ObjRef()
+			pool.Status.EndpointPicker = test.existing
+
+			got := endpointPickerStatus(pool, test.pods, !test.notServing, now)
+
+			syncTime := metav1.NewTime(now)
+			test.want.LastSyncTime = &syncTime
+			opts := cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")
+			if test.existing != nil {
+				opts = nil
+			}
+			if diff := cmp.Diff(test.want, got, opts); diff != "" {
+				t.Errorf("Unexpected status (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestStatusChanged(t *testing.T) {
+	now := time.Unix(10000, 0)
+	syncedAt := func(ago time.Duration) *metav1.Time {
+		t := metav1.NewTime(now.Add(-ago))
+		return &t
+	}
+
+	tests := []struct {
+		name    string
+		current *v1alpha2.EndpointPickerStatus
+		updated *v1alpha2.EndpointPickerStatus
+		want    bool
+	}{
+		{
+			name:    "No current status",
+			updated: &v1alpha2.EndpointPickerStatus{LastSyncTime: syncedAt(0)},
+			want:    true,
+		},
+		{
+			name:    "Only the sync time differs",
+			current: &v1alpha2.EndpointPickerStatus{ReadyEndpoints: 1, LastSyncTime: syncedAt(time.Minute)},
+			updated: &v1alpha2.EndpointPickerStatus{ReadyEndpoints: 1, LastSyncTime: syncedAt(0)},
+			want:    false,
+		},
+		{
+			name:    "Endpoint counts differ",
+			current: &v1alpha2.EndpointPickerStatus{ReadyEndpoints: 1, LastSyncTime: syncedAt(time.Minute)},
+			updated: &v1alpha2.EndpointPickerStatus{ReadyEndpoints: 2, LastSyncTime: syncedAt(0)},
+			want:    true,
+		},
+		{
+			name:    "Sync time due for a refresh",
+			current: &v1alpha2.EndpointPickerStatus{ReadyEndpoints: 1, LastSyncTime: syncedAt(lastSyncTimeRefreshInterval)},
+			updated: &v1alpha2.EndpointPickerStatus{ReadyEndpoints: 1, LastSyncTime: syncedAt(0)},
+			want:    true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := statusChanged(test.current, test.updated, now); got != test.want {
+				t.Errorf("statusChanged() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestInferencePoolStatusUpdater(t *testing.T) {
+	pool := utiltest.MakeInferencePool("pool").Namespace("default").Selector(map[string]string{"app": "vllm"}).ObjRef()
+	gatewayStatus := v1alpha2.PoolStatus{GatewayRef: corev1.ObjectReference{Name: "gateway"}}
+	pool.Status.Parents = []v1alpha2.PoolStatus{gatewayStatus}
+
+	scheme := runtime.NewScheme()
+	_ = v1alpha2.Install(scheme)
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(pool.DeepCopy()).
+		WithStatusSubresource(&v1alpha2.InferencePool{}).
+		Build()
+	pmf := backendmetrics.NewPodMetricsFactory(&backendmetrics.FakePodMetricsClient{}, time.Second)
+	ds := datastore.NewDatastore(t.Context(), pmf)
+	updater := &InferencePoolStatusUpdater{
+		Client:             fakeClient,
+		Datastore:          ds,
+		PoolNamespacedName: types.NamespacedName{Name: pool.Name, Namespace: pool.Namespace},
+		Interval:           time.Second,
+		Serving:            func() bool { return true },
+	}
+
+	// The status is not written until the pool is synced.
+	if err := updater.updateStatus(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got := &v1alpha2.InferencePool{}
+	if err := fakeClient.Get(context.Background(), updater.PoolNamespacedName, got); err != nil {
+		t.Fatalf("Failed to get InferencePool: %v", err)
+	}
+	if got.Status.EndpointPicker != nil {
+		t.Errorf("Unexpected EndpointPicker status before the pool is synced: %+v", got.Status.EndpointPicker)
+	}
+
+	ds.PoolSet(pool)
+	ds.PodUpdateOrAddIfNotExist(utiltest.MakePod("pod1").Namespace(pool.Namespace).ObjRef(), pool)
+	if err := updater.updateStatus(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := fakeClient.Get(context.Background(), updater.PoolNamespacedName, got); err != nil {
+		t.Fatalf("Failed to get InferencePool: %v", err)
+	}
+	// The pod was never scraped, so its metrics are stale.
+	if got.Status.EndpointPicker == nil || got.Status.EndpointPicker.StaleMetricsEndpoints != 1 {
+		t.Errorf("Unexpected EndpointPicker status: %+v", got.Status.EndpointPicker)
+	}
+	if diff := cmp.Diff([]v1alpha2.PoolStatus{gatewayStatus}, got.Status.Parents); diff != "" {
+		t.Errorf("Unexpected parents status (-want +got): %s", diff)
+	}
+
+	// An unchanged status is not written again.
+	resourceVersion := got.ResourceVersion
+	if err := updater.updateStatus(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := fakeClient.Get(context.Background(), updater.PoolNamespacedName, got); err != nil {
+		t.Fatalf("Failed to get InferencePool: %v", err)
+	}
+	if got.ResourceVersion != resourceVersion {
+		t.Errorf("Unexpected status write of an unchanged status, resource version %s -> %s", resourceVersion, got.ResourceVersion)
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	corev1 "k8s.io/api/core/v1"
@@ -34,7 +36,8 @@ import (
 )
 
 const (
-	ModelNameIndexKey = "spec.modelName"
+	ModelNameIndexKey  = "spec.modelName"
+	ModelAliasIndexKey = "spec.aliases"
 )
 
 var (
@@ -52,6 +55,9 @@ type Datastore interface {
 	// InferenceModel operations
 	ModelSetIfOlder(infModel *v1alpha2.InferenceModel) bool
 	ModelGet(modelName string) *v1alpha2.InferenceModel
+	// ModelResolve returns the InferenceModel serving the model name of a request, matching the
+	// modelNames first, then the aliases, then the most specific alias pattern.
+	ModelResolve(modelName string) *v1alpha2.InferenceModel
 	ModelDelete(namespacedName types.NamespacedName) *v1alpha2.InferenceModel
 	ModelResync(ctx context.Context, ctrlClient client.Client, modelName string) (bool, error)
 	ModelGetAll() []*v1alpha2.InferenceModel
@@ -61,6 +67,8 @@ type Datastore interface {
 	PodGetAll() []backendmetrics.PodMetrics
 	// PodList lists pods matching the given predicate.
 	PodList(func(backendmetrics.PodMetrics) bool) []backendmetrics.PodMetrics
+	// PodGet returns the pod with the given name, nil if it isn't in the pool.
+	PodGet(namespacedName types.NamespacedName) backendmetrics.PodMetrics
 	PodUpdateOrAddIfNotExist(pod *corev1.Pod, pool *v1alpha2.InferencePool) bool
 	PodDelete(namespacedName types.NamespacedName)
 	PodResyncAll(ctx context.Context, ctrlClient client.Client, pool *v1alpha2.InferencePool)
@@ -74,6 +82,7 @@ func NewDatastore(parentCtx context.Context, pmf *backendmetrics.PodMetricsFacto
 		parentCtx:       parentCtx,
 		poolAndModelsMu: sync.RWMutex{},
 		models:          make(map[string]*v1alpha2.InferenceModel),
+		aliases:         make(map[string]*v1alpha2.InferenceModel),
 		pods:            &sync.Map{},
 		pmf:             pmf,
 	}
@@ -88,6 +97,11 @@ type datastore struct {
 	pool            *v1alpha2.InferencePool
 	// key: InferenceModel.Spec.ModelName, value: *InferenceModel
 	models map[string]*v1alpha2.InferenceModel
+	// aliases and patterns are derived from models by indexAliases.
+	// key: alias of InferenceModel.Spec.Aliases, value: *InferenceModel retaining it
+	aliases map[string]*v1alpha2.InferenceModel
+	// patterns are the aliases containing '*', most specific first.
+	patterns []string
 	// key: types.NamespacedName, value: backendmetrics.PodMetrics
 	pods *sync.Map
 	pmf  *backendmetrics.PodMetricsFactory
@@ -98,6 +112,7 @@ func (ds *datastore) Clear() {
 	defer ds.poolAndModelsMu.Unlock()
 	ds.pool = nil
 	ds.models = make(map[string]*v1alpha2.InferenceModel)
+	ds.indexAliases()
 	ds.pods.Clear()
 }
 
@@ -147,6 +162,7 @@ func (ds *datastore) ModelSetIfOlder(infModel *v1alpha2.InferenceModel) bool {
 	}
 	// Set the model.
 	ds.models[infModel.Spec.ModelName] = infModel
+	ds.indexAliases()
 	return true
 }
 
@@ -179,6 +195,7 @@ func (ds *datastore) ModelResync(ctx context.Context, c client.Client, modelName
 		return false, nil
 	}
 	ds.models[modelName] = oldest
+	ds.indexAliases()
 	return true, nil
 }
 
@@ -188,12 +205,30 @@ func (ds *datastore) ModelGet(modelName string) *v1alpha2.InferenceModel {
 	return ds.models[modelName]
 }
 
+func (ds *datastore) ModelResolve(modelName string) *v1alpha2.InferenceModel {
+	ds.poolAndModelsMu.RLock()
+	defer ds.poolAndModelsMu.RUnlock()
+	if m, ok := ds.models[modelName]; ok {
+		return m
+	}
+	if m, ok := ds.aliases[modelName]; ok {
+		return m
+	}
+	for _, pattern := range ds.patterns {
+		if matchPattern(pattern, modelName) {
+			return ds.aliases[pattern]
+		}
+	}
+	return nil
+}
+
 func (ds *datastore) ModelDelete(namespacedName types.NamespacedName) *v1alpha2.InferenceModel {
 	ds.poolAndModelsMu.Lock()
 	defer ds.poolAndModelsMu.Unlock()
 	for _, m := range ds.models {
 		if m.Name == namespacedName.Name && m.Namespace == namespacedName.Namespace {
 			delete(ds.models, m.Spec.ModelName)
+			ds.indexAliases()
 			return m
 		}
 	}
@@ -210,6 +245,71 @@ func (ds *datastore) ModelGetAll() []*v1alpha2.InferenceModel {
 	return res
 }
 
+// indexAliases rebuilds the aliases and patterns of the models. An alias used by several models is
+// retained by the oldest one. The caller must hold poolAndModelsMu for writing.
+func (ds *datastore) indexAliases() {
+	ds.aliases = make(map[string]*v1alpha2.InferenceModel)
+	ds.patterns = nil
+	for _, m := range ds.models {
+		for _, alias := range m.Spec.Aliases {
+			existing, exists := ds.aliases[alias]
+			if exists && olderModel(existing, m) {
+				continue
+			}
+			if !exists && strings.Contains(alias, "*") {
+				ds.patterns = append(ds.patterns, alias)
+			}
+			ds.aliases[alias] = m
+		}
+	}
+	sort.Slice(ds.patterns, func(i, j int) bool {
+		pi, pj := ds.patterns[i], ds.patterns[j]
+		if li, lj := patternLiterals(pi), patternLiterals(pj); li != lj {
+			return li > lj
+		}
+		return pi < pj
+	})
+}
+
+// olderModel returns whether a was created before b, breaking ties by namespace and name.
+func olderModel(a, b *v1alpha2.InferenceModel) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+// patternLiterals returns the number of characters of the pattern other than '*', which measures
+// how specific the pattern is.
+func patternLiterals(pattern string) int {
+	return len(pattern) - strings.Count(pattern, "*")
+}
+
+// matchPattern returns whether the name matches the pattern, where '*' matches any sequence of
+// characters.
+func matchPattern(pattern, name string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == name
+	}
+	first, last := parts[0], parts[len(parts)-1]
+	if len(name) < len(first)+len(last) || !strings.HasPrefix(name, first) || !strings.HasSuffix(name, last) {
+		return false
+	}
+	name = name[len(first) : len(name)-len(last)]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(name, part)
+		if i < 0 {
+			return false
+		}
+		name = name[i+len(part):]
+	}
+	return true
+}
+
 // /// Pods/endpoints APIs ///
 
 func (ds *datastore) PodGetAll() []backendmetrics.PodMetrics {
@@ -229,6 +329,13 @@ func (ds *datastore) PodList(predicate func(backendmetrics.PodMetrics) bool) []b
 	return res
 }
 
+func (ds *datastore) PodGet(namespacedName types.NamespacedName) backendmetrics.PodMetrics {
+	if v, ok := ds.pods.Load(namespacedName); ok {
+		return v.(backendmetrics.PodMetrics)
+	}
+	return nil
+}
+
 func (ds *datastore) PodUpdateOrAddIfNotExist(pod *corev1.Pod, pool *v1alpha2.InferencePool) bool {
 	namespacedName := types.NamespacedName{
 		Name:      pod.Name,
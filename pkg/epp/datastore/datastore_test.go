@@ -262,6 +262,81 @@ var (
 	}
 )
 
+func TestModelResolve(t *testing.T) {
+	llama := testutil.MakeInferenceModel("llama").
+		CreationTimestamp(metav1.Unix(1000, 0)).
+		ModelName("llama-3").
+		Aliases("meta-llama/Llama-3", "llama-3-*", "*").ObjRef()
+	llamaInstruct := testutil.MakeInferenceModel("llama-instruct").
+		CreationTimestamp(metav1.Unix(1001, 0)).
+		ModelName("llama-3-instruct").
+		Aliases("llama-3-*-instruct", "meta-llama/Llama-3", "llama-3").ObjRef()
+	sql := testutil.MakeInferenceModel("sql").
+		CreationTimestamp(metav1.Unix(999, 0)).
+		ModelName("sql-lora").
+		Aliases("llama-3-*").ObjRef()
+
+	pmf := backendmetrics.NewPodMetricsFactory(&backendmetrics.FakePodMetricsClient{}, time.Second)
+	ds := NewDatastore(t.Context(), pmf)
+	ds.ModelSetIfOlder(llama)
+	ds.ModelSetIfOlder(llamaInstruct)
+	ds.ModelSetIfOlder(sql)
+
+	tests := []struct {
+		modelName string
+		want      *v1alpha2.InferenceModel
+	}{
+		// Exact names take precedence over aliases.
+		{modelName: "llama-3", want: llama},
+		{modelName: "llama-3-instruct", want: llamaInstruct},
+		// The oldest InferenceModel retains the alias.
+		{modelName: "meta-llama/Llama-3", want: llama},
+		{modelName: "llama-3-8b", want: sql},
+		// The most specific pattern wins.
+		{modelName: "llama-3-8b-instruct", want: llamaInstruct},
+		{modelName: "mistral", want: llama},
+	}
+	for _, test := range tests {
+		t.Run(test.modelName, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, ds.ModelResolve(test.modelName)); diff != "" {
+				t.Errorf("Unexpected model (-want +got): %s", diff)
+			}
+		})
+	}
+
+	// The aliases of deleted models are released.
+	ds.ModelDelete(types.NamespacedName{Name: sql.Name, Namespace: sql.Namespace})
+	if diff := cmp.Diff(llama, ds.ModelResolve("llama-3-8b")); diff != "" {
+		t.Errorf("Unexpected model after delete (-want +got): %s", diff)
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "llama", name: "llama", want: true},
+		{pattern: "llama", name: "llama-3", want: false},
+		{pattern: "*", name: "", want: true},
+		{pattern: "llama-*", name: "llama-", want: true},
+		{pattern: "llama-*", name: "llama-3/8b", want: true},
+		{pattern: "llama-*", name: "mistral", want: false},
+		{pattern: "*-instruct", name: "llama-3-instruct", want: true},
+		{pattern: "llama-*-instruct", name: "llama-3-8b-instruct", want: true},
+		{pattern: "llama-*-instruct", name: "llama-instruct", want: false},
+		{pattern: "a*b*c", name: "abbc", want: true},
+		{pattern: "a*b*c", name: "acb", want: false},
+		{pattern: "a**c", name: "ac", want: true},
+	}
+	for _, test := range tests {
+		if got := matchPattern(test.pattern, test.name); got != test.want {
+			t.Errorf("matchPattern(%q, %q) = %v, want %v", test.pattern, test.name, got, test.want)
+		}
+	}
+}
+
 func TestMetrics(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -332,7 +407,7 @@ func TestMetrics(t *testing.T) {
 				for _, one := range got {
 					metrics = append(metrics, one.GetMetrics())
 				}
-				diff := cmp.Diff(test.want, metrics, cmpopts.IgnoreFields(backendmetrics.Metrics{}, "UpdateTime"), cmpopts.SortSlices(func(a, b *backendmetrics.Metrics) bool {
+				diff := cmp.Diff(test.want, metrics, cmpopts.IgnoreFields(backendmetrics.Metrics{}, "UpdateTime", "SmoothedWaitingQueueSize", "SmoothedKVCacheUsagePercent"), cmpopts.SortSlices(func(a, b *backendmetrics.Metrics) bool {
 					return a.String() < b.String()
 				}))
 				assert.Equal(t, "", diff, "Unexpected diff (+got/-want)")
@@ -340,3 +415,17 @@ func TestMetrics(t *testing.T) {
 		})
 	}
 }
+
+func TestPodGet(t *testing.T) {
+	pmf := backendmetrics.NewPodMetricsFactory(&backendmetrics.FakePodMetricsClient{}, time.Second)
+	ds := NewDatastore(t.Context(), pmf)
+	ds.PoolSet(inferencePool)
+	ds.PodUpdateOrAddIfNotExist(pod1, inferencePool)
+
+	if got := ds.PodGet(pod1NamespacedName); got == nil || got.GetPod().NamespacedName != pod1NamespacedName {
+		t.Errorf("PodGet(%v) = %v, want pod1", pod1NamespacedName, got)
+	}
+	if got := ds.PodGet(pod2NamespacedName); got != nil {
+		t.Errorf("PodGet(%v) = %v, want nil", pod2NamespacedName, got)
+	}
+}
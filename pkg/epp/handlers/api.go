@@ -0,0 +1,352 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	schedulingtypes "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/scheduling/types"
+	errutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/error"
+)
+
+// OpenAI API paths understood by the EPP.
+const (
+	CompletionsPath     = "/v1/completions"
+	ChatCompletionsPath = "/v1/chat/completions"
+	EmbeddingsPath      = "/v1/embeddings"
+	ResponsesPath       = "/v1/responses"
+)
+
+// apiRequest is the typed request model of an OpenAI API, holding the scheduling-relevant fields
+// of its request body.
+type apiRequest interface {
+	// decode fills the request from the body, returning BadRequest errors describing the offending
+	// field.
+	decode(body map[string]interface{}) error
+	// apply sets the fields of the request in req.
+	apply(req *schedulingtypes.LLMRequest)
+}
+
+var apiRequests = map[string]func() apiRequest{
+	CompletionsPath:     func() apiRequest { return &completionsRequest{} },
+	ChatCompletionsPath: func() apiRequest { return &chatCompletionsRequest{} },
+	EmbeddingsPath:      func() apiRequest { return &embeddingsRequest{} },
+	ResponsesPath:       func() apiRequest { return &responsesRequest{} },
+}
+
+// requestPath returns the path of a ":path" header value, without its query and trailing slash.
+func requestPath(value string) string {
+	path, _, _ := strings.Cut(value, "?")
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+// parseRequestBody builds an LLMRequest from the body of a request to the given API path. The
+// requests to other APIs than the ones of apiRequests, such as /v1/score or /tokenize, or with an
+// empty path, e.g. when Envoy doesn't forward the ":path" header, only require the model. The
+// returned errors are BadRequest errors describing the offending field.
+//
+// The typed request models are decoded from the body map rather than from the raw body, which is
+// already decoded once for the mutations and rewrites, and whose type errors wouldn't locate the
+// offending array element.
+func parseRequestBody(path string, body map[string]interface{}) (*schedulingtypes.LLMRequest, error) {
+	if body == nil {
+		return nil, badRequest("request body must be a JSON object")
+	}
+	model, ok := body["model"].(string)
+	if !ok {
+		return nil, errutil.Error{Code: errutil.BadRequest, Msg: "model not found in request"}
+	}
+	req := &schedulingtypes.LLMRequest{Path: path, Model: model, N: 1}
+	newRequest, ok := apiRequests[path]
+	if !ok {
+		return req, nil
+	}
+	apiReq := newRequest()
+	if err := apiReq.decode(body); err != nil {
+		return nil, err
+	}
+	apiReq.apply(req)
+	return req, nil
+}
+
+// completionsRequest is the request of the completions API.
+type completionsRequest struct {
+	Prompt string
+	generationOptions
+}
+
+func (r *completionsRequest) decode(body map[string]interface{}) error {
+	prompt, ok := body["prompt"]
+	if !ok || prompt == nil {
+		return badRequest("prompt is required")
+	}
+	text, err := promptText(prompt)
+	if err != nil {
+		return badRequest("prompt must be a string, an array of strings or an array of tokens")
+	}
+	r.Prompt = text
+	return r.generationOptions.decode(body, maxTokensField(CompletionsPath, body))
+}
+
+func (r *completionsRequest) apply(req *schedulingtypes.LLMRequest) {
+	req.Prompt = r.Prompt
+	r.generationOptions.apply(req)
+}
+
+// chatCompletionsRequest is the request of the chat completions API.
+type chatCompletionsRequest struct {
+	Messages []chatMessage
+	generationOptions
+}
+
+// chatMessage is a message of a chat completions request, with the text of its content.
+type chatMessage struct {
+	Role    string
+	Content string
+}
+
+func (r *chatCompletionsRequest) decode(body map[string]interface{}) error {
+	messages, ok := body["messages"].([]interface{})
+	if !ok || len(messages) == 0 {
+		return badRequest("messages must be a non-empty array")
+	}
+	r.Messages = make([]chatMessage, 0, len(messages))
+	for i, m := range messages {
+		message, ok := m.(map[string]interface{})
+		if !ok {
+			return badRequest("messages[%d] must be an object", i)
+		}
+		role, ok := message["role"].(string)
+		if !ok {
+			return badRequest("messages[%d].role must be a string", i)
+		}
+		text, err := contentText(message["content"], fmt.Sprintf("messages[%d].content", i))
+		if err != nil {
+			return err
+		}
+		r.Messages = append(r.Messages, chatMessage{Role: role, Content: text})
+	}
+	return r.generationOptions.decode(body, maxTokensField(ChatCompletionsPath, body))
+}
+
+func (r *chatCompletionsRequest) apply(req *schedulingtypes.LLMRequest) {
+	texts := make([]string, 0, len(r.Messages))
+	for _, m := range r.Messages {
+		texts = append(texts, m.Content)
+	}
+	req.Prompt = strings.Join(texts, "\n")
+	r.generationOptions.apply(req)
+}
+
+// embeddingsRequest is the request of the embeddings API.
+type embeddingsRequest struct {
+	Input string
+}
+
+func (r *embeddingsRequest) decode(body map[string]interface{}) error {
+	input, ok := body["input"]
+	if !ok || input == nil {
+		return badRequest("input is required")
+	}
+	text, err := promptText(input)
+	if err != nil {
+		return badRequest("input must be a string, an array of strings or an array of tokens")
+	}
+	r.Input = text
+	return nil
+}
+
+func (r *embeddingsRequest) apply(req *schedulingtypes.LLMRequest) {
+	req.Prompt = r.Input
+}
+
+// responsesRequest is the request of the responses API.
+type responsesRequest struct {
+	Input string
+	generationOptions
+}
+
+func (r *responsesRequest) decode(body map[string]interface{}) error {
+	switch input := body["input"].(type) {
+	case string:
+		r.Input = input
+	case []interface{}:
+		texts := make([]string, 0, len(input))
+		for i, item := range input {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				return badRequest("input[%d] must be an object", i)
+			}
+			text, err := contentText(m["content"], fmt.Sprintf("input[%d].content", i))
+			if err != nil {
+				return err
+			}
+			texts = append(texts, text)
+		}
+		r.Input = strings.Join(texts, "\n")
+	case nil:
+		return badRequest("input is required")
+	default:
+		return badRequest("input must be a string or an array of input items")
+	}
+	return r.generationOptions.decode(body, maxTokensField(ResponsesPath, body))
+}
+
+func (r *responsesRequest) apply(req *schedulingtypes.LLMRequest) {
+	req.Prompt = r.Input
+	r.generationOptions.apply(req)
+}
+
+// generationOptions are the options shared by the generation APIs. MaxTokens is 0 if the request
+// doesn't set it.
+type generationOptions struct {
+	Stream    bool
+	MaxTokens int
+	N         int
+}
+
+func (o *generationOptions) decode(body map[string]interface{}, maxTokensField string) error {
+	var err error
+	if o.Stream, err = optionalBool(body, "stream"); err != nil {
+		return err
+	}
+	if o.MaxTokens, err = optionalPositiveInt(body, maxTokensField, 0); err != nil {
+		return err
+	}
+	if o.N, err = optionalPositiveInt(body, "n", 1); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o *generationOptions) apply(req *schedulingtypes.LLMRequest) {
+	req.Stream = o.Stream
+	req.MaxTokens = o.MaxTokens
+	req.N = o.N
+}
+
+// maxTokensField returns the parameter of the request body bounding the number of generated tokens,
+// or an empty string if the API has none. Chat completions requests use "max_completion_tokens",
+// or the deprecated "max_tokens" when they don't set it.
+func maxTokensField(path string, body map[string]interface{}) string {
+	switch path {
+	case CompletionsPath:
+		return "max_tokens"
+	case ChatCompletionsPath:
+		if _, ok := body["max_completion_tokens"]; ok {
+			return "max_completion_tokens"
+		}
+		return "max_tokens"
+	case ResponsesPath:
+		return "max_output_tokens"
+	default:
+		return ""
+	}
+}
+
+// promptText returns the text of a prompt or input that is a string, an array of strings or an
+// array of token IDs (possibly nested). Token IDs contribute no text.
+func promptText(v interface{}) (string, error) {
+	switch prompt := v.(type) {
+	case string:
+		return prompt, nil
+	case []interface{}:
+		texts := make([]string, 0, len(prompt))
+		for _, p := range prompt {
+			switch p := p.(type) {
+			case string:
+				texts = append(texts, p)
+			case float64:
+			case []interface{}:
+				for _, token := range p {
+					if _, ok := token.(float64); !ok {
+						return "", fmt.Errorf("invalid token %v", token)
+					}
+				}
+			default:
+				return "", fmt.Errorf("invalid prompt element %v", p)
+			}
+		}
+		return strings.Join(texts, "\n"), nil
+	default:
+		return "", fmt.Errorf("invalid prompt %v", v)
+	}
+}
+
+// contentText returns the text of the message content in the given field, which is a string, null
+// or an array of content parts.
+func contentText(v interface{}, field string) (string, error) {
+	switch content := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return content, nil
+	case []interface{}:
+		texts := make([]string, 0, len(content))
+		for i, p := range content {
+			part, ok := p.(map[string]interface{})
+			if !ok {
+				return "", badRequest("%s[%d] must be an object", field, i)
+			}
+			if text, ok := part["text"].(string); ok {
+				texts = append(texts, text)
+			}
+		}
+		return strings.Join(texts, "\n"), nil
+	default:
+		return "", badRequest("%s must be a string or an array of content parts", field)
+	}
+}
+
+func optionalBool(body map[string]interface{}, field string) (bool, error) {
+	v, ok := body[field]
+	if !ok || v == nil {
+		return false, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, badRequest("%s must be a boolean", field)
+	}
+	return b, nil
+}
+
+func optionalPositiveInt(body map[string]interface{}, field string, defaultValue int) (int, error) {
+	v, ok := body[field]
+	if !ok || v == nil {
+		return defaultValue, nil
+	}
+	f, ok := v.(float64)
+	if !ok || f != math.Trunc(f) {
+		return 0, badRequest("%s must be an integer", field)
+	}
+	if f < 1 {
+		return 0, badRequest("%s must be at least 1, got %v", field, f)
+	}
+	if f > math.MaxInt32 {
+		return 0, badRequest("%s must be at most %d, got %v", field, math.MaxInt32, f)
+	}
+	return int(f), nil
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return errutil.Error{Code: errutil.BadRequest, Msg: fmt.Sprintf(format, args...)}
+}
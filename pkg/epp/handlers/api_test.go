@@ -0,0 +1,249 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	schedulingtypes "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/scheduling/types"
+	errutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/error"
+)
+
+func TestRequestPath(t *testing.T) {
+	tests := map[string]string{
+		"/v1/completions":               "/v1/completions",
+		"/v1/chat/completions?debug=1":  "/v1/chat/completions",
+		"/v1/embeddings/":               "/v1/embeddings",
+		"/":                             "/",
+		"":                              "",
+		"/v1/responses?a=b/":            "/v1/responses",
+		"/v1/chat/completions/?x=y&z=1": "/v1/chat/completions",
+	}
+	for value, want := range tests {
+		if got := requestPath(value); got != want {
+			t.Errorf("requestPath(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestParseRequestBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		want    *schedulingtypes.LLMRequest
+		wantErr string
+	}{
+		{
+			name: "empty path only requires the model",
+			body: `{"model": "m", "whatever": [1, 2]}`,
+			want: &schedulingtypes.LLMRequest{Model: "m", N: 1},
+		},
+		{
+			name:    "missing model",
+			path:    CompletionsPath,
+			body:    `{"prompt": "hi"}`,
+			wantErr: "model not found in request",
+		},
+		{
+			name:    "model not a string",
+			body:    `{"model": 1}`,
+			wantErr: "model not found in request",
+		},
+		{
+			name:    "null body",
+			body:    `null`,
+			wantErr: "request body must be a JSON object",
+		},
+		{
+			name: "other API path only requires the model",
+			path: "/v1/score",
+			body: `{"model": "m", "text_1": "a", "text_2": ["b", "c"]}`,
+			want: &schedulingtypes.LLMRequest{Path: "/v1/score", Model: "m", N: 1},
+		},
+		{
+			name: "completions",
+			path: CompletionsPath,
+			body: `{"model": "m", "prompt": "hi", "max_tokens": 10, "stream": true, "n": 2, "temperature": 0}`,
+			want: &schedulingtypes.LLMRequest{Path: CompletionsPath, Model: "m", Prompt: "hi", MaxTokens: 10, Stream: true, N: 2},
+		},
+		{
+			name: "completions with prompt array and tokens",
+			path: CompletionsPath,
+			body: `{"model": "m", "prompt": ["a", "b", [1, 2]]}`,
+			want: &schedulingtypes.LLMRequest{Path: CompletionsPath, Model: "m", Prompt: "a\nb", N: 1},
+		},
+		{
+			name:    "completions without prompt",
+			path:    CompletionsPath,
+			body:    `{"model": "m"}`,
+			wantErr: "prompt is required",
+		},
+		{
+			name:    "completions with invalid prompt",
+			path:    CompletionsPath,
+			body:    `{"model": "m", "prompt": {"text": "hi"}}`,
+			wantErr: "prompt must be a string, an array of strings or an array of tokens",
+		},
+		{
+			name:    "completions with fractional max_tokens",
+			path:    CompletionsPath,
+			body:    `{"model": "m", "prompt": "hi", "max_tokens": 1.5}`,
+			wantErr: "max_tokens must be an integer",
+		},
+		{
+			name:    "completions with zero max_tokens",
+			path:    CompletionsPath,
+			body:    `{"model": "m", "prompt": "hi", "max_tokens": 0}`,
+			wantErr: "max_tokens must be at least 1, got 0",
+		},
+		{
+			name:    "completions with max_tokens out of range",
+			path:    CompletionsPath,
+			body:    `{"model": "m", "prompt": "hi", "max_tokens": 3000000000}`,
+			wantErr: "max_tokens must be at most 2147483647, got 3e+09",
+		},
+		{
+			name:    "completions with string stream",
+			path:    CompletionsPath,
+			body:    `{"model": "m", "prompt": "hi", "stream": "true"}`,
+			wantErr: "stream must be a boolean",
+		},
+		{
+			name:    "completions with negative n",
+			path:    CompletionsPath,
+			body:    `{"model": "m", "prompt": "hi", "n": -1}`,
+			wantErr: "n must be at least 1, got -1",
+		},
+		{
+			name: "chat completions",
+			path: ChatCompletionsPath,
+			body: `{"model": "m", "messages": [
+				{"role": "system", "content": "be brief"},
+				{"role": "user", "content": [{"type": "text", "text": "hi"}, {"type": "image_url", "image_url": {"url": "x"}}]},
+				{"role": "assistant", "content": null, "tool_calls": []}
+			], "max_completion_tokens": 5, "max_tokens": 100}`,
+			want: &schedulingtypes.LLMRequest{Path: ChatCompletionsPath, Model: "m", Prompt: "be brief\nhi\n", MaxTokens: 5, N: 1},
+		},
+		{
+			name: "chat completions with max_tokens",
+			path: ChatCompletionsPath,
+			body: `{"model": "m", "messages": [{"role": "user", "content": "hi"}], "max_tokens": 7}`,
+			want: &schedulingtypes.LLMRequest{Path: ChatCompletionsPath, Model: "m", Prompt: "hi", MaxTokens: 7, N: 1},
+		},
+		{
+			name:    "chat completions without messages",
+			path:    ChatCompletionsPath,
+			body:    `{"model": "m", "messages": []}`,
+			wantErr: "messages must be a non-empty array",
+		},
+		{
+			name:    "chat completions with message without role",
+			path:    ChatCompletionsPath,
+			body:    `{"model": "m", "messages": [{"role": "user", "content": "hi"}, {"content": "hi"}]}`,
+			wantErr: "messages[1].role must be a string",
+		},
+		{
+			name:    "chat completions with invalid content",
+			path:    ChatCompletionsPath,
+			body:    `{"model": "m", "messages": [{"role": "user", "content": 1}]}`,
+			wantErr: "messages[0].content must be a string or an array of content parts",
+		},
+		{
+			name:    "chat completions with invalid content part",
+			path:    ChatCompletionsPath,
+			body:    `{"model": "m", "messages": [{"role": "user", "content": ["hi"]}]}`,
+			wantErr: "messages[0].content[0] must be an object",
+		},
+		{
+			name: "embeddings",
+			path: EmbeddingsPath,
+			body: `{"model": "m", "input": ["a", "b"]}`,
+			want: &schedulingtypes.LLMRequest{Path: EmbeddingsPath, Model: "m", Prompt: "a\nb", N: 1},
+		},
+		{
+			name:    "embeddings without input",
+			path:    EmbeddingsPath,
+			body:    `{"model": "m"}`,
+			wantErr: "input is required",
+		},
+		{
+			name: "responses with string input",
+			path: ResponsesPath,
+			body: `{"model": "m", "input": "hi", "max_output_tokens": 3, "stream": true}`,
+			want: &schedulingtypes.LLMRequest{Path: ResponsesPath, Model: "m", Prompt: "hi", MaxTokens: 3, Stream: true, N: 1},
+		},
+		{
+			name: "responses with input items",
+			path: ResponsesPath,
+			body: `{"model": "m", "input": [{"role": "user", "content": [{"type": "input_text", "text": "hi"}]}, {"role": "user", "content": "there"}]}`,
+			want: &schedulingtypes.LLMRequest{Path: ResponsesPath, Model: "m", Prompt: "hi\nthere", N: 1},
+		},
+		{
+			name:    "responses with invalid input",
+			path:    ResponsesPath,
+			body:    `{"model": "m", "input": 1}`,
+			wantErr: "input must be a string or an array of input items",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var body map[string]interface{}
+			if err := json.Unmarshal([]byte(test.body), &body); err != nil {
+				t.Fatal(err)
+			}
+			got, err := parseRequestBody(test.path, body)
+			if test.wantErr != "" {
+				want := errutil.Error{Code: errutil.BadRequest, Msg: test.wantErr}
+				if diff := cmp.Diff(want, err); diff != "" {
+					t.Errorf("parseRequestBody returned unexpected error, diff(-want, +got): %v", diff)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRequestBody returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("parseRequestBody returned unexpected request, diff(-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestChatCompletionsRequestDecode(t *testing.T) {
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"model": "m", "messages": [
+		{"role": "system", "content": "be brief"},
+		{"role": "user", "content": [{"type": "text", "text": "hi"}]}
+	], "max_completion_tokens": 5, "stream": true}`), &body); err != nil {
+		t.Fatal(err)
+	}
+	got := &chatCompletionsRequest{}
+	if err := got.decode(body); err != nil {
+		t.Fatalf("decode returned unexpected error: %v", err)
+	}
+	want := &chatCompletionsRequest{
+		Messages:          []chatMessage{{Role: "system", Content: "be brief"}, {Role: "user", Content: "hi"}},
+		generationOptions: generationOptions{Stream: true, MaxTokens: 5, N: 1},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(chatCompletionsRequest{})); diff != "" {
+		t.Errorf("decode returned unexpected request, diff(-want, +got): %v", diff)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/metrics"
+	schedulingtypes "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/scheduling/types"
+	errutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/error"
+)
+
+// The request limits, as reported by the request limit exceeded metric.
+const (
+	limitBodySize     = "body_size"
+	limitMessages     = "messages"
+	limitMaxTokens    = "max_tokens"
+	limitN            = "n"
+	limitPromptLength = "prompt_length"
+)
+
+// checkBodySize rejects request bodies larger than the MaxRequestBodyBytes limit, or than the limit
+// of the InferenceModel when it was resolved from the model name header. It is checked as the body
+// chunks arrive, so that oversized bodies are rejected before being buffered.
+func (s *StreamingServer) checkBodySize(reqCtx *RequestContext, size int) error {
+	if err := bodySizeError(s.config.MaxRequestBodyBytes, size); err != nil {
+		metrics.RecordRequestLimitExceeded("", limitBodySize)
+		return err
+	}
+	if model := reqCtx.inferenceModel; model != nil && model.Spec.Limits != nil && model.Spec.Limits.MaxBodyBytes != nil {
+		if err := bodySizeError(*model.Spec.Limits.MaxBodyBytes, size); err != nil {
+			metrics.RecordRequestLimitExceeded(model.Spec.ModelName, limitBodySize)
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRequestLimits checks a parsed request against the limits of its InferenceModel, recording
+// the exceeded limit.
+func checkRequestLimits(model *v1alpha2.InferenceModel, llmReq *schedulingtypes.LLMRequest, requestBodyMap map[string]interface{}, bodySize int) error {
+	if model == nil || model.Spec.Limits == nil {
+		return nil
+	}
+	limit, err := exceededLimit(model.Spec.Limits, llmReq, requestBodyMap, bodySize)
+	if err != nil {
+		metrics.RecordRequestLimitExceeded(model.Spec.ModelName, limit)
+	}
+	return err
+}
+
+// exceededLimit returns the first limit exceeded by the request, along with the error reporting it
+// to the client.
+func exceededLimit(limits *v1alpha2.RequestLimits, llmReq *schedulingtypes.LLMRequest, requestBodyMap map[string]interface{}, bodySize int) (string, error) {
+	if limits.MaxBodyBytes != nil {
+		if err := bodySizeError(*limits.MaxBodyBytes, bodySize); err != nil {
+			return limitBodySize, err
+		}
+	}
+	if limits.MaxMessages != nil {
+		if messages, _ := requestBodyMap["messages"].([]interface{}); len(messages) > int(*limits.MaxMessages) {
+			return limitMessages, badRequest("request has %d messages, exceeding the limit of %d", len(messages), *limits.MaxMessages)
+		}
+	}
+	if limits.MaxTokens != nil && llmReq.MaxTokens > int(*limits.MaxTokens) {
+		return limitMaxTokens, badRequest("request asks for %d tokens, exceeding the limit of %d", llmReq.MaxTokens, *limits.MaxTokens)
+	}
+	if limits.MaxN != nil && llmReq.N > int(*limits.MaxN) {
+		return limitN, badRequest("request asks for %d choices, exceeding the limit of %d", llmReq.N, *limits.MaxN)
+	}
+	if limits.MaxPromptLength != nil {
+		if length := utf8.RuneCountInString(llmReq.Prompt); length > int(*limits.MaxPromptLength) {
+			return limitPromptLength, badRequest("prompt has %d characters, exceeding the limit of %d", length, *limits.MaxPromptLength)
+		}
+	}
+	return "", nil
+}
+
+// limitsNeedBody reports whether the limits are checked against the parsed request body, beyond
+// its size.
+func limitsNeedBody(limits *v1alpha2.RequestLimits) bool {
+	return limits != nil && (limits.MaxMessages != nil || limits.MaxTokens != nil || limits.MaxN != nil || limits.MaxPromptLength != nil)
+}
+
+// bodySizeError returns the error rejecting a request body of the given size, if it exceeds max.
+// A max of 0 is unlimited.
+func bodySizeError(max int64, size int) error {
+	if max > 0 && int64(size) > max {
+		return errutil.Error{Code: errutil.RequestTooLarge, Msg: fmt.Sprintf("request body exceeds the limit of %d bytes", max)}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+	backendmetrics "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/backend/metrics"
+	schedulingtypes "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/scheduling/types"
+	errutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/error"
+)
+
+func TestExceededLimit(t *testing.T) {
+	limits := &v1alpha2.RequestLimits{
+		MaxBodyBytes:    ptr.To[int64](100),
+		MaxMessages:     ptr.To[int32](2),
+		MaxTokens:       ptr.To[int32](50),
+		MaxN:            ptr.To[int32](2),
+		MaxPromptLength: ptr.To[int32](5),
+	}
+	tests := []struct {
+		name      string
+		limits    *v1alpha2.RequestLimits
+		req       *schedulingtypes.LLMRequest
+		body      map[string]interface{}
+		bodySize  int
+		wantLimit string
+		wantCode  string
+	}{
+		{
+			name:   "within limits",
+			limits: limits,
+			req:    &schedulingtypes.LLMRequest{Prompt: "héllo", MaxTokens: 50, N: 2},
+			body: map[string]interface{}{
+				"messages": []interface{}{map[string]interface{}{}, map[string]interface{}{}},
+			},
+			bodySize: 100,
+		},
+		{
+			name:     "no limits",
+			limits:   &v1alpha2.RequestLimits{},
+			req:      &schedulingtypes.LLMRequest{Prompt: "a long prompt", MaxTokens: 1000, N: 10},
+			bodySize: 1 << 20,
+		},
+		{
+			name:      "body too large",
+			limits:    limits,
+			req:       &schedulingtypes.LLMRequest{N: 1},
+			bodySize:  101,
+			wantLimit: limitBodySize,
+			wantCode:  errutil.RequestTooLarge,
+		},
+		{
+			name:   "too many messages",
+			limits: limits,
+			req:    &schedulingtypes.LLMRequest{N: 1},
+			body: map[string]interface{}{
+				"messages": []interface{}{map[string]interface{}{}, map[string]interface{}{}, map[string]interface{}{}},
+			},
+			wantLimit: limitMessages,
+			wantCode:  errutil.BadRequest,
+		},
+		{
+			name:      "too many tokens",
+			limits:    limits,
+			req:       &schedulingtypes.LLMRequest{MaxTokens: 51, N: 1},
+			wantLimit: limitMaxTokens,
+			wantCode:  errutil.BadRequest,
+		},
+		{
+			name:      "too many choices",
+			limits:    limits,
+			req:       &schedulingtypes.LLMRequest{N: 3},
+			wantLimit: limitN,
+			wantCode:  errutil.BadRequest,
+		},
+		{
+			name:      "prompt too long",
+			limits:    limits,
+			req:       &schedulingtypes.LLMRequest{Prompt: "hello!", N: 1},
+			wantLimit: limitPromptLength,
+			wantCode:  errutil.BadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			limit, err := exceededLimit(test.limits, test.req, test.body, test.bodySize)
+			if limit != test.wantLimit {
+				t.Errorf("exceededLimit() limit = %q, want %q", limit, test.wantLimit)
+			}
+			if test.wantCode == "" {
+				if err != nil {
+					t.Errorf("exceededLimit() unexpected error: %v", err)
+				}
+				return
+			}
+			if code := errutil.CanonicalCode(err); code != test.wantCode {
+				t.Errorf("exceededLimit() error code = %q, want %q (error: %v)", code, test.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestProcessRequestBodyTooLarge(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxBytes   int64
+		modelLimit *int64
+		headers    []*configPb.HeaderValue
+	}{
+		{
+			name:     "buffered body over the global limit",
+			maxBytes: 16,
+		},
+		{
+			name:       "passed through body over the model limit",
+			modelLimit: ptr.To[int64](16),
+			headers: []*configPb.HeaderValue{
+				{Key: "x-gateway-model-name", RawValue: []byte("llama-3")},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newModelHeaderTestServer(t, &fakeScheduler{pod: &backendmetrics.Pod{Address: "10.0.0.1"}})
+			server.config.MaxRequestBodyBytes = test.maxBytes
+			if test.modelLimit != nil {
+				model := testInferenceModel("llama", "llama-3", time.Time{})
+				model.Spec.Limits = &v1alpha2.RequestLimits{MaxBodyBytes: test.modelLimit}
+				server.datastore.ModelSetIfOlder(model)
+			}
+			srv := &fakeProcessServer{requests: []*extProcPb.ProcessingRequest{
+				{Request: &extProcPb.ProcessingRequest_RequestHeaders{RequestHeaders: &extProcPb.HttpHeaders{
+					Headers: &configPb.HeaderMap{Headers: test.headers},
+				}}},
+				{Request: &extProcPb.ProcessingRequest_RequestBody{RequestBody: &extProcPb.HttpBody{Body: []byte(`{"model":"llama-3",`)}}},
+				{Request: &extProcPb.ProcessingRequest_RequestBody{RequestBody: &extProcPb.HttpBody{Body: []byte(`"prompt":"hi"}`), EndOfStream: true}}},
+			}}
+			if err := server.Process(srv); err != nil {
+				t.Fatalf("Process returned unexpected error: %v", err)
+			}
+
+			// The request is rejected on the first chunk, and the stream is closed.
+			if len(srv.requests) != 1 {
+				t.Errorf("Process left %d requests unread, want 1", len(srv.requests))
+			}
+			if len(srv.responses) == 0 {
+				t.Fatal("Process sent no response")
+			}
+			resp := srv.responses[len(srv.responses)-1].GetImmediateResponse()
+			if resp == nil || resp.Status.Code != envoyTypePb.StatusCode_PayloadTooLarge {
+				t.Errorf("Last response isn't a 413 immediate response: %v", srv.responses[len(srv.responses)-1])
+			}
+		})
+	}
+}
+
+func TestProcessModelNameHeaderLimits(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		wantStatus    envoyTypePb.StatusCode
+		wantMaxTokens interface{}
+	}{
+		{
+			name:       "max tokens over the limit",
+			body:       `{"model":"llama-3","prompt":"hi","max_tokens":1000}`,
+			wantStatus: envoyTypePb.StatusCode_BadRequest,
+		},
+		{
+			name:          "max tokens within the limit",
+			body:          `{"model":"llama-3","prompt":"hi","max_tokens":16}`,
+			wantMaxTokens: float64(16),
+		},
+		{
+			name: "max tokens not set",
+			body: `{"model":"llama-3","prompt":"hi"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newModelHeaderTestServer(t, &fakeScheduler{pod: &backendmetrics.Pod{Address: "10.0.0.1"}})
+			model := testInferenceModel("llama", "llama-3", time.Time{})
+			model.Spec.Limits = &v1alpha2.RequestLimits{MaxTokens: ptr.To[int32](256)}
+			server.datastore.ModelSetIfOlder(model)
+			srv := &fakeProcessServer{requests: []*extProcPb.ProcessingRequest{
+				{Request: &extProcPb.ProcessingRequest_RequestHeaders{RequestHeaders: &extProcPb.HttpHeaders{
+					Headers: &configPb.HeaderMap{Headers: []*configPb.HeaderValue{
+						{Key: ":path", RawValue: []byte("/v1/completions")},
+						{Key: "x-gateway-model-name", RawValue: []byte("llama-3")},
+					}},
+				}}},
+				{Request: &extProcPb.ProcessingRequest_RequestBody{RequestBody: &extProcPb.HttpBody{Body: []byte(test.body), EndOfStream: true}}},
+			}}
+			if err := server.Process(srv); err != nil {
+				t.Fatalf("Process returned unexpected error: %v", err)
+			}
+			if len(srv.responses) == 0 {
+				t.Fatal("Process sent no response")
+			}
+
+			// The limits are checked against the parsed body rather than passing it through.
+			last := srv.responses[len(srv.responses)-1]
+			if test.wantStatus != 0 {
+				if resp := last.GetImmediateResponse(); resp == nil || resp.Status.Code != test.wantStatus {
+					t.Errorf("Last response isn't a %v immediate response: %v", test.wantStatus, last)
+				}
+				return
+			}
+			body := last.GetRequestBody().GetResponse().GetBodyMutation().GetStreamedResponse().GetBody()
+			var got map[string]interface{}
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("Last response doesn't carry a JSON request body: %v: %v", err, last)
+			}
+			if got["max_tokens"] != test.wantMaxTokens {
+				t.Errorf("Forwarded max_tokens = %v, want %v", got["max_tokens"], test.wantMaxTokens)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+	backendmetrics "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/backend/metrics"
+)
+
+// ModelsPath is the OpenAI API path listing the models.
+const ModelsPath = "/v1/models"
+
+// modelObject is an OpenAI model object.
+type modelObject struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+type modelList struct {
+	Object string        `json:"object"`
+	Data   []modelObject `json:"data"`
+}
+
+// modelsResponse answers a GET request to the models API with the registered InferenceModels.
+// The list holds the modelNames and the aliases retained by the InferenceModels, but not the
+// alias patterns, which a single model ID doesn't describe. A model is retrieved by any name that
+// its requests may use, patterns included. It returns nil if the path is not a models API path.
+func (s *StreamingServer) modelsResponse(path string) *extProcPb.ProcessingResponse {
+	listed := s.listedModels()
+	if path == ModelsPath {
+		models := []modelObject{}
+		for _, model := range listed {
+			models = append(models, newModelObject(model.Spec.ModelName, model))
+			for _, alias := range model.Spec.Aliases {
+				// Aliases retained by an older InferenceModel aren't routed to this one.
+				if !strings.Contains(alias, "*") && s.datastore.ModelResolve(alias) == model {
+					models = append(models, newModelObject(alias, model))
+				}
+			}
+		}
+		sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+		return jsonImmediateResponse(envoyTypePb.StatusCode_OK, modelList{Object: "list", Data: models})
+	}
+
+	escapedID, ok := strings.CutPrefix(path, ModelsPath+"/")
+	if !ok {
+		return nil
+	}
+	// Model names may contain slashes, e.g. "meta-llama/Llama-3.1-8B-Instruct".
+	id, err := url.PathUnescape(escapedID)
+	if err != nil {
+		id = escapedID
+	}
+	if model := s.datastore.ModelResolve(id); model != nil && slices.Contains(listed, model) {
+		return jsonImmediateResponse(envoyTypePb.StatusCode_OK, newModelObject(id, model))
+	}
+	param := "model"
+	return jsonImmediateResponse(envoyTypePb.StatusCode_NotFound, openAIErrorResponse{Error: openAIError{
+		Message: "The model '" + id + "' does not exist",
+		Type:    "invalid_request_error",
+		Param:   &param,
+		Code:    "model_not_found",
+	}})
+}
+
+// newModelObject returns the model object of a name of an InferenceModel.
+func newModelObject(id string, model *v1alpha2.InferenceModel) modelObject {
+	return modelObject{
+		ID:      id,
+		Object:  "model",
+		Created: model.CreationTimestamp.Unix(),
+		OwnedBy: model.Namespace,
+	}
+}
+
+// listedModels returns the InferenceModels to list, restricted to the ones with serving capacity
+// if configured.
+func (s *StreamingServer) listedModels() []*v1alpha2.InferenceModel {
+	models := s.datastore.ModelGetAll()
+	if !s.config.FilterModelsByCapacity {
+		return models
+	}
+	return modelsWithServingCapacity(models, s.datastore.PodGetAll())
+}
+
+// modelsWithServingCapacity returns the models that a pod with a healthy metrics scrape can serve.
+func modelsWithServingCapacity(models []*v1alpha2.InferenceModel, pods []backendmetrics.PodMetrics) []*v1alpha2.InferenceModel {
+	// Adapters reported by any pod. Other target models are assumed to be base models, which every
+	// pod serves.
+	adapters := map[string]bool{}
+	var healthy []*backendmetrics.Metrics
+	for _, pod := range pods {
+		m := pod.GetMetrics()
+		if m == nil {
+			continue
+		}
+		for name := range m.ActiveModels {
+			adapters[name] = true
+		}
+		for name := range m.WaitingModels {
+			adapters[name] = true
+		}
+		if pod.GetScrapeHealth().Healthy() {
+			healthy = append(healthy, m)
+		}
+	}
+
+	var res []*v1alpha2.InferenceModel
+	for _, model := range models {
+		targets := []string{model.Spec.ModelName}
+		if len(model.Spec.TargetModels) > 0 {
+			targets = targets[:0]
+			for _, target := range model.Spec.TargetModels {
+				targets = append(targets, target.Name)
+			}
+		}
+		if hasServingCapacity(healthy, targets, adapters) {
+			res = append(res, model)
+		}
+	}
+	return res
+}
+
+// hasServingCapacity returns true if one of the pods can serve one of the target models without
+// evicting an adapter: the target is a base model, is loaded or waiting on the pod, the pod has a
+// free adapter slot, or the pod doesn't report its adapters.
+func hasServingCapacity(pods []*backendmetrics.Metrics, targets []string, adapters map[string]bool) bool {
+	for _, m := range pods {
+		for _, target := range targets {
+			_, active := m.ActiveModels[target]
+			_, waiting := m.WaitingModels[target]
+			if !adapters[target] || active || waiting || m.MaxActiveModels == 0 || len(m.ActiveModels) < m.MaxActiveModels {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonImmediateResponse builds an immediate response with the given status and JSON body.
+func jsonImmediateResponse(code envoyTypePb.StatusCode, body interface{}) *extProcPb.ProcessingResponse {
+	// The bodies are built from plain strings, integers and structs of them, so marshaling can't fail.
+	bytes, _ := json.Marshal(body)
+	return &extProcPb.ProcessingResponse{
+		Response: &extProcPb.ProcessingResponse_ImmediateResponse{
+			ImmediateResponse: &extProcPb.ImmediateResponse{
+				Status: &envoyTypePb.HttpStatus{Code: code},
+				Headers: &extProcPb.HeaderMutation{
+					SetHeaders: []*configPb.HeaderValueOption{
+						{
+							Header: &configPb.HeaderValue{
+								Key:      "content-type",
+								RawValue: []byte("application/json"),
+							},
+						},
+					},
+				},
+				Body: bytes,
+			},
+		},
+	}
+}
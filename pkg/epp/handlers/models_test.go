@@ -0,0 +1,258 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+	backendmetrics "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/backend/metrics"
+	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/datastore"
+)
+
+func testInferenceModel(name, modelName string, created time.Time, targets ...string) *v1alpha2.InferenceModel {
+	model := &v1alpha2.InferenceModel{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: v1alpha2.InferenceModelSpec{ModelName: modelName},
+	}
+	for _, target := range targets {
+		model.Spec.TargetModels = append(model.Spec.TargetModels, v1alpha2.TargetModel{Name: target})
+	}
+	return model
+}
+
+func TestModelsResponse(t *testing.T) {
+	created := time.Unix(1700000000, 0)
+	pmf := backendmetrics.NewPodMetricsFactory(&backendmetrics.FakePodMetricsClient{}, time.Second)
+	ds := datastore.NewDatastore(context.Background(), pmf)
+	ds.ModelSetIfOlder(testInferenceModel("sql", "sql-lora", created, "sql-lora-v1", "sql-lora-v2"))
+	llama := testInferenceModel("llama", "meta-llama/Llama-3.1-8B-Instruct", created)
+	llama.Spec.Aliases = []string{"llama", "llama-3-*"}
+	ds.ModelSetIfOlder(llama)
+	// The alias is retained by the older InferenceModel.
+	shadow := testInferenceModel("shadow", "shadow", created.Add(time.Hour))
+	shadow.Spec.Aliases = []string{"llama"}
+	ds.ModelSetIfOlder(shadow)
+	server := NewStreamingServer(nil, "", "", ds, Config{})
+
+	tests := []struct {
+		name     string
+		path     string
+		wantCode envoyTypePb.StatusCode
+		wantBody string
+	}{
+		{
+			name:     "list",
+			path:     ModelsPath,
+			wantCode: envoyTypePb.StatusCode_OK,
+			wantBody: `{"object":"list","data":[` +
+				`{"id":"llama","object":"model","created":1700000000,"owned_by":"default"},` +
+				`{"id":"meta-llama/Llama-3.1-8B-Instruct","object":"model","created":1700000000,"owned_by":"default"},` +
+				`{"id":"shadow","object":"model","created":1700003600,"owned_by":"default"},` +
+				`{"id":"sql-lora","object":"model","created":1700000000,"owned_by":"default"}]}`,
+		},
+		{
+			name:     "retrieve alias",
+			path:     ModelsPath + "/llama",
+			wantCode: envoyTypePb.StatusCode_OK,
+			wantBody: `{"id":"llama","object":"model","created":1700000000,"owned_by":"default"}`,
+		},
+		{
+			name:     "retrieve name matching an alias pattern",
+			path:     ModelsPath + "/llama-3-70b",
+			wantCode: envoyTypePb.StatusCode_OK,
+			wantBody: `{"id":"llama-3-70b","object":"model","created":1700000000,"owned_by":"default"}`,
+		},
+		{
+			name:     "retrieve",
+			path:     ModelsPath + "/sql-lora",
+			wantCode: envoyTypePb.StatusCode_OK,
+			wantBody: `{"id":"sql-lora","object":"model","created":1700000000,"owned_by":"default"}`,
+		},
+		{
+			name:     "retrieve model with a slash",
+			path:     ModelsPath + "/meta-llama/Llama-3.1-8B-Instruct",
+			wantCode: envoyTypePb.StatusCode_OK,
+			wantBody: `{"id":"meta-llama/Llama-3.1-8B-Instruct","object":"model","created":1700000000,"owned_by":"default"}`,
+		},
+		{
+			name:     "retrieve escaped model",
+			path:     ModelsPath + "/meta-llama%2FLlama-3.1-8B-Instruct",
+			wantCode: envoyTypePb.StatusCode_OK,
+			wantBody: `{"id":"meta-llama/Llama-3.1-8B-Instruct","object":"model","created":1700000000,"owned_by":"default"}`,
+		},
+		{
+			name:     "retrieve unknown model",
+			path:     ModelsPath + "/sql-lora-v1",
+			wantCode: envoyTypePb.StatusCode_NotFound,
+			wantBody: `{"error":{"message":"The model 'sql-lora-v1' does not exist","type":"invalid_request_error","param":"model","code":"model_not_found"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := server.modelsResponse(test.path)
+			if resp == nil {
+				t.Fatalf("modelsResponse(%q) returned nil", test.path)
+			}
+			immediate := resp.Response.(*extProcPb.ProcessingResponse_ImmediateResponse).ImmediateResponse
+			if immediate.Status.Code != test.wantCode {
+				t.Errorf("modelsResponse(%q) status = %v, want %v", test.path, immediate.Status.Code, test.wantCode)
+			}
+			if diff := cmp.Diff(test.wantBody, string(immediate.Body)); diff != "" {
+				t.Errorf("modelsResponse(%q) returned unexpected body, diff(-want, +got): %v", test.path, diff)
+			}
+			if !json.Valid(immediate.Body) {
+				t.Errorf("modelsResponse(%q) returned invalid JSON %q", test.path, immediate.Body)
+			}
+		})
+	}
+
+	if resp := server.modelsResponse("/v1/modelsx"); resp != nil {
+		t.Errorf("modelsResponse returned a response for a non-models path: %v", resp)
+	}
+}
+
+func TestModelsWithServingCapacity(t *testing.T) {
+	base := testInferenceModel("base", "base-model", time.Time{})
+	loaded := testInferenceModel("loaded", "loaded", time.Time{}, "loaded-v1")
+	unloaded := testInferenceModel("unloaded", "unloaded", time.Time{}, "unloaded-v1")
+	models := []*v1alpha2.InferenceModel{base, loaded, unloaded}
+
+	tests := []struct {
+		name string
+		pods []backendmetrics.PodMetrics
+		want []*v1alpha2.InferenceModel
+	}{
+		{
+			name: "no pods",
+		},
+		{
+			name: "no healthy pods",
+			pods: []backendmetrics.PodMetrics{
+				&backendmetrics.FakePodMetrics{
+					Metrics: &backendmetrics.Metrics{},
+					Health:  &backendmetrics.ScrapeHealth{ConsecutiveFailures: 3},
+				},
+			},
+		},
+		{
+			name: "adapter slots full",
+			pods: []backendmetrics.PodMetrics{
+				&backendmetrics.FakePodMetrics{Metrics: &backendmetrics.Metrics{
+					ActiveModels:    map[string]int{"loaded-v1": 1},
+					MaxActiveModels: 1,
+				}},
+				&backendmetrics.FakePodMetrics{Metrics: &backendmetrics.Metrics{
+					ActiveModels:    map[string]int{"other-v1": 1},
+					WaitingModels:   map[string]int{"loaded-v1": 1},
+					MaxActiveModels: 1,
+				}},
+				// The only pod with unloaded-v1 can't be scraped.
+				&backendmetrics.FakePodMetrics{
+					Metrics: &backendmetrics.Metrics{
+						ActiveModels:    map[string]int{"unloaded-v1": 1},
+						MaxActiveModels: 2,
+					},
+					Health: &backendmetrics.ScrapeHealth{ConsecutiveFailures: 1},
+				},
+			},
+			want: []*v1alpha2.InferenceModel{base, loaded},
+		},
+		{
+			name: "free adapter slot",
+			pods: []backendmetrics.PodMetrics{
+				&backendmetrics.FakePodMetrics{Metrics: &backendmetrics.Metrics{
+					ActiveModels:    map[string]int{"loaded-v1": 1},
+					MaxActiveModels: 1,
+				}},
+				&backendmetrics.FakePodMetrics{Metrics: &backendmetrics.Metrics{
+					ActiveModels:    map[string]int{"unloaded-v1": 1},
+					MaxActiveModels: 2,
+				}},
+			},
+			want: models,
+		},
+		{
+			name: "adapter loaded elsewhere is unknown",
+			pods: []backendmetrics.PodMetrics{
+				&backendmetrics.FakePodMetrics{Metrics: &backendmetrics.Metrics{
+					ActiveModels:    map[string]int{"loaded-v1": 1},
+					MaxActiveModels: 1,
+				}},
+			},
+			// unloaded-v1 was never seen, so it is taken for a base model.
+			want: models,
+		},
+		{
+			name: "pods not reporting adapters",
+			pods: []backendmetrics.PodMetrics{
+				&backendmetrics.FakePodMetrics{Metrics: &backendmetrics.Metrics{}},
+			},
+			want: models,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := modelsWithServingCapacity(models, test.pods)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("modelsWithServingCapacity returned unexpected models, diff(-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestHandleRequestHeadersModels(t *testing.T) {
+	pmf := backendmetrics.NewPodMetricsFactory(&backendmetrics.FakePodMetricsClient{}, time.Second)
+	ds := datastore.NewDatastore(context.Background(), pmf)
+	ds.ModelSetIfOlder(testInferenceModel("sql", "sql-lora", time.Time{}))
+	server := NewStreamingServer(nil, "", "", ds, Config{})
+
+	reqCtx := &RequestContext{}
+	req := &extProcPb.ProcessingRequest_RequestHeaders{
+		RequestHeaders: &extProcPb.HttpHeaders{
+			Headers: &configPb.HeaderMap{Headers: []*configPb.HeaderValue{
+				{Key: ":method", RawValue: []byte("GET")},
+				{Key: ":path", RawValue: []byte("/v1/models")},
+			}},
+			EndOfStream: true,
+		},
+	}
+	if err := server.HandleRequestHeaders(context.Background(), reqCtx, req); err != nil {
+		t.Fatalf("HandleRequestHeaders returned unexpected error: %v", err)
+	}
+	immediate, ok := reqCtx.reqHeaderResp.GetResponse().(*extProcPb.ProcessingResponse_ImmediateResponse)
+	if !ok {
+		t.Fatalf("HandleRequestHeaders didn't respond immediately: %v", reqCtx.reqHeaderResp)
+	}
+	if immediate.ImmediateResponse.Status.Code != envoyTypePb.StatusCode_OK {
+		t.Errorf("HandleRequestHeaders returned status %v, want OK", immediate.ImmediateResponse.Status.Code)
+	}
+}
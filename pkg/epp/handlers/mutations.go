@@ -0,0 +1,125 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+)
+
+// applyRequestMutations applies the mutations of an InferenceModel in order to the request body,
+// returning the names of the top-level fields it changed. Mutations that don't apply to the request,
+// such as clamping a field it doesn't set, leave it unchanged.
+func applyRequestMutations(mutations []v1alpha2.RequestMutation, body map[string]interface{}) ([]string, error) {
+	var changed []string
+	for i, m := range mutations {
+		// The model is resolved before the mutations, rewriting it would bypass the InferenceModel.
+		if m.Field == "model" {
+			continue
+		}
+		field, err := applyRequestMutation(m, body)
+		if err != nil {
+			return nil, fmt.Errorf("mutation %d: %w", i, err)
+		}
+		if field != "" && !slices.Contains(changed, field) {
+			changed = append(changed, field)
+		}
+	}
+	return changed, nil
+}
+
+// applyRequestMutation applies a mutation to the request body, returning the name of the field it
+// changed, if any.
+func applyRequestMutation(m v1alpha2.RequestMutation, body map[string]interface{}) (string, error) {
+	switch m.Type {
+	case v1alpha2.MutationSetIfAbsent:
+		if _, ok := body[m.Field]; ok || m.Value == nil {
+			return "", nil
+		}
+		var value interface{}
+		if err := json.Unmarshal(m.Value.Raw, &value); err != nil {
+			return "", fmt.Errorf("invalid value of field %q: %w", m.Field, err)
+		}
+		body[m.Field] = value
+		return m.Field, nil
+	case v1alpha2.MutationClamp:
+		value, ok := body[m.Field].(float64)
+		if !ok {
+			// Leave absent and invalid values to the model server.
+			return "", nil
+		}
+		clamped := value
+		if m.Min != nil {
+			lower, err := strconv.ParseFloat(*m.Min, 64)
+			if err != nil {
+				return "", fmt.Errorf("invalid min of field %q: %w", m.Field, err)
+			}
+			clamped = max(clamped, lower)
+		}
+		if m.Max != nil {
+			upper, err := strconv.ParseFloat(*m.Max, 64)
+			if err != nil {
+				return "", fmt.Errorf("invalid max of field %q: %w", m.Field, err)
+			}
+			clamped = min(clamped, upper)
+		}
+		if clamped == value {
+			return "", nil
+		}
+		body[m.Field] = clamped
+		return m.Field, nil
+	case v1alpha2.MutationPrependSystemMessage:
+		return prependSystemMessage(m.Content, body), nil
+	case v1alpha2.MutationDrop:
+		if _, ok := body[m.Field]; !ok {
+			return "", nil
+		}
+		delete(body, m.Field)
+		return m.Field, nil
+	}
+	return "", fmt.Errorf("unknown mutation type %q", m.Type)
+}
+
+// prependSystemMessage prepends a system message to the messages of a chat completions request, or
+// the content to the string prompts of a completions request. It returns the name of the field it
+// changed, if any. Token ID prompts are left unchanged.
+func prependSystemMessage(content string, body map[string]interface{}) string {
+	if messages, ok := body["messages"].([]interface{}); ok {
+		system := map[string]interface{}{"role": "system", "content": content}
+		body["messages"] = append([]interface{}{system}, messages...)
+		return "messages"
+	}
+	switch prompt := body["prompt"].(type) {
+	case string:
+		body["prompt"] = content + prompt
+		return "prompt"
+	case []interface{}:
+		changed := ""
+		for i, p := range prompt {
+			if text, ok := p.(string); ok {
+				prompt[i] = content + text
+				changed = "prompt"
+			}
+		}
+		return changed
+	}
+	return ""
+}
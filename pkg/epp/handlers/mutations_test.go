@@ -0,0 +1,196 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"github.com/google/go-cmp/cmp"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+	backendmetrics "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/backend/metrics"
+	errutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/error"
+)
+
+func TestApplyRequestMutations(t *testing.T) {
+	setMaxTokens := v1alpha2.RequestMutation{Type: v1alpha2.MutationSetIfAbsent, Field: "max_tokens", Value: &apiextensionsv1.JSON{Raw: []byte("256")}}
+	clampTemperature := v1alpha2.RequestMutation{Type: v1alpha2.MutationClamp, Field: "temperature", Min: ptr.To("0.2"), Max: ptr.To("1")}
+	clampMaxTokens := v1alpha2.RequestMutation{Type: v1alpha2.MutationClamp, Field: "max_tokens", Max: ptr.To("512")}
+	prependSystem := v1alpha2.RequestMutation{Type: v1alpha2.MutationPrependSystemMessage, Content: "Be concise. "}
+	dropLogprobs := v1alpha2.RequestMutation{Type: v1alpha2.MutationDrop, Field: "logprobs"}
+
+	tests := []struct {
+		name        string
+		mutations   []v1alpha2.RequestMutation
+		body        string
+		want        string
+		wantChanged []string
+		wantErr     bool
+	}{
+		{
+			name:        "chat defaults applied",
+			mutations:   []v1alpha2.RequestMutation{setMaxTokens, clampTemperature, prependSystem, dropLogprobs},
+			body:        `{"model":"m","messages":[{"role":"user","content":"hi"}],"temperature":1.8,"logprobs":true}`,
+			want:        `{"model":"m","max_tokens":256,"messages":[{"role":"system","content":"Be concise. "},{"role":"user","content":"hi"}],"temperature":1}`,
+			wantChanged: []string{"max_tokens", "temperature", "messages", "logprobs"},
+		},
+		{
+			name:        "completion defaults applied",
+			mutations:   []v1alpha2.RequestMutation{setMaxTokens, clampTemperature, prependSystem},
+			body:        `{"model":"m","prompt":"hi","temperature":0}`,
+			want:        `{"model":"m","max_tokens":256,"prompt":"Be concise. hi","temperature":0.2}`,
+			wantChanged: []string{"max_tokens", "temperature", "prompt"},
+		},
+		{
+			name:        "completion batch prompts prefixed",
+			mutations:   []v1alpha2.RequestMutation{prependSystem},
+			body:        `{"model":"m","prompt":["hi",[1,2],"bye"]}`,
+			want:        `{"model":"m","prompt":["Be concise. hi",[1,2],"Be concise. bye"]}`,
+			wantChanged: []string{"prompt"},
+		},
+		{
+			name:      "token prompt unchanged",
+			mutations: []v1alpha2.RequestMutation{prependSystem},
+			body:      `{"model":"m","prompt":[[1,2]]}`,
+			want:      `{"model":"m","prompt":[[1,2]]}`,
+		},
+		{
+			name:      "values within bounds and set fields unchanged",
+			mutations: []v1alpha2.RequestMutation{setMaxTokens, clampTemperature, clampMaxTokens, dropLogprobs},
+			body:      `{"model":"m","prompt":"hi","max_tokens":100,"temperature":0.7}`,
+			want:      `{"model":"m","prompt":"hi","max_tokens":100,"temperature":0.7}`,
+		},
+		{
+			name:        "mutations applied in order",
+			mutations:   []v1alpha2.RequestMutation{setMaxTokens, clampMaxTokens},
+			body:        `{"model":"m","prompt":"hi","max_tokens":4096}`,
+			want:        `{"model":"m","prompt":"hi","max_tokens":512}`,
+			wantChanged: []string{"max_tokens"},
+		},
+		{
+			name:      "invalid values left to the model server",
+			mutations: []v1alpha2.RequestMutation{clampTemperature},
+			body:      `{"model":"m","prompt":"hi","temperature":"hot"}`,
+			want:      `{"model":"m","prompt":"hi","temperature":"hot"}`,
+		},
+		{
+			name:      "model not mutated",
+			mutations: []v1alpha2.RequestMutation{{Type: v1alpha2.MutationDrop, Field: "model"}},
+			body:      `{"model":"m","prompt":"hi"}`,
+			want:      `{"model":"m","prompt":"hi"}`,
+		},
+		{
+			name:      "invalid bound",
+			mutations: []v1alpha2.RequestMutation{{Type: v1alpha2.MutationClamp, Field: "temperature", Max: ptr.To("one")}},
+			body:      `{"model":"m","prompt":"hi","temperature":2}`,
+			wantErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var body map[string]interface{}
+			if err := json.Unmarshal([]byte(test.body), &body); err != nil {
+				t.Fatal(err)
+			}
+			changed, err := applyRequestMutations(test.mutations, body)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("applyRequestMutations() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(test.wantChanged, changed); diff != "" {
+				t.Errorf("Unexpected changed fields (-want +got): %s", diff)
+			}
+			var want map[string]interface{}
+			if err := json.Unmarshal([]byte(test.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want, body); diff != "" {
+				t.Errorf("Unexpected mutated body (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestHandleRequestBodyMutations(t *testing.T) {
+	model := testInferenceModel("llama", "llama-3", time.Time{})
+	model.Spec.Mutations = []v1alpha2.RequestMutation{
+		{Type: v1alpha2.MutationClamp, Field: "max_tokens", Max: ptr.To("512")},
+		{Type: v1alpha2.MutationPrependSystemMessage, Content: "Be concise."},
+		{Type: v1alpha2.MutationDrop, Field: "user"},
+	}
+	model.Spec.Limits = &v1alpha2.RequestLimits{MaxMessages: ptr.To[int32](2)}
+
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		want     string
+		wantCode string
+	}{
+		{
+			name: "chat completion",
+			path: "/v1/chat/completions",
+			body: `{"user":"u1", "model":"llama-3", "messages":[{"role":"user","content":"hi"}], "max_tokens":4096}`,
+			want: `{"model":"llama-3", "messages":[{"content":"Be concise.","role":"system"},{"content":"hi","role":"user"}], "max_tokens":512}`,
+		},
+		{
+			name: "completion",
+			path: "/v1/completions",
+			body: `{"model":"llama-3", "prompt":" hi", "max_tokens":16, "user":"u1"}`,
+			want: `{"model":"llama-3", "prompt":"Be concise. hi", "max_tokens":16}`,
+		},
+		{
+			name:     "limits checked after the mutations",
+			path:     "/v1/chat/completions",
+			body:     `{"model":"llama-3", "messages":[{"role":"user","content":"hi"},{"role":"user","content":"again"}]}`,
+			wantCode: errutil.BadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newModelHeaderTestServer(t, &fakeScheduler{pod: &backendmetrics.Pod{Address: "10.0.0.1"}})
+			server.datastore.ModelSetIfOlder(model)
+			var bodyMap map[string]interface{}
+			if err := json.Unmarshal([]byte(test.body), &bodyMap); err != nil {
+				t.Fatal(err)
+			}
+
+			reqCtx, err := server.HandleRequestBody(t.Context(), &RequestContext{RequestPath: test.path}, &extProcPb.ProcessingRequest{}, []byte(test.body), bodyMap)
+			if test.wantCode != "" {
+				if code := errutil.CanonicalCode(err); code != test.wantCode {
+					t.Errorf("HandleRequestBody() error code = %q, want %q (error: %v)", code, test.wantCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("HandleRequestBody() unexpected error: %v", err)
+			}
+			got := reqCtx.reqBodyResp.GetRequestBody().GetResponse().GetBodyMutation().GetStreamedResponse().GetBody()
+			if string(got) != test.want {
+				t.Errorf("HandleRequestBody() body = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
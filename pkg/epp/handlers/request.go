@@ -18,14 +18,17 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/metrics"
 	schedulingtypes "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/scheduling/types"
 	errutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/error"
 	logutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/logging"
@@ -36,54 +39,119 @@ func (s *StreamingServer) HandleRequestBody(
 	ctx context.Context,
 	reqCtx *RequestContext,
 	req *extProcPb.ProcessingRequest,
+	requestBody []byte,
 	requestBodyMap map[string]interface{},
 ) (*RequestContext, error) {
 	var requestBodyBytes []byte
 	logger := log.FromContext(ctx)
 
-	// Resolve target models.
-	model, ok := requestBodyMap["model"].(string)
-	if !ok {
-		return reqCtx, errutil.Error{Code: errutil.BadRequest, Msg: "model not found in request"}
+	llmReq, err := parseRequestBody(reqCtx.RequestPath, requestBodyMap)
+	if err != nil {
+		return reqCtx, err
 	}
 
-	modelName := model
-
-	// NOTE: The nil checking for the modelObject means that we DO allow passthrough currently.
-	// This might be a security risk in the future where adapters not registered in the InferenceModel
-	// are able to be requested by using their distinct name.
-	modelObj := s.datastore.ModelGet(model)
-	if modelObj == nil {
-		return reqCtx, errutil.Error{Code: errutil.BadConfiguration, Msg: fmt.Sprintf("error finding a model object in InferenceModel for input %v", model)}
+	// Resolve target models, unless the model name header was already resolved for this model.
+	if headerReq := reqCtx.headerRequest; headerReq != nil && headerReq.Model == llmReq.Model {
+		llmReq.ResolvedTargetModel = headerReq.ResolvedTargetModel
+		llmReq.Critical = headerReq.Critical
+	} else if reqCtx.inferenceModel, err = s.resolveTargetModel(ctx, llmReq); err != nil {
+		return reqCtx, err
 	}
-	if len(modelObj.Spec.TargetModels) > 0 {
-		modelName = RandomWeightedDraw(logger, modelObj, 0)
-		if modelName == "" {
-			return reqCtx, errutil.Error{Code: errutil.BadConfiguration, Msg: fmt.Sprintf("error getting target model name for model %v", modelObj.Name)}
+
+	// Apply the mutations of the InferenceModel, and parse the mutated request again.
+	var mutated []string
+	if model := reqCtx.inferenceModel; model != nil && len(model.Spec.Mutations) > 0 {
+		if mutated, err = applyRequestMutations(model.Spec.Mutations, requestBodyMap); err != nil {
+			logger.V(logutil.DEFAULT).Error(err, "Error mutating request body")
+			return reqCtx, errutil.Error{Code: errutil.Internal, Msg: fmt.Sprintf("error mutating request body: %v", err)}
+		}
+		if len(mutated) > 0 {
+			mutatedReq, err := parseRequestBody(reqCtx.RequestPath, requestBodyMap)
+			if err != nil {
+				return reqCtx, err
+			}
+			mutatedReq.ResolvedTargetModel = llmReq.ResolvedTargetModel
+			mutatedReq.Critical = llmReq.Critical
+			llmReq = mutatedReq
 		}
 	}
-	llmReq := &schedulingtypes.LLMRequest{
-		Model:               model,
-		ResolvedTargetModel: modelName,
-		Critical:            modelObj.Spec.Criticality != nil && *modelObj.Spec.Criticality == v1alpha2.Critical,
+	logger.V(logutil.DEBUG).Info("LLM request assembled", "path", llmReq.Path, "model", llmReq.Model, "targetModel", llmReq.ResolvedTargetModel,
+		"critical", llmReq.Critical, "maxTokens", llmReq.MaxTokens, "stream", llmReq.Stream, "n", llmReq.N, "mutated", mutated)
+	if err := checkRequestLimits(reqCtx.inferenceModel, llmReq, requestBodyMap, len(requestBody)); err != nil {
+		return reqCtx, err
 	}
-	logger.V(logutil.DEBUG).Info("LLM request assembled", "model", llmReq.Model, "targetModel", llmReq.ResolvedTargetModel, "critical", llmReq.Critical)
 
-	var err error
-	// Update target models in the body.
+	// Update target models and mutated fields in the body, leaving the rest of it untouched.
+	fields := map[string]interface{}{}
+	var dropped []string
+	for _, name := range mutated {
+		if value, ok := requestBodyMap[name]; ok {
+			fields[name] = value
+		} else {
+			dropped = append(dropped, name)
+		}
+	}
 	if llmReq.Model != llmReq.ResolvedTargetModel {
-		requestBodyMap["model"] = llmReq.ResolvedTargetModel
+		fields["model"] = llmReq.ResolvedTargetModel
+	}
+	if s.config.InjectStreamUsage {
+		if reqCtx.stripStreamUsage = injectStreamUsage(requestBodyMap); reqCtx.stripStreamUsage {
+			fields["stream_options"] = requestBodyMap["stream_options"]
+		}
+	}
+
+	requestBodyBytes = requestBody
+	if len(fields) > 0 || len(dropped) > 0 {
+		requestBodyBytes, err = rewriteTopLevelFields(requestBody, fields, dropped)
+		if err != nil {
+			logger.V(logutil.DEFAULT).Error(err, "Error rewriting request body")
+			return reqCtx, errutil.Error{Code: errutil.Internal, Msg: fmt.Sprintf("error rewriting request body: %v", err)}
+		}
 	}
 
-	requestBodyBytes, err = json.Marshal(requestBodyMap)
+	endpoint, err := s.scheduleRequest(ctx, reqCtx, llmReq)
 	if err != nil {
-		logger.V(logutil.DEFAULT).Error(err, "Error marshaling request body")
-		return reqCtx, errutil.Error{Code: errutil.Internal, Msg: fmt.Sprintf("error marshaling request body: %v", err)}
+		return reqCtx, err
+	}
+	reqCtx.RequestSize = len(requestBodyBytes)
+
+	s.populateRequestHeaderResponse(reqCtx, endpoint, len(requestBodyBytes))
+
+	// The Endpoint Picker supports two approaches to communicating the target endpoint, as a request header
+	// and as an unstructure ext-proc response metadata key/value pair. This enables different integration
+	// options for gateway providers.
+	reqCtx.reqBodyResp = requestBodyResponse(requestBodyBytes, true)
+	return reqCtx, nil
+}
+
+// resolveTargetModel resolves the InferenceModel of the request, setting its target model and its
+// criticality. It returns the InferenceModel, nil if the request is passed through without one.
+func (s *StreamingServer) resolveTargetModel(ctx context.Context, llmReq *schedulingtypes.LLMRequest) (*v1alpha2.InferenceModel, error) {
+	modelName := llmReq.Model
+	modelObj, err := s.resolveInferenceModel(ctx, llmReq.Model)
+	if err != nil {
+		return nil, err
+	}
+	if modelObj != nil {
+		modelName = modelObj.Spec.ModelName
+		if len(modelObj.Spec.TargetModels) > 0 {
+			modelName = RandomWeightedDraw(log.FromContext(ctx), modelObj, 0)
+			if modelName == "" {
+				return nil, errutil.Error{Code: errutil.BadConfiguration, Msg: fmt.Sprintf("error getting target model name for model %v", modelObj.Name)}
+			}
+		}
+		llmReq.Critical = modelObj.Spec.Criticality != nil && *modelObj.Spec.Criticality == v1alpha2.Critical
 	}
+	llmReq.ResolvedTargetModel = modelName
+	return modelObj, nil
+}
 
+// scheduleRequest picks the endpoint serving the request, and records the routing in the request
+// context.
+func (s *StreamingServer) scheduleRequest(ctx context.Context, reqCtx *RequestContext, llmReq *schedulingtypes.LLMRequest) (string, error) {
 	target, err := s.scheduler.Schedule(ctx, llmReq)
 	if err != nil {
-		return reqCtx, errutil.Error{Code: errutil.InferencePoolResourceExhausted, Msg: fmt.Errorf("failed to find target pod: %w", err).Error()}
+		return "", errutil.Error{Code: errutil.InferencePoolResourceExhausted, Msg: fmt.Errorf("failed to find target pod: %w", err).Error()}
 	}
 	targetPod := target.GetPod()
 
@@ -91,34 +159,59 @@ func (s *StreamingServer) HandleRequestBody(
 	// Attach the port number
 	pool, err := s.datastore.PoolGet()
 	if err != nil {
-		return reqCtx, err
+		return "", err
 	}
 	endpoint := targetPod.Address + ":" + strconv.Itoa(int(pool.Spec.TargetPortNumber))
 
-	logger.V(logutil.DEFAULT).Info("Request handled",
+	log.FromContext(ctx).V(logutil.DEFAULT).Info("Request handled",
 		"model", llmReq.Model, "targetModel", llmReq.ResolvedTargetModel, "endpoint", targetPod, "endpoint metrics",
 		fmt.Sprintf("%+v", target))
 
 	reqCtx.Model = llmReq.Model
 	reqCtx.ResolvedTargetModel = llmReq.ResolvedTargetModel
-	reqCtx.RequestSize = len(requestBodyBytes)
 	reqCtx.TargetPod = targetPod.NamespacedName.String()
+	reqCtx.targetPodName = targetPod.NamespacedName
 	reqCtx.TargetEndpoint = endpoint
+	return endpoint, nil
+}
 
-	s.populateRequestHeaderResponse(reqCtx, endpoint, len(requestBodyBytes))
+// scheduleFromHeader schedules a request whose model is set in the model name header before its body
+// is received, so that the body is passed through unchanged. It returns false if the body must be
+// parsed to rewrite the target model, apply the mutations of the InferenceModel or check its limits
+// beyond the body size, leaving the request to HandleRequestBody.
+func (s *StreamingServer) scheduleFromHeader(ctx context.Context, reqCtx *RequestContext, model string) (bool, error) {
+	llmReq := &schedulingtypes.LLMRequest{Path: reqCtx.RequestPath, Model: model}
+	var err error
+	if reqCtx.inferenceModel, err = s.resolveTargetModel(ctx, llmReq); err != nil {
+		return false, err
+	}
+	if model := reqCtx.inferenceModel; llmReq.ResolvedTargetModel != llmReq.Model ||
+		(model != nil && (len(model.Spec.Mutations) > 0 || limitsNeedBody(model.Spec.Limits))) {
+		reqCtx.headerRequest = llmReq
+		return false, nil
+	}
+
+	endpoint, err := s.scheduleRequest(ctx, reqCtx, llmReq)
+	if err != nil {
+		return false, err
+	}
+	// The body isn't mutated, so the Content-Length is left untouched.
+	s.populateRequestHeaderResponse(reqCtx, endpoint, 0)
+	reqCtx.bodyPassthrough = true
+	return true, nil
+}
 
-	reqCtx.reqBodyResp = &extProcPb.ProcessingResponse{
-		// The Endpoint Picker supports two approaches to communicating the target endpoint, as a request header
-		// and as an unstructure ext-proc response metadata key/value pair. This enables different integration
-		// options for gateway providers.
+// requestBodyResponse builds the response carrying a request body chunk back to the proxy.
+func requestBodyResponse(body []byte, endOfStream bool) *extProcPb.ProcessingResponse {
+	return &extProcPb.ProcessingResponse{
 		Response: &extProcPb.ProcessingResponse_RequestBody{
 			RequestBody: &extProcPb.BodyResponse{
 				Response: &extProcPb.CommonResponse{
 					BodyMutation: &extProcPb.BodyMutation{
 						Mutation: &extProcPb.BodyMutation_StreamedResponse{
 							StreamedResponse: &extProcPb.StreamedBodyResponse{
-								Body:        requestBodyBytes,
-								EndOfStream: true,
+								Body:        body,
+								EndOfStream: endOfStream,
 							},
 						},
 					},
@@ -126,14 +219,94 @@ func (s *StreamingServer) HandleRequestBody(
 			},
 		},
 	}
-	return reqCtx, nil
+}
+
+// headerValue returns the value of a header, which Envoy sets in either the raw or the string value.
+func headerValue(header *configPb.HeaderValue) string {
+	if len(header.RawValue) > 0 {
+		return string(header.RawValue)
+	}
+	return header.Value
+}
+
+// injectStreamUsage asks the model server to report token usage at the end of a streamed response
+// by setting "stream_options.include_usage". It returns true if the option was injected, meaning the
+// client didn't ask for the usage and the usage-only event must be removed from the response.
+func injectStreamUsage(requestBodyMap map[string]interface{}) bool {
+	if stream, _ := requestBodyMap["stream"].(bool); !stream {
+		return false
+	}
+	options := map[string]interface{}{}
+	if v, ok := requestBodyMap["stream_options"]; ok && v != nil {
+		if options, ok = v.(map[string]interface{}); !ok {
+			// Leave malformed options for the model server to reject.
+			return false
+		}
+	}
+	if includeUsage, _ := options["include_usage"].(bool); includeUsage {
+		return false
+	}
+	options["include_usage"] = true
+	requestBodyMap["stream_options"] = options
+	return true
+}
+
+// resolveInferenceModel returns the InferenceModel serving the model, applying the unregistered
+// model policy to models without one. A nil InferenceModel passes the request through as sheddable.
+func (s *StreamingServer) resolveInferenceModel(ctx context.Context, model string) (*v1alpha2.InferenceModel, error) {
+	if modelObj := s.datastore.ModelResolve(model); modelObj != nil {
+		return modelObj, nil
+	}
+
+	policy := s.config.UnregisteredModelPolicy
+	if policy == "" {
+		policy = UnregisteredModelReject
+	}
+	metrics.RecordUnregisteredModelRequest(model, string(policy))
+	log.FromContext(ctx).V(logutil.DEBUG).Info("Request for unregistered model", "model", model, "policy", policy)
+
+	switch policy {
+	case UnregisteredModelPassthrough:
+		return nil, nil
+	case UnregisteredModelDefault:
+		if modelObj := s.datastore.ModelGet(s.config.DefaultModelName); modelObj != nil {
+			return modelObj, nil
+		}
+		return nil, errutil.Error{Code: errutil.BadConfiguration, Msg: fmt.Sprintf("error finding the default InferenceModel %v for input %v", s.config.DefaultModelName, model)}
+	default:
+		return nil, errutil.Error{Code: errutil.BadConfiguration, Msg: fmt.Sprintf("error finding a model object in InferenceModel for input %v", model)}
+	}
 }
 
 func (s *StreamingServer) HandleRequestHeaders(ctx context.Context, reqCtx *RequestContext, req *extProcPb.ProcessingRequest_RequestHeaders) error {
 	reqCtx.RequestReceivedTimestamp = time.Now()
+	method := ""
+	model := ""
+	for _, header := range req.RequestHeaders.GetHeaders().GetHeaders() {
+		switch {
+		case header.Key == ":path":
+			reqCtx.RequestPath = requestPath(headerValue(header))
+		case header.Key == ":method":
+			method = headerValue(header)
+		case s.config.ModelNameHeader != "" && strings.EqualFold(header.Key, s.config.ModelNameHeader):
+			model = headerValue(header)
+		}
+	}
+
+	if !req.RequestHeaders.EndOfStream && model != "" {
+		_, err := s.scheduleFromHeader(ctx, reqCtx, model)
+		return err
+	}
 
 	// an EoS in the request headers means this request has no body or trailers.
 	if req.RequestHeaders.EndOfStream {
+		// The models API lists the InferenceModels rather than the models of a random pod.
+		if method == http.MethodGet {
+			if resp := s.modelsResponse(reqCtx.RequestPath); resp != nil {
+				reqCtx.reqHeaderResp = resp
+				return nil
+			}
+		}
 		// We will route this request to a random pod as this is assumed to just be a GET
 		// More context: https://github.com/kubernetes-sigs/gateway-api-inference-extension/pull/526
 		// The above PR will address endpoint admission, but currently any request without a body will be
@@ -0,0 +1,381 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+	backendmetrics "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/backend/metrics"
+	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/datastore"
+	schedulingtypes "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/scheduling/types"
+	errutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/error"
+)
+
+func TestInjectStreamUsage(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         map[string]interface{}
+		wantInjected bool
+		wantBody     map[string]interface{}
+	}{
+		{
+			name:     "not streaming",
+			body:     map[string]interface{}{"model": "m"},
+			wantBody: map[string]interface{}{"model": "m"},
+		},
+		{
+			name:     "stream false",
+			body:     map[string]interface{}{"model": "m", "stream": false},
+			wantBody: map[string]interface{}{"model": "m", "stream": false},
+		},
+		{
+			name:         "streaming without options",
+			body:         map[string]interface{}{"model": "m", "stream": true},
+			wantInjected: true,
+			wantBody: map[string]interface{}{
+				"model":          "m",
+				"stream":         true,
+				"stream_options": map[string]interface{}{"include_usage": true},
+			},
+		},
+		{
+			name: "streaming with other options",
+			body: map[string]interface{}{
+				"stream":         true,
+				"stream_options": map[string]interface{}{"continuous_usage_stats": false, "include_usage": false},
+			},
+			wantInjected: true,
+			wantBody: map[string]interface{}{
+				"stream":         true,
+				"stream_options": map[string]interface{}{"continuous_usage_stats": false, "include_usage": true},
+			},
+		},
+		{
+			name: "client asked for usage",
+			body: map[string]interface{}{
+				"stream":         true,
+				"stream_options": map[string]interface{}{"include_usage": true},
+			},
+			wantBody: map[string]interface{}{
+				"stream":         true,
+				"stream_options": map[string]interface{}{"include_usage": true},
+			},
+		},
+		{
+			name:     "malformed options",
+			body:     map[string]interface{}{"stream": true, "stream_options": "yes"},
+			wantBody: map[string]interface{}{"stream": true, "stream_options": "yes"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := injectStreamUsage(test.body); got != test.wantInjected {
+				t.Errorf("injectStreamUsage() = %v, want %v", got, test.wantInjected)
+			}
+			if diff := cmp.Diff(test.wantBody, test.body); diff != "" {
+				t.Errorf("injectStreamUsage() produced unexpected body, diff(-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestHandleRequestHeadersPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []*configPb.HeaderValue
+		want    string
+	}{
+		{
+			name:    "raw value",
+			headers: []*configPb.HeaderValue{{Key: ":method", RawValue: []byte("POST")}, {Key: ":path", RawValue: []byte("/v1/chat/completions?x=1")}},
+			want:    ChatCompletionsPath,
+		},
+		{
+			name:    "string value",
+			headers: []*configPb.HeaderValue{{Key: ":path", Value: "/v1/completions"}},
+			want:    CompletionsPath,
+		},
+		{
+			name:    "no path",
+			headers: []*configPb.HeaderValue{{Key: "hi", Value: "mom"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := &StreamingServer{}
+			reqCtx := &RequestContext{}
+			req := &extProcPb.ProcessingRequest_RequestHeaders{
+				RequestHeaders: &extProcPb.HttpHeaders{
+					Headers: &configPb.HeaderMap{Headers: test.headers},
+				},
+			}
+			if err := server.HandleRequestHeaders(context.Background(), reqCtx, req); err != nil {
+				t.Fatalf("HandleRequestHeaders returned unexpected error: %v", err)
+			}
+			if reqCtx.RequestPath != test.want {
+				t.Errorf("RequestPath = %q, want %q", reqCtx.RequestPath, test.want)
+			}
+		})
+	}
+}
+
+func TestResolveInferenceModel(t *testing.T) {
+	pmf := backendmetrics.NewPodMetricsFactory(&backendmetrics.FakePodMetricsClient{}, time.Second)
+	ds := datastore.NewDatastore(context.Background(), pmf)
+	sql := testInferenceModel("sql", "sql-lora", time.Time{}, "sql-lora-v1")
+	base := testInferenceModel("base", "meta-llama/Llama-3.1-8B-Instruct", time.Time{})
+	ds.ModelSetIfOlder(sql)
+	ds.ModelSetIfOlder(base)
+
+	tests := []struct {
+		name     string
+		config   Config
+		model    string
+		want     *v1alpha2.InferenceModel
+		wantCode string
+	}{
+		{
+			name:  "registered",
+			model: "sql-lora",
+			want:  sql,
+		},
+		{
+			name:     "unregistered rejected by default",
+			model:    "unknown",
+			wantCode: errutil.BadConfiguration,
+		},
+		{
+			name:     "unregistered rejected",
+			config:   Config{UnregisteredModelPolicy: UnregisteredModelReject},
+			model:    "unknown",
+			wantCode: errutil.BadConfiguration,
+		},
+		{
+			name:   "unregistered passed through",
+			config: Config{UnregisteredModelPolicy: UnregisteredModelPassthrough},
+			model:  "unknown",
+		},
+		{
+			name:   "registered with passthrough",
+			config: Config{UnregisteredModelPolicy: UnregisteredModelPassthrough},
+			model:  "sql-lora",
+			want:   sql,
+		},
+		{
+			name:   "unregistered mapped to default",
+			config: Config{UnregisteredModelPolicy: UnregisteredModelDefault, DefaultModelName: "meta-llama/Llama-3.1-8B-Instruct"},
+			model:  "unknown",
+			want:   base,
+		},
+		{
+			name:     "default not registered",
+			config:   Config{UnregisteredModelPolicy: UnregisteredModelDefault, DefaultModelName: "missing"},
+			model:    "unknown",
+			wantCode: errutil.BadConfiguration,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := NewStreamingServer(nil, "", "", ds, test.config)
+			got, err := server.resolveInferenceModel(context.Background(), test.model)
+			if test.wantCode != "" {
+				if errutil.CanonicalCode(err) != test.wantCode {
+					t.Fatalf("resolveInferenceModel returned error %v, want code %q", err, test.wantCode)
+				}
+			} else if err != nil {
+				t.Fatalf("resolveInferenceModel returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("resolveInferenceModel returned unexpected InferenceModel, diff(-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestParseUnregisteredModelPolicy(t *testing.T) {
+	for _, policy := range []UnregisteredModelPolicy{UnregisteredModelReject, UnregisteredModelPassthrough, UnregisteredModelDefault} {
+		if got, err := ParseUnregisteredModelPolicy(string(policy)); err != nil || got != policy {
+			t.Errorf("ParseUnregisteredModelPolicy(%q) = %q, %v, want %q", policy, got, err, policy)
+		}
+	}
+	if _, err := ParseUnregisteredModelPolicy("allow"); err == nil {
+		t.Error("ParseUnregisteredModelPolicy(\"allow\") returned no error")
+	}
+}
+
+// fakeScheduler schedules every request on the same pod, or fails with err.
+type fakeScheduler struct {
+	pod *backendmetrics.Pod
+	err error
+}
+
+func (f *fakeScheduler) Schedule(_ context.Context, _ *schedulingtypes.LLMRequest) (schedulingtypes.Pod, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &schedulingtypes.PodMetrics{Pod: f.pod, Metrics: &backendmetrics.Metrics{}}, nil
+}
+
+func newModelHeaderTestServer(t *testing.T, scheduler Scheduler) *StreamingServer {
+	pmf := backendmetrics.NewPodMetricsFactory(&backendmetrics.FakePodMetricsClient{}, time.Second)
+	ds := datastore.NewDatastore(t.Context(), pmf)
+	ds.PoolSet(&v1alpha2.InferencePool{Spec: v1alpha2.InferencePoolSpec{TargetPortNumber: 8000}})
+	ds.ModelSetIfOlder(testInferenceModel("llama", "llama-3", time.Time{}))
+	ds.ModelSetIfOlder(testInferenceModel("sql", "sql-lora", time.Time{}, "sql-lora-v1"))
+	return NewStreamingServer(scheduler, "", "x-gateway-destination-endpoint", ds, Config{ModelNameHeader: "X-Gateway-Model-Name"})
+}
+
+func TestHandleRequestHeadersModelName(t *testing.T) {
+	pod := &backendmetrics.Pod{Address: "10.0.0.1"}
+	tests := []struct {
+		name            string
+		headers         []*configPb.HeaderValue
+		schedulerErr    error
+		wantPassthrough bool
+		wantEndpoint    string
+		wantHeaderModel string
+		wantCode        string
+	}{
+		{
+			name: "scheduled from the header",
+			headers: []*configPb.HeaderValue{
+				{Key: ":path", RawValue: []byte("/v1/completions")},
+				{Key: "x-gateway-model-name", RawValue: []byte("llama-3")},
+			},
+			wantPassthrough: true,
+			wantEndpoint:    "10.0.0.1:8000",
+		},
+		{
+			name: "target model rewritten from the body",
+			headers: []*configPb.HeaderValue{
+				{Key: "x-gateway-model-name", RawValue: []byte("sql-lora")},
+			},
+			wantHeaderModel: "sql-lora-v1",
+		},
+		{
+			name: "no header",
+			headers: []*configPb.HeaderValue{
+				{Key: ":path", RawValue: []byte("/v1/completions")},
+			},
+		},
+		{
+			name: "unregistered model",
+			headers: []*configPb.HeaderValue{
+				{Key: "x-gateway-model-name", RawValue: []byte("unknown")},
+			},
+			wantCode: errutil.BadConfiguration,
+		},
+		{
+			name: "no capacity",
+			headers: []*configPb.HeaderValue{
+				{Key: "x-gateway-model-name", RawValue: []byte("llama-3")},
+			},
+			schedulerErr: errors.New("no pods"),
+			wantCode:     errutil.InferencePoolResourceExhausted,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newModelHeaderTestServer(t, &fakeScheduler{pod: pod, err: test.schedulerErr})
+			reqCtx := &RequestContext{}
+			req := &extProcPb.ProcessingRequest_RequestHeaders{
+				RequestHeaders: &extProcPb.HttpHeaders{
+					Headers: &configPb.HeaderMap{Headers: test.headers},
+				},
+			}
+			err := server.HandleRequestHeaders(context.Background(), reqCtx, req)
+			if test.wantCode != "" {
+				if errutil.CanonicalCode(err) != test.wantCode {
+					t.Fatalf("HandleRequestHeaders returned error %v, want code %q", err, test.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("HandleRequestHeaders returned unexpected error: %v", err)
+			}
+
+			if reqCtx.bodyPassthrough != test.wantPassthrough {
+				t.Errorf("bodyPassthrough = %v, want %v", reqCtx.bodyPassthrough, test.wantPassthrough)
+			}
+			if reqCtx.TargetEndpoint != test.wantEndpoint {
+				t.Errorf("TargetEndpoint = %q, want %q", reqCtx.TargetEndpoint, test.wantEndpoint)
+			}
+			gotHeaderModel := ""
+			if reqCtx.headerRequest != nil {
+				gotHeaderModel = reqCtx.headerRequest.ResolvedTargetModel
+			}
+			if gotHeaderModel != test.wantHeaderModel {
+				t.Errorf("header resolved target model = %q, want %q", gotHeaderModel, test.wantHeaderModel)
+			}
+			if !test.wantPassthrough {
+				if reqCtx.reqHeaderResp != nil {
+					t.Errorf("HandleRequestHeaders responded before the body: %v", reqCtx.reqHeaderResp)
+				}
+				return
+			}
+			// The body isn't mutated, so the Content-Length must be left untouched.
+			for _, h := range reqCtx.reqHeaderResp.GetRequestHeaders().GetResponse().GetHeaderMutation().GetSetHeaders() {
+				if h.Header.Key == "Content-Length" {
+					t.Errorf("HandleRequestHeaders set the Content-Length of a passed through body")
+				}
+			}
+		})
+	}
+}
+
+func TestHandleRequestBodyOtherAPIPaths(t *testing.T) {
+	tests := []struct {
+		path string
+		body string
+	}{
+		{path: "/v1/score", body: `{"model":"sql-lora", "text_1":"a", "text_2":["b","c"]}`},
+		{path: "/tokenize", body: `{"model":"sql-lora", "prompt":"hi"}`},
+		{path: "/generate", body: `{"model":"sql-lora", "prompt":"hi", "sampling_params":{"max_tokens":"16"}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			server := newModelHeaderTestServer(t, &fakeScheduler{pod: &backendmetrics.Pod{Address: "10.0.0.1"}})
+			var bodyMap map[string]interface{}
+			if err := json.Unmarshal([]byte(test.body), &bodyMap); err != nil {
+				t.Fatal(err)
+			}
+
+			reqCtx, err := server.HandleRequestBody(t.Context(), &RequestContext{RequestPath: test.path}, &extProcPb.ProcessingRequest{}, []byte(test.body), bodyMap)
+			if err != nil {
+				t.Fatalf("HandleRequestBody() unexpected error: %v", err)
+			}
+			if reqCtx.TargetEndpoint != "10.0.0.1:8000" {
+				t.Errorf("TargetEndpoint = %q, want %q", reqCtx.TargetEndpoint, "10.0.0.1:8000")
+			}
+			if reqCtx.ResolvedTargetModel != "sql-lora-v1" {
+				t.Errorf("ResolvedTargetModel = %q, want %q", reqCtx.ResolvedTargetModel, "sql-lora-v1")
+			}
+		})
+	}
+}
@@ -19,17 +19,18 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"strings"
+	"time"
 
 	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	backendmetrics "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/backend/metrics"
 	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/metrics"
 	logutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/logging"
 )
 
 const (
-	streamingRespPrefix = "data: "
-	streamingEndMsg     = "data: [DONE]"
+	// streamingEndData is the data of the event terminating an OpenAI-style stream.
+	streamingEndData = "[DONE]"
 )
 
 // HandleResponseBody always returns the requestContext even in the error case, as the request context is used in error handling.
@@ -84,60 +85,145 @@ func (s *StreamingServer) HandleResponseBody(
 	return reqCtx, nil
 }
 
-// The function is to handle streaming response if the modelServer is streaming.
+// HandleResponseLoadReport updates the metrics of the target pod from the ORCA load report the model
+// server attached to its response, so that the scheduler doesn't have to wait for the next scrape.
+func (s *StreamingServer) HandleResponseLoadReport(ctx context.Context, reqCtx *RequestContext, header, value string) {
+	logger := log.FromContext(ctx)
+	report, err := backendmetrics.ParseORCALoadReport(header, value)
+	if err != nil {
+		logger.V(logutil.DEBUG).Error(err, "Failed to parse load report", "pod", reqCtx.TargetPod)
+		return
+	}
+	pod := s.datastore.PodGet(reqCtx.targetPodName)
+	if pod == nil {
+		// The pod left the pool since it was picked.
+		return
+	}
+	pod.UpdateLoadReport(report)
+}
+
+// HandleResponseBodyModelStreaming decodes the server-sent events of a streamed response body
+// chunk and reports whether any of them carried generated tokens. Events may span chunks; the
+// decoder state lives on the request context. At the end of the stream the token usage is
+// recorded, or, when the model server did not report usage, the number of streamed token events
+// as an estimate of the output tokens.
+//
+// It returns the body to forward to the client: the chunk itself, or, when the EPP injected
+// "include_usage" into the request, the complete events received so far minus the usage-only one.
 func (s *StreamingServer) HandleResponseBodyModelStreaming(
 	ctx context.Context,
 	reqCtx *RequestContext,
-	responseText string,
-) {
-	if strings.Contains(responseText, streamingEndMsg) {
-		resp := parseRespForUsage(ctx, responseText)
-		reqCtx.Usage = resp.Usage
-		metrics.RecordInputTokens(reqCtx.Model, reqCtx.ResolvedTargetModel, resp.Usage.PromptTokens)
-		metrics.RecordOutputTokens(reqCtx.Model, reqCtx.ResolvedTargetModel, resp.Usage.CompletionTokens)
+	body []byte,
+	endOfStream bool,
+) ([]byte, bool) {
+	forward := body
+	if reqCtx.stripStreamUsage {
+		forward = []byte{}
+	}
+	carriesTokens := false
+	for _, event := range reqCtx.sse.Decode(body, endOfStream) {
+		tokens, usageOnly := handleStreamedEvent(ctx, reqCtx, event)
+		if tokens {
+			carriesTokens = true
+		}
+		if reqCtx.stripStreamUsage && !usageOnly {
+			forward = append(forward, event.raw...)
+		}
+	}
+	if endOfStream {
+		if reqCtx.stripStreamUsage {
+			forward = append(forward, reqCtx.sse.takeRaw()...)
+		}
+		metrics.RecordInputTokens(reqCtx.Model, reqCtx.ResolvedTargetModel, reqCtx.Usage.PromptTokens)
+		if reqCtx.Usage.CompletionTokens > 0 {
+			metrics.RecordOutputTokens(reqCtx.Model, reqCtx.ResolvedTargetModel, reqCtx.Usage.CompletionTokens)
+		} else {
+			// Token-bearing events may carry several tokens, so their count is only recorded as an estimate.
+			metrics.RecordEstimatedOutputTokens(reqCtx.Model, reqCtx.ResolvedTargetModel, reqCtx.StreamedTokens)
+		}
 	}
+	return forward, carriesTokens
 }
 
-// Example message if "stream_options": {"include_usage": "true"} is included in the request:
+// handleStreamedEvent records the usage, finish reasons and generated tokens of a single streamed
+// event. It reports whether the event carried tokens and whether it only carried the usage.
+//
+// Example events if "stream_options": {"include_usage": true} is included in the request:
+// data: {"id":"...","object":"text_completion","created":1739400043,"model":"food-review-0","choices":[{"index":0,"text":" Hi","finish_reason":null}],"usage":null}
+//
 // data: {"id":"...","object":"text_completion","created":1739400043,"model":"food-review-0","choices":[],
 // "usage":{"prompt_tokens":7,"total_tokens":17,"completion_tokens":10}}
 //
 // data: [DONE]
-//
-// Noticed that vLLM returns two entries in one response.
-// We need to strip the `data:` prefix and next Data: [DONE] from the message to fetch response data.
-//
-// If include_usage is not included in the request, `data: [DONE]` is returned separately, which
-// indicates end of streaming.
-func parseRespForUsage(
-	ctx context.Context,
-	responseText string,
-) Response {
-	response := Response{}
-	logger := log.FromContext(ctx)
-
-	lines := strings.Split(responseText, "\n")
-	for _, line := range lines {
-		if !strings.HasPrefix(line, streamingRespPrefix) {
-			continue
+func handleStreamedEvent(ctx context.Context, reqCtx *RequestContext, event sseEvent) (bool, bool) {
+	if event.Data == streamingEndData {
+		return false, false
+	}
+	chunk := streamedChunk{}
+	if err := json.Unmarshal([]byte(event.Data), &chunk); err != nil {
+		log.FromContext(ctx).V(logutil.DEFAULT).Error(err, "Error unmarshaling streamed response event")
+		return false, false
+	}
+	if chunk.Usage != nil {
+		reqCtx.Usage = *chunk.Usage
+	}
+	carriesTokens := false
+	for _, choice := range chunk.Choices {
+		if choice.FinishReason != nil && *choice.FinishReason != "" {
+			reqCtx.FinishReasons = append(reqCtx.FinishReasons, *choice.FinishReason)
 		}
-		content := strings.TrimPrefix(line, streamingRespPrefix)
-		if content == "[DONE]" {
-			continue
+		if choice.Text != "" || choice.Delta.Content != "" || choice.Delta.ReasoningContent != "" || len(choice.Delta.ToolCalls) > 0 {
+			reqCtx.StreamedTokens++
+			carriesTokens = true
 		}
+	}
+	return carriesTokens, chunk.Usage != nil && len(chunk.Choices) == 0
+}
 
-		byteSlice := []byte(content)
-		if err := json.Unmarshal(byteSlice, &response); err != nil {
-			logger.Error(err, "unmarshaling response body")
-			continue
-		}
+// recordResponseChunkLatencies timestamps a response body chunk received at the given time. The
+// first chunk records the time to first token; later chunks carrying streamed tokens record the
+// latency since the previous token-bearing chunk.
+func recordResponseChunkLatencies(ctx context.Context, reqCtx *RequestContext, received time.Time, carriesTokens bool) {
+	if reqCtx.FirstTokenTimestamp.IsZero() {
+		reqCtx.FirstTokenTimestamp = received
+		reqCtx.LastTokenTimestamp = received
+		metrics.RecordTimeToFirstToken(ctx, reqCtx.Model, reqCtx.ResolvedTargetModel, reqCtx.RequestReceivedTimestamp, received)
+		return
 	}
+	if !carriesTokens {
+		return
+	}
+	metrics.RecordInterTokenLatency(ctx, reqCtx.Model, reqCtx.ResolvedTargetModel, reqCtx.LastTokenTimestamp, received)
+	reqCtx.LastTokenTimestamp = received
+}
+
+// recordNormalizedTimePerOutputToken records the NTPOT of a completed response. Responses without
+// usage, such as streams requested without "include_usage", are skipped rather than normalized by
+// the estimated output tokens.
+func recordNormalizedTimePerOutputToken(ctx context.Context, reqCtx *RequestContext) {
+	if reqCtx.Usage.CompletionTokens <= 0 {
+		return
+	}
+	metrics.RecordNormalizedTimePerOutputToken(ctx, reqCtx.Model, reqCtx.ResolvedTargetModel,
+		reqCtx.RequestReceivedTimestamp, reqCtx.ResponseCompleteTimestamp, reqCtx.Usage.CompletionTokens)
+}
 
-	return response
+// streamedChunk is the subset of a streamed completion or chat completion chunk inspected by the EPP.
+type streamedChunk struct {
+	Choices []streamedChoice `json:"choices"`
+	Usage   *Usage           `json:"usage"`
 }
 
-type Response struct {
-	Usage Usage `json:"usage"`
+type streamedChoice struct {
+	// Text is set by the completions API.
+	Text string `json:"text"`
+	// Delta is set by the chat completions API.
+	Delta struct {
+		Content          string            `json:"content"`
+		ReasoningContent string            `json:"reasoning_content"`
+		ToolCalls        []json.RawMessage `json:"tool_calls"`
+	} `json:"delta"`
+	FinishReason *string `json:"finish_reason"`
 }
 
 type Usage struct {
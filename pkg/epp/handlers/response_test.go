@@ -19,7 +19,9 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	logutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/logging"
@@ -51,11 +53,30 @@ const (
 	`
 
 	streamingBodyWithoutUsage = `data: {"id":"cmpl-41764c93-f9d2-4f31-be08-3ba04fa25394","object":"text_completion","created":1740002445,"model":"food-review-0","choices":[],"usage":null}
-	`
+
+`
 
 	streamingBodyWithUsage = `data: {"id":"cmpl-41764c93-f9d2-4f31-be08-3ba04fa25394","object":"text_completion","created":1740002445,"model":"food-review-0","choices":[],"usage":{"prompt_tokens":7,"total_tokens":17,"completion_tokens":10}}
+
 data: [DONE]
-	`
+
+`
+
+	streamingBodyTokens = `data: {"id":"cmpl-1","object":"text_completion","created":1740002445,"model":"food-review-0","choices":[{"index":0,"text":" Hello","finish_reason":null}],"usage":null}
+
+data: {"id":"cmpl-1","object":"text_completion","created":1740002445,"model":"food-review-0","choices":[{"index":0,"text":" world","finish_reason":"length"}],"usage":null}
+
+`
+
+	streamingBodyChatTokens = `data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1740002445,"model":"food-review-0","choices":[{"index":0,"delta":{"role":"assistant","content":""},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1740002445,"model":"food-review-0","choices":[{"index":0,"delta":{"content":"Hi"},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1740002445,"model":"food-review-0","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{}"}}]},"finish_reason":"tool_calls"}]}
+
+data: [DONE]
+
+`
 )
 
 func TestHandleResponseBody(t *testing.T) {
@@ -109,48 +130,148 @@ func TestHandleResponseBody(t *testing.T) {
 func TestHandleStreamedResponseBody(t *testing.T) {
 	ctx := logutil.NewTestLoggerIntoContext(context.Background())
 	tests := []struct {
-		name    string
-		body    string
-		reqCtx  *RequestContext
-		want    Usage
-		wantErr bool
+		name               string
+		chunks             []string
+		stripStreamUsage   bool
+		want               Usage
+		wantStreamedTokens int
+		wantFinishReasons  []string
+		wantTokenChunks    []bool
+		wantBody           string
 	}{
 		{
-			name: "streaming request without usage",
-			body: streamingBodyWithoutUsage,
-			reqCtx: &RequestContext{
-				modelServerStreaming: true,
+			name:            "streaming request without usage",
+			chunks:          []string{streamingBodyWithoutUsage},
+			wantTokenChunks: []bool{false},
+			// No usage was reported and no tokens were streamed.
+		},
+		{
+			name:   "streaming request with usage",
+			chunks: []string{streamingBodyWithUsage},
+			want: Usage{
+				PromptTokens:     7,
+				TotalTokens:      17,
+				CompletionTokens: 10,
+			},
+			wantTokenChunks: []bool{false},
+		},
+		{
+			name: "usage event split across chunks",
+			chunks: []string{
+				streamingBodyWithUsage[:40],
+				streamingBodyWithUsage[40:170],
+				streamingBodyWithUsage[170:],
+			},
+			want: Usage{
+				PromptTokens:     7,
+				TotalTokens:      17,
+				CompletionTokens: 10,
 			},
-			wantErr: false,
-			// In the middle of streaming response, so request context response is not set yet.
+			wantTokenChunks: []bool{false, false, false},
+		},
+		{
+			name:               "tokens counted without usage",
+			chunks:             []string{streamingBodyTokens[:50], streamingBodyTokens[50:], "data: [DONE]\n\n"},
+			wantStreamedTokens: 2,
+			wantFinishReasons:  []string{"length"},
+			wantTokenChunks:    []bool{false, true, false},
+		},
+		{
+			name:               "chat completion chunks",
+			chunks:             []string{streamingBodyChatTokens},
+			wantStreamedTokens: 2,
+			wantFinishReasons:  []string{"tool_calls"},
+			wantTokenChunks:    []bool{true},
 		},
 		{
-			name: "streaming request with usage",
-			body: streamingBodyWithUsage,
-			reqCtx: &RequestContext{
-				modelServerStreaming: true,
+			name: "injected usage stripped",
+			chunks: []string{
+				streamingBodyTokens[:50],
+				streamingBodyTokens[50:] + streamingBodyWithUsage[:60],
+				streamingBodyWithUsage[60:] + ": bye\n",
 			},
-			wantErr: false,
+			stripStreamUsage: true,
 			want: Usage{
 				PromptTokens:     7,
 				TotalTokens:      17,
 				CompletionTokens: 10,
 			},
+			wantStreamedTokens: 2,
+			wantFinishReasons:  []string{"length"},
+			wantTokenChunks:    []bool{false, true, false},
+			wantBody:           streamingBodyTokens + "data: [DONE]\n\n: bye\n",
+		},
+		{
+			name:            "malformed event",
+			chunks:          []string{"data: {\"choices\":\n\n", streamingBodyWithUsage},
+			want:            Usage{PromptTokens: 7, TotalTokens: 17, CompletionTokens: 10},
+			wantTokenChunks: []bool{false, false},
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			server := &StreamingServer{}
-			reqCtx := test.reqCtx
-			if reqCtx == nil {
-				reqCtx = &RequestContext{}
+			reqCtx := &RequestContext{modelServerStreaming: true, stripStreamUsage: test.stripStreamUsage}
+			var gotTokenChunks []bool
+			var gotBody []byte
+			for i, chunk := range test.chunks {
+				endOfStream := i == len(test.chunks)-1
+				body, carriesTokens := server.HandleResponseBodyModelStreaming(ctx, reqCtx, []byte(chunk), endOfStream)
+				gotBody = append(gotBody, body...)
+				gotTokenChunks = append(gotTokenChunks, carriesTokens)
 			}
-			server.HandleResponseBodyModelStreaming(ctx, reqCtx, test.body)
 
 			if diff := cmp.Diff(test.want, reqCtx.Usage); diff != "" {
-				t.Errorf("HandleResponseBody returned unexpected response, diff(-want, +got): %v", diff)
+				t.Errorf("HandleResponseBodyModelStreaming returned unexpected usage, diff(-want, +got): %v", diff)
+			}
+			if reqCtx.StreamedTokens != test.wantStreamedTokens {
+				t.Errorf("HandleResponseBodyModelStreaming counted %d streamed tokens, want %d", reqCtx.StreamedTokens, test.wantStreamedTokens)
+			}
+			if diff := cmp.Diff(test.wantFinishReasons, reqCtx.FinishReasons); diff != "" {
+				t.Errorf("HandleResponseBodyModelStreaming returned unexpected finish reasons, diff(-want, +got): %v", diff)
+			}
+			if diff := cmp.Diff(test.wantTokenChunks, gotTokenChunks); diff != "" {
+				t.Errorf("HandleResponseBodyModelStreaming returned unexpected token chunks, diff(-want, +got): %v", diff)
+			}
+			wantBody := test.wantBody
+			if !test.stripStreamUsage {
+				wantBody = strings.Join(test.chunks, "")
+			}
+			if diff := cmp.Diff(wantBody, string(gotBody)); diff != "" {
+				t.Errorf("HandleResponseBodyModelStreaming returned unexpected body, diff(-want, +got): %v", diff)
 			}
 		})
 	}
 }
+
+func TestRecordResponseChunkLatencies(t *testing.T) {
+	ctx := logutil.NewTestLoggerIntoContext(context.Background())
+	received := time.Now()
+	reqCtx := &RequestContext{
+		Model:                    "m",
+		ResolvedTargetModel:      "t",
+		RequestReceivedTimestamp: received,
+	}
+
+	first := received.Add(100 * time.Millisecond)
+	recordResponseChunkLatencies(ctx, reqCtx, first, true)
+	if !reqCtx.FirstTokenTimestamp.Equal(first) || !reqCtx.LastTokenTimestamp.Equal(first) {
+		t.Fatalf("first chunk timestamps = (%v, %v), want %v", reqCtx.FirstTokenTimestamp, reqCtx.LastTokenTimestamp, first)
+	}
+
+	// Chunks without tokens don't advance the last token timestamp.
+	recordResponseChunkLatencies(ctx, reqCtx, first.Add(10*time.Millisecond), false)
+	if !reqCtx.LastTokenTimestamp.Equal(first) {
+		t.Errorf("LastTokenTimestamp = %v after a chunk without tokens, want %v", reqCtx.LastTokenTimestamp, first)
+	}
+
+	next := first.Add(20 * time.Millisecond)
+	recordResponseChunkLatencies(ctx, reqCtx, next, true)
+	if !reqCtx.FirstTokenTimestamp.Equal(first) {
+		t.Errorf("FirstTokenTimestamp = %v, want %v", reqCtx.FirstTokenTimestamp, first)
+	}
+	if !reqCtx.LastTokenTimestamp.Equal(next) {
+		t.Errorf("LastTokenTimestamp = %v, want %v", reqCtx.LastTokenTimestamp, next)
+	}
+}
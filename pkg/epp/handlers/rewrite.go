@@ -0,0 +1,244 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var errNotObject = errors.New("request body is not a JSON object")
+
+// rewriteTopLevelFields sets the given fields of the JSON object in body and removes the dropped
+// ones. The values of the existing fields are replaced, and the other fields are inserted at the
+// beginning of the object. The rest of the body is preserved byte for byte, unlike a round trip
+// through a map which reorders the fields, reformats the numbers and costs a full re-encode of the
+// prompt. Every occurrence of a duplicated field is rewritten, since parsers disagree on which one
+// wins. A field both set and dropped is set.
+//
+// The body must already be valid JSON, e.g. as checked by the json.Unmarshal of the request body:
+// only the top-level structure is parsed, and the values are scanned for their end without being
+// validated, so a malformed value is copied as is.
+func rewriteTopLevelFields(body []byte, set map[string]interface{}, drop []string) ([]byte, error) {
+	values := make(map[string][]byte, len(set))
+	for name, value := range set {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("encoding field %q: %w", name, err)
+		}
+		values[name] = encoded
+	}
+	dropped := make(map[string]bool, len(drop))
+	for _, name := range drop {
+		if _, ok := values[name]; !ok {
+			dropped[name] = true
+		}
+	}
+
+	// Locate the members of the object, skipping over their values without decoding them.
+	type member struct {
+		keyStart, valueStart, valueEnd int
+		name                           string
+	}
+	var members []member
+	i := skipSpace(body, 0)
+	if i >= len(body) || body[i] != '{' {
+		return nil, errNotObject
+	}
+	objectStart := i + 1
+	for i = skipSpace(body, objectStart); ; {
+		if i < len(body) && body[i] == '}' && len(members) == 0 {
+			break
+		}
+		keyEnd, err := skipString(body, i)
+		if err != nil {
+			return nil, err
+		}
+		name, err := objectKey(body[i:keyEnd])
+		if err != nil {
+			return nil, err
+		}
+		colon := skipSpace(body, keyEnd)
+		if colon >= len(body) || body[colon] != ':' {
+			return nil, errNotObject
+		}
+		valueStart := skipSpace(body, colon+1)
+		valueEnd, err := skipValue(body, valueStart)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member{keyStart: i, valueStart: valueStart, valueEnd: valueEnd, name: name})
+
+		i = skipSpace(body, valueEnd)
+		if i < len(body) && body[i] == ',' {
+			i = skipSpace(body, i+1)
+			continue
+		}
+		if i >= len(body) || body[i] != '}' {
+			return nil, errNotObject
+		}
+		break
+	}
+
+	found := make(map[string]bool, len(values))
+	kept := 0
+	for _, m := range members {
+		found[m.name] = true
+		if !dropped[m.name] {
+			kept++
+		}
+	}
+	var inserted []string
+	for name := range values {
+		if !found[name] {
+			inserted = append(inserted, name)
+		}
+	}
+	sort.Strings(inserted)
+
+	var out bytes.Buffer
+	out.Grow(len(body) + 64)
+	out.Write(body[:objectStart])
+	for i, name := range inserted {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		encodedName, _ := json.Marshal(name)
+		out.Write(encodedName)
+		out.WriteByte(':')
+		out.Write(values[name])
+	}
+	if len(inserted) > 0 && kept > 0 {
+		out.WriteByte(',')
+	}
+	// The kept members are written with the separator that preceded them, so that dropping a member
+	// drops its comma.
+	written := 0
+	for i, m := range members {
+		if dropped[m.name] {
+			continue
+		}
+		if written == 0 {
+			out.Write(body[objectStart:members[0].keyStart])
+		} else {
+			out.Write(body[members[i-1].valueEnd:m.keyStart])
+		}
+		if value, ok := values[m.name]; ok {
+			out.Write(body[m.keyStart:m.valueStart])
+			out.Write(value)
+		} else {
+			out.Write(body[m.keyStart:m.valueEnd])
+		}
+		written++
+	}
+	last := objectStart
+	if len(members) > 0 {
+		last = members[len(members)-1].valueEnd
+	}
+	out.Write(body[last:])
+	return out.Bytes(), nil
+}
+
+// objectKey returns the name of the quoted object key, only unquoting keys with escapes.
+func objectKey(quoted []byte) (string, error) {
+	if bytes.IndexByte(quoted, '\\') < 0 {
+		return string(quoted[1 : len(quoted)-1]), nil
+	}
+	var name string
+	if err := json.Unmarshal(quoted, &name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func skipSpace(body []byte, i int) int {
+	for i < len(body) {
+		switch body[i] {
+		case ' ', '\t', '\r', '\n':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// skipString returns the offset following the string starting at body[i].
+func skipString(body []byte, i int) (int, error) {
+	if i >= len(body) || body[i] != '"' {
+		return 0, errNotObject
+	}
+	for i++; i < len(body); i++ {
+		switch body[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, nil
+		}
+	}
+	return 0, errNotObject
+}
+
+// skipValue returns the offset following the value starting at body[i]. The body is expected to
+// have been validated by the caller, so the value is only scanned for its end.
+func skipValue(body []byte, i int) (int, error) {
+	if i >= len(body) {
+		return 0, errNotObject
+	}
+	switch body[i] {
+	case '"':
+		return skipString(body, i)
+	case '{', '[':
+		depth := 0
+		for i < len(body) {
+			switch body[i] {
+			case '"':
+				end, err := skipString(body, i)
+				if err != nil {
+					return 0, err
+				}
+				i = end
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return i + 1, nil
+				}
+			}
+			i++
+		}
+		return 0, errNotObject
+	default:
+		start := i
+		for i < len(body) {
+			switch body[i] {
+			case ',', '}', ']', ' ', '\t', '\r', '\n':
+				if i == start {
+					return 0, errNotObject
+				}
+				return i, nil
+			}
+			i++
+		}
+		return 0, errNotObject
+	}
+}
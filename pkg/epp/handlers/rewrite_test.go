@@ -0,0 +1,203 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRewriteTopLevelFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		set     map[string]interface{}
+		drop    []string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "replace model preserving the rest of the body",
+			body: `{"prompt":"hi","model":"food-review","temperature":1.0,"top_p":1e-1}`,
+			set:  map[string]interface{}{"model": "food-review-1"},
+			want: `{"prompt":"hi","model":"food-review-1","temperature":1.0,"top_p":1e-1}`,
+		},
+		{
+			name: "preserve whitespace",
+			body: "{\n  \"model\" : \"food-review\" ,\n  \"max_tokens\": 10\n}\n",
+			set:  map[string]interface{}{"model": "food-review-1"},
+			want: "{\n  \"model\" : \"food-review-1\" ,\n  \"max_tokens\": 10\n}\n",
+		},
+		{
+			name: "nested model is left alone",
+			body: `{"metadata":{"model":"other"},"model":"food-review"}`,
+			set:  map[string]interface{}{"model": "food-review-1"},
+			want: `{"metadata":{"model":"other"},"model":"food-review-1"}`,
+		},
+		{
+			name: "duplicate fields are all replaced",
+			body: `{"model":"a","prompt":"hi","model":"b"}`,
+			set:  map[string]interface{}{"model": "food-review-1"},
+			want: `{"model":"food-review-1","prompt":"hi","model":"food-review-1"}`,
+		},
+		{
+			name: "duplicate fields with escaped names are all replaced",
+			body: `{"model":"a","mod\u0065l":"b","prompt":"hi"}`,
+			set:  map[string]interface{}{"model": "food-review-1"},
+			want: `{"model":"food-review-1","mod\u0065l":"food-review-1","prompt":"hi"}`,
+		},
+		{
+			name: "duplicate fields are all dropped",
+			body: `{"model":"a","user":"u","prompt":"hi","user":"v"}`,
+			drop: []string{"user"},
+			want: `{"model":"a","prompt":"hi"}`,
+		},
+		{
+			name: "missing fields are inserted",
+			body: `{"model":"food-review","stream":true}`,
+			set: map[string]interface{}{
+				"model":          "food-review-1",
+				"stream_options": map[string]interface{}{"include_usage": true},
+			},
+			want: `{"stream_options":{"include_usage":true},"model":"food-review-1","stream":true}`,
+		},
+		{
+			name: "insert into an empty object",
+			body: `{}`,
+			set:  map[string]interface{}{"b": 1, "a": 2},
+			want: `{"a":2,"b":1}`,
+		},
+		{
+			name: "non-string value replaced",
+			body: `{"model":["a","b"],"n":1}`,
+			set:  map[string]interface{}{"model": "food-review-1"},
+			want: `{"model":"food-review-1","n":1}`,
+		},
+		{
+			name: "drop fields",
+			body: "{\n  \"model\": \"food-review\",\n  \"logprobs\": true,\n  \"prompt\": \"hi\",\n  \"user\": \"u\"\n}",
+			set:  map[string]interface{}{"model": "food-review-1"},
+			drop: []string{"logprobs", "user"},
+			want: "{\n  \"model\": \"food-review-1\",\n  \"prompt\": \"hi\"\n}",
+		},
+		{
+			name: "drop the first field",
+			body: `{"user":"u","prompt":"hi","user":"v"}`,
+			drop: []string{"user"},
+			want: `{"prompt":"hi"}`,
+		},
+		{
+			name: "drop all fields and insert one",
+			body: `{"user":"u"}`,
+			set:  map[string]interface{}{"max_tokens": 10},
+			drop: []string{"user", "missing"},
+			want: `{"max_tokens":10}`,
+		},
+		{
+			name: "set wins over drop",
+			body: `{"temperature":2,"prompt":"hi"}`,
+			set:  map[string]interface{}{"temperature": 1},
+			drop: []string{"temperature"},
+			want: `{"temperature":1,"prompt":"hi"}`,
+		},
+		{
+			name:    "not an object",
+			body:    `["model"]`,
+			set:     map[string]interface{}{"model": "food-review-1"},
+			wantErr: true,
+		},
+		{
+			name:    "truncated object",
+			body:    `{"model":"food-review",`,
+			set:     map[string]interface{}{"model": "food-review-1"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := rewriteTopLevelFields([]byte(test.body), test.set, test.drop)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("rewriteTopLevelFields() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if string(got) != test.want {
+				t.Errorf("rewriteTopLevelFields() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRewriteTopLevelFieldsDuplicateDecoded(t *testing.T) {
+	// Whichever duplicate a parser keeps, it gets the rewritten value: encoding/json keeps the last.
+	body := `{"model":"a","prompt":"hi","model":"b"}`
+	got, err := rewriteTopLevelFields([]byte(body), map[string]interface{}{"model": "food-review-1"}, nil)
+	if err != nil {
+		t.Fatalf("rewriteTopLevelFields() unexpected error: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(got, &decoded); err != nil {
+		t.Fatalf("rewritten body is not valid JSON: %v", err)
+	}
+	if decoded["model"] != "food-review-1" {
+		t.Errorf("decoded model = %v, want %q", decoded["model"], "food-review-1")
+	}
+}
+
+func BenchmarkRewriteModel(b *testing.B) {
+	for _, size := range []int{1 << 10, 16 << 10, 256 << 10, 1 << 20} {
+		body, err := json.Marshal(map[string]interface{}{
+			"model":       "food-review",
+			"prompt":      strings.Repeat("a", size),
+			"max_tokens":  100,
+			"temperature": 0.7,
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		// The body is decoded to parse the request either way, so only the re-encoding is measured.
+		var requestBodyMap map[string]interface{}
+		if err := json.Unmarshal(body, &requestBodyMap); err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(fmt.Sprintf("marshal/%dKB", size>>10), func(b *testing.B) {
+			b.SetBytes(int64(len(body)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				requestBodyMap["model"] = "food-review-1"
+				if _, err := json.Marshal(requestBodyMap); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("rewrite/%dKB", size>>10), func(b *testing.B) {
+			b.SetBytes(int64(len(body)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := rewriteTopLevelFields(body, map[string]interface{}{"model": "food-review-1"}, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
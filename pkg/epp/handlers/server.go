@@ -19,7 +19,9 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
+	"math"
 	"math/rand"
 	"strconv"
 	"strings"
@@ -32,6 +34,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
 	backendmetrics "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/backend/metrics"
@@ -42,15 +45,62 @@ import (
 	logutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/logging"
 )
 
-func NewStreamingServer(scheduler Scheduler, destinationEndpointHintMetadataNamespace, destinationEndpointHintKey string, datastore datastore.Datastore) *StreamingServer {
+func NewStreamingServer(scheduler Scheduler, destinationEndpointHintMetadataNamespace, destinationEndpointHintKey string, datastore datastore.Datastore, config Config) *StreamingServer {
 	return &StreamingServer{
 		scheduler:                                scheduler,
 		destinationEndpointHintMetadataNamespace: destinationEndpointHintMetadataNamespace,
 		destinationEndpointHintKey:               destinationEndpointHintKey,
 		datastore:                                datastore,
+		config:                                   config,
 	}
 }
 
+// Config holds the optional behaviors of the StreamingServer.
+type Config struct {
+	// InjectStreamUsage requests token usage from the model server for streamed requests that
+	// didn't ask for it, hiding the usage from the client. The bodies passed through from the
+	// ModelNameHeader are left unchanged, so their usage is only accounted if the client asked for it.
+	InjectStreamUsage bool
+	// FilterModelsByCapacity restricts the models API to the models with serving capacity.
+	FilterModelsByCapacity bool
+	// UnregisteredModelPolicy selects how requests for models without an InferenceModel are
+	// handled. Defaults to UnregisteredModelReject.
+	UnregisteredModelPolicy UnregisteredModelPolicy
+	// DefaultModelName is the modelName of the InferenceModel serving unregistered models with the
+	// UnregisteredModelDefault policy.
+	DefaultModelName string
+	// ModelNameHeader is the request header holding the model name, if set. Requests with the header
+	// are scheduled before their body is received, and their body is passed through unchanged unless
+	// the target model must be rewritten or the InferenceModel has mutations or limits beyond the
+	// body size. InjectStreamUsage doesn't apply to the passed through bodies.
+	ModelNameHeader string
+	// MaxRequestBodyBytes is the maximum size of a request body, 0 if unlimited. Larger requests are
+	// rejected with a 413 before their body is buffered.
+	MaxRequestBodyBytes int64
+}
+
+// UnregisteredModelPolicy selects how requests for models without an InferenceModel are handled.
+type UnregisteredModelPolicy string
+
+const (
+	// UnregisteredModelReject rejects the requests with a 404.
+	UnregisteredModelReject UnregisteredModelPolicy = "reject"
+	// UnregisteredModelPassthrough routes the requests unchanged as sheddable.
+	UnregisteredModelPassthrough UnregisteredModelPolicy = "passthrough"
+	// UnregisteredModelDefault serves the requests with the default InferenceModel.
+	UnregisteredModelDefault UnregisteredModelPolicy = "default"
+)
+
+// ParseUnregisteredModelPolicy parses an unregistered model policy.
+func ParseUnregisteredModelPolicy(policy string) (UnregisteredModelPolicy, error) {
+	switch p := UnregisteredModelPolicy(policy); p {
+	case UnregisteredModelReject, UnregisteredModelPassthrough, UnregisteredModelDefault:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown unregistered model policy %q, must be %q, %q or %q",
+		policy, UnregisteredModelReject, UnregisteredModelPassthrough, UnregisteredModelDefault)
+}
+
 // Server implements the Envoy external processing server.
 // https://www.envoyproxy.io/docs/envoy/latest/api-v3/service/ext_proc/v3/external_processor.proto
 type StreamingServer struct {
@@ -62,6 +112,7 @@ type StreamingServer struct {
 	// back the picked endpoints.
 	destinationEndpointHintMetadataNamespace string
 	datastore                                datastore.Datastore
+	config                                   Config
 }
 
 type Scheduler interface {
@@ -72,9 +123,12 @@ type Scheduler interface {
 type RequestContext struct {
 	TargetPod                 string
 	TargetEndpoint            string
+	RequestPath               string
 	Model                     string
 	ResolvedTargetModel       string
 	RequestReceivedTimestamp  time.Time
+	FirstTokenTimestamp       time.Time
+	LastTokenTimestamp        time.Time
 	ResponseCompleteTimestamp time.Time
 	RequestSize               int
 	Usage                     Usage
@@ -83,8 +137,29 @@ type RequestContext struct {
 	ResponseStatusCode        string
 	RequestRunning            bool
 
+	// FinishReasons holds the finish reasons of the choices of a streamed response, in arrival order.
+	FinishReasons []string
+	// StreamedTokens counts the token-bearing choices of a streamed response, which estimates its
+	// output tokens when the model server doesn't report the usage.
+	StreamedTokens int
+
 	RequestState         StreamRequestState
 	modelServerStreaming bool
+	sse                  sseDecoder
+	// stripStreamUsage is set when the EPP injected "include_usage" into a streamed request, and
+	// the usage-only event must be removed from the response.
+	stripStreamUsage bool
+	// bodyPassthrough is set when the request was scheduled from the model name header, and its body
+	// chunks are passed through unchanged.
+	bodyPassthrough bool
+	// headerRequest holds the model resolved from the model name header when the body must be
+	// parsed to rewrite the target model.
+	headerRequest *schedulingtypes.LLMRequest
+	// inferenceModel is the InferenceModel serving the request once resolved, nil for requests passed
+	// through without one.
+	inferenceModel *v1alpha2.InferenceModel
+	// targetPodName is the name of the TargetPod, looking it up in the datastore.
+	targetPodName types.NamespacedName
 
 	reqHeaderResp  *extProcPb.ProcessingResponse
 	reqBodyResp    *extProcPb.ProcessingResponse
@@ -161,21 +236,39 @@ func (s *StreamingServer) Process(srv extProcPb.ExternalProcessor_ProcessServer)
 			err = s.HandleRequestHeaders(ctx, reqCtx, v)
 		case *extProcPb.ProcessingRequest_RequestBody:
 			loggerTrace.Info("Incoming body chunk", "EoS", v.RequestBody.EndOfStream)
+			if reqCtx.bodyPassthrough {
+				// The request was scheduled from its headers, forward the chunks as they come.
+				reqCtx.RequestSize += len(v.RequestBody.Body)
+				if err = s.checkBodySize(reqCtx, reqCtx.RequestSize); err != nil {
+					break
+				}
+				reqCtx.reqBodyResp = requestBodyResponse(v.RequestBody.Body, v.RequestBody.EndOfStream)
+				if v.RequestBody.EndOfStream {
+					metrics.RecordRequestCounter(reqCtx.Model, reqCtx.ResolvedTargetModel)
+					metrics.RecordRequestSizes(reqCtx.Model, reqCtx.ResolvedTargetModel, reqCtx.RequestSize)
+				}
+				break
+			}
 			// In the stream case, we can receive multiple request bodies.
+			if err = s.checkBodySize(reqCtx, len(body)+len(v.RequestBody.Body)); err != nil {
+				break
+			}
 			body = append(body, v.RequestBody.Body...)
 
 			// Message is buffered, we can read and decode.
 			if v.RequestBody.EndOfStream {
 				loggerTrace.Info("decoding")
 				err = json.Unmarshal(body, &requestBody)
+				rawRequestBody := body
+				// Body stream complete. Allocate empty slice for response to use.
+				body = []byte{}
 				if err != nil {
 					logger.V(logutil.DEFAULT).Error(err, "Error unmarshaling request body")
+					err = badRequest("malformed request body: %v", err)
+					break
 				}
 
-				// Body stream complete. Allocate empty slice for response to use.
-				body = []byte{}
-
-				reqCtx, err = s.HandleRequestBody(ctx, reqCtx, req, requestBody)
+				reqCtx, err = s.HandleRequestBody(ctx, reqCtx, req, rawRequestBody, requestBody)
 				if err != nil {
 					logger.V(logutil.DEFAULT).Error(err, "Error handling body")
 				} else {
@@ -195,6 +288,8 @@ func (s *StreamingServer) Process(srv extProcPb.ExternalProcessor_ProcessServer)
 				} else if header.Key == "content-type" && strings.Contains(value, "text/event-stream") {
 					reqCtx.modelServerStreaming = true
 					loggerTrace.Info("model server is streaming response")
+				} else if header.Key == backendmetrics.ORCALoadReportHeader || header.Key == backendmetrics.ORCALoadReportBinHeader {
+					s.HandleResponseLoadReport(ctx, reqCtx, header.Key, value)
 				}
 			}
 			reqCtx.RequestState = ResponseRecieved
@@ -219,17 +314,19 @@ func (s *StreamingServer) Process(srv extProcPb.ExternalProcessor_ProcessServer)
 			}
 
 		case *extProcPb.ProcessingRequest_ResponseBody:
+			chunkReceived := time.Now()
 			if reqCtx.modelServerStreaming {
 				// Currently we punt on response parsing if the modelServer is streaming, and we just passthrough.
 
-				responseText := string(v.ResponseBody.Body)
-				s.HandleResponseBodyModelStreaming(ctx, reqCtx, responseText)
+				responseBody, carriesTokens := s.HandleResponseBodyModelStreaming(ctx, reqCtx, v.ResponseBody.Body, v.ResponseBody.EndOfStream)
+				recordResponseChunkLatencies(ctx, reqCtx, chunkReceived, carriesTokens)
 				if v.ResponseBody.EndOfStream {
 					loggerTrace.Info("stream completed")
 
 					reqCtx.ResponseCompleteTimestamp = time.Now()
 					metrics.RecordRequestLatencies(ctx, reqCtx.Model, reqCtx.ResolvedTargetModel, reqCtx.RequestReceivedTimestamp, reqCtx.ResponseCompleteTimestamp)
 					metrics.RecordResponseSizes(reqCtx.Model, reqCtx.ResolvedTargetModel, reqCtx.ResponseSize)
+					recordNormalizedTimePerOutputToken(ctx, reqCtx)
 				}
 
 				reqCtx.respBodyResp = &extProcPb.ProcessingResponse{
@@ -239,7 +336,7 @@ func (s *StreamingServer) Process(srv extProcPb.ExternalProcessor_ProcessServer)
 								BodyMutation: &extProcPb.BodyMutation{
 									Mutation: &extProcPb.BodyMutation_StreamedResponse{
 										StreamedResponse: &extProcPb.StreamedBodyResponse{
-											Body:        v.ResponseBody.Body,
+											Body:        responseBody,
 											EndOfStream: v.ResponseBody.EndOfStream,
 										},
 									},
@@ -249,6 +346,7 @@ func (s *StreamingServer) Process(srv extProcPb.ExternalProcessor_ProcessServer)
 					},
 				}
 			} else {
+				recordResponseChunkLatencies(ctx, reqCtx, chunkReceived, false)
 				body = append(body, v.ResponseBody.Body...)
 
 				// Message is buffered, we can read and decode.
@@ -272,6 +370,7 @@ func (s *StreamingServer) Process(srv extProcPb.ExternalProcessor_ProcessServer)
 						metrics.RecordResponseSizes(reqCtx.Model, reqCtx.ResolvedTargetModel, reqCtx.ResponseSize)
 						metrics.RecordInputTokens(reqCtx.Model, reqCtx.ResolvedTargetModel, reqCtx.Usage.PromptTokens)
 						metrics.RecordOutputTokens(reqCtx.Model, reqCtx.ResolvedTargetModel, reqCtx.Usage.CompletionTokens)
+						recordNormalizedTimePerOutputToken(ctx, reqCtx)
 					}
 				}
 			}
@@ -282,11 +381,11 @@ func (s *StreamingServer) Process(srv extProcPb.ExternalProcessor_ProcessServer)
 		// Handle the err and fire an immediate response.
 		if err != nil {
 			logger.V(logutil.DEFAULT).Error(err, "Failed to process request", "request", req)
-			resp, err := BuildErrResponse(err)
-			if err != nil {
-				return err
+			var retryAfter time.Duration
+			if errutil.CanonicalCode(err) == errutil.InferencePoolResourceExhausted {
+				retryAfter = retryAfterFor(s.datastore.PodGetAll())
 			}
-			if err := srv.Send(resp); err != nil {
+			if err := srv.Send(BuildErrResponse(err, retryAfter)); err != nil {
 				logger.V(logutil.DEFAULT).Error(err, "Send failed")
 				return status.Errorf(codes.Unknown, "failed to send response back to Envoy: %v", err)
 			}
@@ -317,9 +416,12 @@ func (r *RequestContext) updateStateAndSendIfNeeded(srv extProcPb.ExternalProces
 		if err := srv.Send(r.reqBodyResp); err != nil {
 			return status.Errorf(codes.Unknown, "failed to send response back to Envoy: %v", err)
 		}
-		r.RequestState = BodyRequestResponsesComplete
-		metrics.IncRunningRequests(r.Model)
-		r.RequestRunning = true
+		body := r.reqBodyResp.Response.(*extProcPb.ProcessingResponse_RequestBody)
+		if body.RequestBody.Response.GetBodyMutation().GetStreamedResponse().GetEndOfStream() {
+			r.RequestState = BodyRequestResponsesComplete
+			metrics.IncRunningRequests(r.Model)
+			r.RequestRunning = true
+		}
 		// Dump the response so a new stream message can begin
 		r.reqBodyResp = nil
 	}
@@ -425,16 +527,21 @@ func RandomWeightedDraw(logger logr.Logger, model *v1alpha2.InferenceModel, seed
 	}
 	r := rand.New(source)
 
-	// all the weight values are nil, then we should return random model name
-	if model.Spec.TargetModels[0].Weight == nil {
-		index := r.Int31n(int32(len(model.Spec.TargetModels)))
-		return model.Spec.TargetModels[index].Name
-	}
-
 	var weights int32
 	for _, model := range model.Spec.TargetModels {
+		if model.Weight == nil || *model.Weight < 0 {
+			weights = 0
+			break
+		}
 		weights += *model.Weight
 	}
+	// If the weights are unset, only partially set, or don't add up to a positive sum, then we
+	// should return a random model name. The validating webhook rejects such models, but it may
+	// not be installed.
+	if weights <= 0 {
+		index := r.Int31n(int32(len(model.Spec.TargetModels)))
+		return model.Spec.TargetModels[index].Name
+	}
 	logger.V(logutil.TRACE).Info("Weights for model computed", "model", model.Name, "weights", weights)
 	randomVal := r.Int31n(weights)
 	// TODO: optimize this without using loop
@@ -454,56 +561,98 @@ func GetRandomPod(ds datastore.Datastore) *backendmetrics.Pod {
 	return pod.GetPod()
 }
 
-func BuildErrResponse(err error) (*extProcPb.ProcessingResponse, error) {
-	var resp *extProcPb.ProcessingResponse
+// openAIError is the error object of an OpenAI API error response.
+type openAIError struct {
+	Message string  `json:"message"`
+	Type    string  `json:"type"`
+	Param   *string `json:"param"`
+	Code    string  `json:"code"`
+}
 
-	switch errutil.CanonicalCode(err) {
+type openAIErrorResponse struct {
+	Error openAIError `json:"error"`
+}
+
+// errorStatus describes how an errutil.Error code is reported to the client.
+type errorStatus struct {
+	httpStatus envoyTypePb.StatusCode
+	errorType  string
+	code       string
+}
+
+var errorStatuses = map[string]errorStatus{
 	// This code can be returned by scheduler when there is no capacity for sheddable
 	// requests.
-	case errutil.InferencePoolResourceExhausted:
-		resp = &extProcPb.ProcessingResponse{
-			Response: &extProcPb.ProcessingResponse_ImmediateResponse{
-				ImmediateResponse: &extProcPb.ImmediateResponse{
-					Status: &envoyTypePb.HttpStatus{
-						Code: envoyTypePb.StatusCode_TooManyRequests,
-					},
-				},
-			},
-		}
+	errutil.InferencePoolResourceExhausted: {envoyTypePb.StatusCode_TooManyRequests, "rate_limit_error", "rate_limit_exceeded"},
 	// This code can be returned by when EPP processes the request and run into server-side errors.
-	case errutil.Internal:
-		resp = &extProcPb.ProcessingResponse{
-			Response: &extProcPb.ProcessingResponse_ImmediateResponse{
-				ImmediateResponse: &extProcPb.ImmediateResponse{
-					Status: &envoyTypePb.HttpStatus{
-						Code: envoyTypePb.StatusCode_InternalServerError,
-					},
-				},
-			},
-		}
+	errutil.Internal: {envoyTypePb.StatusCode_InternalServerError, "server_error", "internal_error"},
 	// This code can be returned when users provide invalid json request.
-	case errutil.BadRequest:
-		resp = &extProcPb.ProcessingResponse{
-			Response: &extProcPb.ProcessingResponse_ImmediateResponse{
-				ImmediateResponse: &extProcPb.ImmediateResponse{
-					Status: &envoyTypePb.HttpStatus{
-						Code: envoyTypePb.StatusCode_BadRequest,
-					},
-				},
+	errutil.BadRequest: {envoyTypePb.StatusCode_BadRequest, "invalid_request_error", "invalid_request"},
+	// This code can be returned when the requested model isn't served by the pool.
+	errutil.BadConfiguration: {envoyTypePb.StatusCode_NotFound, "invalid_request_error", "model_not_found"},
+	// This code can be returned when the request body exceeds a size limit.
+	errutil.RequestTooLarge: {envoyTypePb.StatusCode_PayloadTooLarge, "invalid_request_error", "request_too_large"},
+}
+
+// unknownErrorStatus reports errors without a known errutil.Error code.
+var unknownErrorStatus = errorStatus{envoyTypePb.StatusCode_InternalServerError, "server_error", "internal_error"}
+
+// BuildErrResponse builds the immediate response reporting err to the client, with an OpenAI-style
+// JSON error body. A positive retryAfter is sent in the Retry-After header of 429 responses.
+func BuildErrResponse(err error, retryAfter time.Duration) *extProcPb.ProcessingResponse {
+	st, ok := errorStatuses[errutil.CanonicalCode(err)]
+	if !ok {
+		st = unknownErrorStatus
+	}
+	message := err.Error()
+	if e, ok := err.(errutil.Error); ok {
+		message = e.Msg
+	}
+	resp := jsonImmediateResponse(st.httpStatus, openAIErrorResponse{Error: openAIError{
+		Message: message,
+		Type:    st.errorType,
+		Code:    st.code,
+	}})
+	if st.httpStatus == envoyTypePb.StatusCode_TooManyRequests && retryAfter > 0 {
+		headers := resp.GetImmediateResponse().Headers
+		headers.SetHeaders = append(headers.SetHeaders, &configPb.HeaderValueOption{
+			Header: &configPb.HeaderValue{
+				Key:      "retry-after",
+				RawValue: []byte(strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))),
 			},
+		})
+	}
+	return resp
+}
+
+const (
+	// queuedRequestDrainTime is the time assumed for a pod to drain one request of its waiting queue.
+	// It is a coarse guess of the latency of a request, which varies by orders of magnitude with the
+	// model and the prompt and output lengths, and is only meant to grow the Retry-After with the load.
+	queuedRequestDrainTime = time.Second
+	// minRetryAfter is the smallest Retry-After, as the header has a resolution of a second and a
+	// Retry-After of zero would have the clients retry right away.
+	minRetryAfter = time.Second
+	// maxRetryAfter bounds the Retry-After of shed requests, so that clients retry within a minute
+	// even when the queues are long, as they may drain much faster than estimated.
+	maxRetryAfter = 60 * time.Second
+)
+
+// retryAfterFor estimates when a shed request may be admitted from the waiting queue of the least
+// loaded pod. It is a coarse heuristic assuming each queued request takes queuedRequestDrainTime to
+// drain, clamped between minRetryAfter and maxRetryAfter.
+func retryAfterFor(pods []backendmetrics.PodMetrics) time.Duration {
+	minQueue := 0
+	found := false
+	for _, pod := range pods {
+		m := pod.GetMetrics()
+		if m == nil {
+			continue
 		}
-	case errutil.BadConfiguration:
-		resp = &extProcPb.ProcessingResponse{
-			Response: &extProcPb.ProcessingResponse_ImmediateResponse{
-				ImmediateResponse: &extProcPb.ImmediateResponse{
-					Status: &envoyTypePb.HttpStatus{
-						Code: envoyTypePb.StatusCode_NotFound,
-					},
-				},
-			},
+		if !found || m.WaitingQueueSize < minQueue {
+			minQueue = m.WaitingQueueSize
+			found = true
 		}
-	default:
-		return nil, status.Errorf(status.Code(err), "failed to handle request: %v", err)
 	}
-	return resp, nil
+	return min(max(time.Duration(minQueue)*queuedRequestDrainTime, minRetryAfter), maxRetryAfter)
 }
@@ -0,0 +1,136 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"bytes"
+	"strings"
+)
+
+// sseEvent is a single server-sent event of a streamed model server response.
+type sseEvent struct {
+	Event string
+	Data  string
+	// raw holds the bytes of the event as received, including its terminating blank line and any
+	// preceding comments or data-less events.
+	raw []byte
+}
+
+// sseDecoder incrementally decodes a text/event-stream body that arrives in arbitrary chunks,
+// following https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation.
+// Partial lines are buffered until the rest of the line arrives in a later chunk, so an event
+// split across ext_proc body chunks is decoded as a whole. The zero value is ready to use.
+type sseDecoder struct {
+	// buf holds the unterminated tail of the stream.
+	buf []byte
+	// raw holds the bytes of the lines consumed since the last dispatched event.
+	raw []byte
+	// event and data accumulate the fields of the event being decoded.
+	event   string
+	data    []string
+	hasData bool
+}
+
+// Decode consumes a body chunk and returns the events it completes. When endOfStream is set, an
+// unterminated trailing line is processed and a pending event is dispatched.
+func (d *sseDecoder) Decode(chunk []byte, endOfStream bool) []sseEvent {
+	d.buf = append(d.buf, chunk...)
+
+	var events []sseEvent
+	start := 0
+	for {
+		i := bytes.IndexAny(d.buf[start:], "\r\n")
+		if i < 0 {
+			break
+		}
+		end := start + i
+		next := end + 1
+		if d.buf[end] == '\r' {
+			if next == len(d.buf) && !endOfStream {
+				// The line feed of a CRLF may be in the next chunk.
+				break
+			}
+			if next < len(d.buf) && d.buf[next] == '\n' {
+				next++
+			}
+		}
+		d.raw = append(d.raw, d.buf[start:next]...)
+		if event, ok := d.processLine(string(d.buf[start:end])); ok {
+			events = append(events, event)
+		}
+		start = next
+	}
+	d.buf = append(d.buf[:0], d.buf[start:]...)
+
+	if endOfStream {
+		if len(d.buf) > 0 {
+			d.raw = append(d.raw, d.buf...)
+			if event, ok := d.processLine(string(d.buf)); ok {
+				events = append(events, event)
+			}
+			d.buf = d.buf[:0]
+		}
+		if event, ok := d.dispatch(); ok {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// processLine interprets a single line, returning an event when the line completes one.
+func (d *sseDecoder) processLine(line string) (sseEvent, bool) {
+	if line == "" {
+		return d.dispatch()
+	}
+	if strings.HasPrefix(line, ":") {
+		// Comment.
+		return sseEvent{}, false
+	}
+	field, value, found := strings.Cut(line, ":")
+	if found {
+		value = strings.TrimPrefix(value, " ")
+	}
+	switch field {
+	case "event":
+		d.event = value
+	case "data":
+		d.data = append(d.data, value)
+		d.hasData = true
+	}
+	return sseEvent{}, false
+}
+
+// dispatch completes the pending event. Events without data are discarded; their bytes are
+// attributed to the next event.
+func (d *sseDecoder) dispatch() (sseEvent, bool) {
+	if !d.hasData {
+		d.event = ""
+		return sseEvent{}, false
+	}
+	event := sseEvent{Event: d.event, Data: strings.Join(d.data, "\n"), raw: append([]byte(nil), d.raw...)}
+	d.raw = d.raw[:0]
+	d.event, d.data, d.hasData = "", d.data[:0], false
+	return event, true
+}
+
+// takeRaw returns and clears the consumed bytes not attributed to any event, such as trailing
+// comments at the end of the stream.
+func (d *sseDecoder) takeRaw() []byte {
+	raw := append([]byte(nil), d.raw...)
+	d.raw = d.raw[:0]
+	return raw
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestSSEDecoder(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		want   []sseEvent
+	}{
+		{
+			name:   "single event",
+			stream: "data: {\"a\":1}\n\n",
+			want:   []sseEvent{{Data: `{"a":1}`}},
+		},
+		{
+			name:   "multiple events",
+			stream: "data: one\n\ndata: two\n\ndata: [DONE]\n\n",
+			want:   []sseEvent{{Data: "one"}, {Data: "two"}, {Data: "[DONE]"}},
+		},
+		{
+			name:   "multi-line data",
+			stream: "data: one\ndata: two\n\n",
+			want:   []sseEvent{{Data: "one\ntwo"}},
+		},
+		{
+			name:   "crlf and cr line endings",
+			stream: "data: one\r\n\r\ndata: two\r\rdata: three\n\n",
+			want:   []sseEvent{{Data: "one"}, {Data: "two"}, {Data: "three"}},
+		},
+		{
+			name:   "event type, comments and unknown fields",
+			stream: ": keep-alive\nid: 1\nretry: 10\nevent: error\ndata: oops\n\n",
+			want:   []sseEvent{{Event: "error", Data: "oops"}},
+		},
+		{
+			name:   "no space after colon",
+			stream: "data:one\n\n",
+			want:   []sseEvent{{Data: "one"}},
+		},
+		{
+			name:   "empty data field",
+			stream: "data\n\n",
+			want:   []sseEvent{{Data: ""}},
+		},
+		{
+			name:   "events without data are discarded",
+			stream: "event: ping\n\n\n\ndata: one\n\n",
+			want:   []sseEvent{{Data: "one"}},
+		},
+		{
+			name:   "unterminated event flushed at end of stream",
+			stream: "data: one\n\ndata: two",
+			want:   []sseEvent{{Data: "one"}, {Data: "two"}},
+		},
+		{
+			name:   "trailing carriage return at end of stream",
+			stream: "data: one\r",
+			want:   []sseEvent{{Data: "one"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := &sseDecoder{}
+			got := d.Decode([]byte(test.stream), true)
+			if diff := cmp.Diff(test.want, got, cmpopts.IgnoreUnexported(sseEvent{})); diff != "" {
+				t.Errorf("Decode returned unexpected events, diff(-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestSSEDecoderPartialEvents(t *testing.T) {
+	d := &sseDecoder{}
+	if got := d.Decode([]byte("data: {\"a\""), false); len(got) != 0 {
+		t.Fatalf("Decode returned events for a partial line: %v", got)
+	}
+	if got := d.Decode([]byte(":1}\r"), false); len(got) != 0 {
+		t.Fatalf("Decode returned events before the blank line: %v", got)
+	}
+	// The line feed completing the CRLF arrives in the next chunk and must not be taken as a blank line.
+	if got := d.Decode([]byte("\n"), false); len(got) != 0 {
+		t.Fatalf("Decode returned events before the blank line: %v", got)
+	}
+	got := d.Decode([]byte("\r\ndata: [DONE]"), false)
+	if diff := cmp.Diff([]sseEvent{{Data: `{"a":1}`, raw: []byte("data: {\"a\":1}\r\n\r\n")}}, got, cmp.AllowUnexported(sseEvent{})); diff != "" {
+		t.Errorf("Decode returned unexpected events, diff(-want, +got): %v", diff)
+	}
+	got = d.Decode(nil, true)
+	if diff := cmp.Diff([]sseEvent{{Data: "[DONE]", raw: []byte("data: [DONE]")}}, got, cmp.AllowUnexported(sseEvent{})); diff != "" {
+		t.Errorf("Decode returned unexpected events at end of stream, diff(-want, +got): %v", diff)
+	}
+}
+
+// decodeInChunks decodes the stream split at the given offsets. It also checks that the raw bytes
+// of the events and the unattributed remainder reassemble the stream.
+func decodeInChunks(t *testing.T, stream []byte, splits []int) []sseEvent {
+	d := &sseDecoder{}
+	var events []sseEvent
+	start := 0
+	for _, split := range splits {
+		events = append(events, d.Decode(stream[start:split], false)...)
+		start = split
+	}
+	events = append(events, d.Decode(stream[start:], true)...)
+
+	var reassembled []byte
+	for _, event := range events {
+		reassembled = append(reassembled, event.raw...)
+	}
+	reassembled = append(reassembled, d.takeRaw()...)
+	if !bytes.Equal(stream, reassembled) {
+		t.Errorf("Raw event bytes %q don't reassemble stream %q split at %v", reassembled, stream, splits)
+	}
+	return events
+}
+
+func TestSSEDecoderChunkBoundaries(t *testing.T) {
+	streams := []string{
+		streamingBodyTokens + streamingBodyWithUsage,
+		streamingBodyChatTokens,
+		"data: one\r\n\r\ndata: two\r\rdata: a\ndata: b\n\n: comment\nevent: x\ndata: three",
+	}
+	for _, stream := range streams {
+		want := decodeInChunks(t, []byte(stream), nil)
+		for i := 0; i <= len(stream); i++ {
+			if diff := cmp.Diff(want, decodeInChunks(t, []byte(stream), []int{i}), cmp.AllowUnexported(sseEvent{})); diff != "" {
+				t.Fatalf("Decode split at %d returned unexpected events, diff(-want, +got): %v", i, diff)
+			}
+		}
+		every := make([]int, len(stream))
+		for i := range every {
+			every[i] = i
+		}
+		if diff := cmp.Diff(want, decodeInChunks(t, []byte(stream), every), cmp.AllowUnexported(sseEvent{})); diff != "" {
+			t.Errorf("Decode one byte at a time returned unexpected events, diff(-want, +got): %v", diff)
+		}
+	}
+}
+
+func FuzzSSEDecoderChunkBoundaries(f *testing.F) {
+	f.Add([]byte(streamingBodyWithUsage), []byte{3, 40, 7})
+	f.Add([]byte(streamingBodyTokens), []byte{1})
+	f.Add([]byte(streamingBodyChatTokens), []byte{0, 255, 13})
+	f.Add([]byte("data: a\r\n\r\ndata: b\r\r"), []byte{9, 1, 1})
+	f.Fuzz(func(t *testing.T, stream []byte, cuts []byte) {
+		// Derive increasing split offsets from the cut lengths.
+		var splits []int
+		offset := 0
+		for _, cut := range cuts {
+			offset += int(cut)
+			if offset > len(stream) {
+				break
+			}
+			splits = append(splits, offset)
+		}
+		want := decodeInChunks(t, stream, nil)
+		if diff := cmp.Diff(want, decodeInChunks(t, stream, splits), cmp.AllowUnexported(sseEvent{})); diff != "" {
+			t.Errorf("Decode split at %v returned unexpected events, diff(-want, +got): %v", splits, diff)
+		}
+	})
+}
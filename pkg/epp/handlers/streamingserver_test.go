@@ -17,9 +17,21 @@ limitations under the License.
 package handlers
 
 import (
+	"context"
+	"errors"
+	"io"
 	"testing"
+	"time"
 
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/testing/protocmp"
 	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+	backendmetrics "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/backend/metrics"
+	errutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/error"
 	logutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/logging"
 )
 
@@ -111,6 +123,48 @@ func TestRandomWeightedDraw(t *testing.T) {
 			},
 			want: "canary",
 		},
+		{
+			name: "weighted distribution with weight partially set",
+			model: &v1alpha2.InferenceModel{
+				Spec: v1alpha2.InferenceModelSpec{
+					TargetModels: []v1alpha2.TargetModel{
+						{
+							Name: "canary",
+						},
+						{
+							Name:   "v1.1",
+							Weight: pointer(20),
+						},
+						{
+							Name: "v1",
+						},
+					},
+				},
+			},
+			want: "canary",
+		},
+		{
+			name: "weighted distribution with zero weights",
+			model: &v1alpha2.InferenceModel{
+				Spec: v1alpha2.InferenceModelSpec{
+					TargetModels: []v1alpha2.TargetModel{
+						{
+							Name:   "canary",
+							Weight: pointer(0),
+						},
+						{
+							Name:   "v1.1",
+							Weight: pointer(0),
+						},
+						{
+							Name:   "v1",
+							Weight: pointer(0),
+						},
+					},
+				},
+			},
+			want: "canary",
+		},
 	}
 	var seedVal int64 = 420
 	for _, test := range tests {
@@ -129,3 +183,191 @@ func TestRandomWeightedDraw(t *testing.T) {
 func pointer(v int32) *int32 {
 	return &v
 }
+
+func TestBuildErrResponse(t *testing.T) {
+	contentType := &configPb.HeaderValueOption{
+		Header: &configPb.HeaderValue{Key: "content-type", RawValue: []byte("application/json")},
+	}
+	tests := []struct {
+		name        string
+		err         error
+		retryAfter  time.Duration
+		wantCode    envoyTypePb.StatusCode
+		wantHeaders []*configPb.HeaderValueOption
+		wantBody    string
+	}{
+		{
+			name:        "bad request",
+			err:         errutil.Error{Code: errutil.BadRequest, Msg: "model not found in request"},
+			wantCode:    envoyTypePb.StatusCode_BadRequest,
+			wantHeaders: []*configPb.HeaderValueOption{contentType},
+			wantBody:    `{"error":{"message":"model not found in request","type":"invalid_request_error","param":null,"code":"invalid_request"}}`,
+		},
+		{
+			name:        "bad configuration",
+			err:         errutil.Error{Code: errutil.BadConfiguration, Msg: "error finding a model object in InferenceModel for input model"},
+			wantCode:    envoyTypePb.StatusCode_NotFound,
+			wantHeaders: []*configPb.HeaderValueOption{contentType},
+			wantBody:    `{"error":{"message":"error finding a model object in InferenceModel for input model","type":"invalid_request_error","param":null,"code":"model_not_found"}}`,
+		},
+		{
+			name:       "resource exhausted",
+			err:        errutil.Error{Code: errutil.InferencePoolResourceExhausted, Msg: "dropping request due to limited backend resources"},
+			retryAfter: 2500 * time.Millisecond,
+			wantCode:   envoyTypePb.StatusCode_TooManyRequests,
+			wantHeaders: []*configPb.HeaderValueOption{
+				contentType,
+				{Header: &configPb.HeaderValue{Key: "retry-after", RawValue: []byte("3")}},
+			},
+			wantBody: `{"error":{"message":"dropping request due to limited backend resources","type":"rate_limit_error","param":null,"code":"rate_limit_exceeded"}}`,
+		},
+		{
+			name:        "resource exhausted without retry hint",
+			err:         errutil.Error{Code: errutil.InferencePoolResourceExhausted, Msg: "dropping request due to limited backend resources"},
+			wantCode:    envoyTypePb.StatusCode_TooManyRequests,
+			wantHeaders: []*configPb.HeaderValueOption{contentType},
+			wantBody:    `{"error":{"message":"dropping request due to limited backend resources","type":"rate_limit_error","param":null,"code":"rate_limit_exceeded"}}`,
+		},
+		{
+			name:        "request too large",
+			err:         errutil.Error{Code: errutil.RequestTooLarge, Msg: "request body exceeds the limit of 16 bytes"},
+			wantCode:    envoyTypePb.StatusCode_PayloadTooLarge,
+			wantHeaders: []*configPb.HeaderValueOption{contentType},
+			wantBody:    `{"error":{"message":"request body exceeds the limit of 16 bytes","type":"invalid_request_error","param":null,"code":"request_too_large"}}`,
+		},
+		{
+			name:        "internal",
+			err:         errutil.Error{Code: errutil.Internal, Msg: "failed to marshal body"},
+			retryAfter:  time.Second,
+			wantCode:    envoyTypePb.StatusCode_InternalServerError,
+			wantHeaders: []*configPb.HeaderValueOption{contentType},
+			wantBody:    `{"error":{"message":"failed to marshal body","type":"server_error","param":null,"code":"internal_error"}}`,
+		},
+		{
+			name:        "unknown error",
+			err:         errors.New("unexpected"),
+			wantCode:    envoyTypePb.StatusCode_InternalServerError,
+			wantHeaders: []*configPb.HeaderValueOption{contentType},
+			wantBody:    `{"error":{"message":"unexpected","type":"server_error","param":null,"code":"internal_error"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			want := &extProcPb.ProcessingResponse{
+				Response: &extProcPb.ProcessingResponse_ImmediateResponse{
+					ImmediateResponse: &extProcPb.ImmediateResponse{
+						Status:  &envoyTypePb.HttpStatus{Code: test.wantCode},
+						Headers: &extProcPb.HeaderMutation{SetHeaders: test.wantHeaders},
+						Body:    []byte(test.wantBody),
+					},
+				},
+			}
+			got := BuildErrResponse(test.err, test.retryAfter)
+			if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("BuildErrResponse returned unexpected response, diff(-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestRetryAfterFor(t *testing.T) {
+	tests := []struct {
+		name string
+		pods []backendmetrics.PodMetrics
+		want time.Duration
+	}{
+		{
+			name: "no pods",
+			want: time.Second,
+		},
+		{
+			name: "least loaded queue",
+			pods: []backendmetrics.PodMetrics{
+				&backendmetrics.FakePodMetrics{Metrics: &backendmetrics.Metrics{WaitingQueueSize: 12}},
+				&backendmetrics.FakePodMetrics{Metrics: &backendmetrics.Metrics{WaitingQueueSize: 5}},
+				&backendmetrics.FakePodMetrics{Metrics: &backendmetrics.Metrics{WaitingQueueSize: 8}},
+			},
+			want: 5 * time.Second,
+		},
+		{
+			name: "empty queue",
+			pods: []backendmetrics.PodMetrics{
+				&backendmetrics.FakePodMetrics{Metrics: &backendmetrics.Metrics{WaitingQueueSize: 3}},
+				&backendmetrics.FakePodMetrics{Metrics: &backendmetrics.Metrics{}},
+			},
+			want: time.Second,
+		},
+		{
+			name: "capped",
+			pods: []backendmetrics.PodMetrics{
+				&backendmetrics.FakePodMetrics{Metrics: &backendmetrics.Metrics{WaitingQueueSize: 500}},
+			},
+			want: maxRetryAfter,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := retryAfterFor(test.pods); got != test.want {
+				t.Errorf("retryAfterFor() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// fakeProcessServer replays the requests to the StreamingServer and collects its responses.
+type fakeProcessServer struct {
+	grpc.ServerStream
+	requests  []*extProcPb.ProcessingRequest
+	responses []*extProcPb.ProcessingResponse
+}
+
+func (f *fakeProcessServer) Context() context.Context {
+	return context.Background()
+}
+
+func (f *fakeProcessServer) Recv() (*extProcPb.ProcessingRequest, error) {
+	if len(f.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := f.requests[0]
+	f.requests = f.requests[1:]
+	return req, nil
+}
+
+func (f *fakeProcessServer) Send(resp *extProcPb.ProcessingResponse) error {
+	f.responses = append(f.responses, resp)
+	return nil
+}
+
+func TestProcessModelNameHeaderPassthrough(t *testing.T) {
+	server := newModelHeaderTestServer(t, &fakeScheduler{pod: &backendmetrics.Pod{Address: "10.0.0.1"}})
+	chunks := [][]byte{[]byte(`{"model":"llama-3",`), []byte(`"prompt":"hi"}`)}
+	srv := &fakeProcessServer{requests: []*extProcPb.ProcessingRequest{
+		{Request: &extProcPb.ProcessingRequest_RequestHeaders{RequestHeaders: &extProcPb.HttpHeaders{
+			Headers: &configPb.HeaderMap{Headers: []*configPb.HeaderValue{
+				{Key: "x-gateway-model-name", RawValue: []byte("llama-3")},
+			}},
+		}}},
+		{Request: &extProcPb.ProcessingRequest_RequestBody{RequestBody: &extProcPb.HttpBody{Body: chunks[0]}}},
+		{Request: &extProcPb.ProcessingRequest_RequestBody{RequestBody: &extProcPb.HttpBody{Body: chunks[1], EndOfStream: true}}},
+	}}
+	if err := server.Process(srv); err != nil {
+		t.Fatalf("Process returned unexpected error: %v", err)
+	}
+
+	// The headers are answered before the body is received, then each chunk is sent back unchanged.
+	if len(srv.responses) != 3 {
+		t.Fatalf("Process sent %d responses, want 3: %v", len(srv.responses), srv.responses)
+	}
+	if srv.responses[0].GetRequestHeaders() == nil {
+		t.Errorf("First response isn't a request headers response: %v", srv.responses[0])
+	}
+	for i, chunk := range chunks {
+		want := requestBodyResponse(chunk, i == len(chunks)-1)
+		if diff := cmp.Diff(want, srv.responses[i+1], protocmp.Transform()); diff != "" {
+			t.Errorf("Unexpected body response %d, diff(-want, +got): %v", i, diff)
+		}
+	}
+}
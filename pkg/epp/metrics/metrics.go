@@ -18,6 +18,7 @@ package metrics
 
 import (
 	"context"
+	"strconv"
 	"sync"
 	"time"
 
@@ -121,6 +122,17 @@ var (
 		[]string{"model_name", "target_model_name"},
 	)
 
+	estimatedOutputTokens = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Subsystem:      InferenceModelComponent,
+			Name:           "estimated_output_tokens",
+			Help:           "Inference model output token count distribution estimated from the streamed events of the responses without usage, for requests in each model.",
+			Buckets:        []float64{1, 8, 16, 32, 64, 128, 256, 512, 1024, 2048, 4096, 8192},
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"model_name", "target_model_name"},
+	)
+
 	runningRequests = compbasemetrics.NewGaugeVec(
 		&compbasemetrics.GaugeOpts{
 			Subsystem:      InferenceModelComponent,
@@ -146,6 +158,55 @@ var (
 		[]string{"model_name", "target_model_name"},
 	)
 
+	// TTFT - Time To First Token
+	timeToFirstToken = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Subsystem: InferenceModelComponent,
+			Name:      "time_to_first_token_seconds",
+			Help:      "Inference model time from request receipt to the first response body chunk in seconds for each model and target model.",
+			Buckets: []float64{
+				0.001, 0.005, 0.01, 0.02, 0.04, 0.06, 0.08, 0.1, 0.25, 0.5, 0.75, 1.0, 2.5, 5.0, 7.5, 10.0, 20.0, 40.0, 80.0,
+			},
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"model_name", "target_model_name"},
+	)
+
+	// ITL - Inter-Token Latency
+	interTokenLatency = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Subsystem: InferenceModelComponent,
+			Name:      "inter_token_latency_seconds",
+			Help:      "Inference model latency between consecutive token-bearing response chunks in seconds for each model and target model.",
+			Buckets: []float64{
+				0.001, 0.002, 0.005, 0.01, 0.015, 0.02, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0,
+			},
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"model_name", "target_model_name"},
+	)
+
+	unregisteredModelRequests = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Subsystem: InferenceModelComponent,
+			Name:      "unregistered_request_total",
+			Help: "Counter of requests for models without an InferenceModel broken out for each model and the policy applied. " +
+				"Models beyond the first " + strconv.Itoa(maxUnregisteredModelNames) + " are counted as " + UnregisteredModelOverflow + ".",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"model_name", "policy"},
+	)
+
+	requestLimitExceeded = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Subsystem:      InferenceModelComponent,
+			Name:           "request_limit_exceeded_total",
+			Help:           "Counter of requests rejected for exceeding a request limit broken out for each model and limit.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"model_name", "limit"},
+	)
+
 	// Inference Pool Metrics
 	inferencePoolAvgKVCache = compbasemetrics.NewGaugeVec(
 		&compbasemetrics.GaugeOpts{
@@ -176,6 +237,61 @@ var (
 		},
 		[]string{"name"},
 	)
+
+	inferencePoolPodScrapeLatencies = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Subsystem: InferencePoolComponent,
+			Name:      "pod_scrape_duration_seconds",
+			Help:      "Latency distribution in seconds of the metrics scrapes of the model server pods in an inference server pool.",
+			Buckets: []float64{
+				0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5,
+			},
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"name", "result"},
+	)
+
+	inferencePoolPodScrapeErrors = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Subsystem:      InferencePoolComponent,
+			Name:           "pod_scrape_errors_total",
+			Help:           "Counter of failed metrics scrapes of the model server pods in an inference server pool.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"name", "pod"},
+	)
+
+	inferencePoolScrapeFailingPods = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Subsystem:      InferencePoolComponent,
+			Name:           "scrape_failing_pods",
+			Help:           "The number of pods in the inference server pool whose last metrics scrape failed.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"name"},
+	)
+
+	inferencePoolPodsMissingMetric = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Subsystem:      InferencePoolComponent,
+			Name:           "pods_missing_metric",
+			Help:           "The number of pods in the inference server pool whose last metrics scrape lacked a mapped metric.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"name", "metric"},
+	)
+)
+
+// maxUnregisteredModelNames caps the model names of the unregistered request counter, which come
+// from the clients.
+const maxUnregisteredModelNames = 100
+
+// UnregisteredModelOverflow is the model name of the unregistered requests beyond the cap.
+const UnregisteredModelOverflow = "__overflow__"
+
+var (
+	unregisteredModelNamesMu sync.Mutex
+	unregisteredModelNames   = make(map[string]bool)
 )
 
 var registerMetrics sync.Once
@@ -190,12 +306,21 @@ func Register() {
 		legacyregistry.MustRegister(responseSizes)
 		legacyregistry.MustRegister(inputTokens)
 		legacyregistry.MustRegister(outputTokens)
+		legacyregistry.MustRegister(estimatedOutputTokens)
 		legacyregistry.MustRegister(runningRequests)
 		legacyregistry.MustRegister(NormalizedTimePerOutputToken)
+		legacyregistry.MustRegister(timeToFirstToken)
+		legacyregistry.MustRegister(interTokenLatency)
+		legacyregistry.MustRegister(unregisteredModelRequests)
+		legacyregistry.MustRegister(requestLimitExceeded)
 
 		legacyregistry.MustRegister(inferencePoolAvgKVCache)
 		legacyregistry.MustRegister(inferencePoolAvgQueueSize)
 		legacyregistry.MustRegister(inferencePoolReadyPods)
+		legacyregistry.MustRegister(inferencePoolPodScrapeLatencies)
+		legacyregistry.MustRegister(inferencePoolPodScrapeErrors)
+		legacyregistry.MustRegister(inferencePoolScrapeFailingPods)
+		legacyregistry.MustRegister(inferencePoolPodsMissingMetric)
 	})
 }
 
@@ -247,6 +372,14 @@ func RecordOutputTokens(modelName, targetModelName string, size int) {
 	}
 }
 
+// RecordEstimatedOutputTokens records the output tokens count estimated from a streamed response
+// without usage.
+func RecordEstimatedOutputTokens(modelName, targetModelName string, size int) {
+	if size > 0 {
+		estimatedOutputTokens.WithLabelValues(modelName, targetModelName).Observe(float64(size))
+	}
+}
+
 // RecordNormalizedTimePerOutputToken (NTPOT) records the normalized time per output token.
 func RecordNormalizedTimePerOutputToken(ctx context.Context, modelName, targetModelName string, received time.Time, complete time.Time, outputTokenCount int) bool {
 	if !complete.After(received) {
@@ -268,6 +401,50 @@ func RecordNormalizedTimePerOutputToken(ctx context.Context, modelName, targetMo
 	return true
 }
 
+// RecordTimeToFirstToken (TTFT) records the time between receiving the request and the first response token.
+func RecordTimeToFirstToken(ctx context.Context, modelName, targetModelName string, received time.Time, firstToken time.Time) bool {
+	if !firstToken.After(received) {
+		log.FromContext(ctx).V(logutil.DEFAULT).Error(nil, "Time to first token values are invalid",
+			"modelName", modelName, "targetModelName", targetModelName, "firstTokenTime", firstToken, "receivedTime", received)
+		return false
+	}
+	timeToFirstToken.WithLabelValues(modelName, targetModelName).Observe(firstToken.Sub(received).Seconds())
+	return true
+}
+
+// RecordInterTokenLatency (ITL) records the time between two consecutive token-bearing response chunks.
+func RecordInterTokenLatency(ctx context.Context, modelName, targetModelName string, previousToken time.Time, token time.Time) bool {
+	if token.Before(previousToken) {
+		log.FromContext(ctx).V(logutil.DEFAULT).Error(nil, "Inter-token latency values are invalid",
+			"modelName", modelName, "targetModelName", targetModelName, "tokenTime", token, "previousTokenTime", previousToken)
+		return false
+	}
+	interTokenLatency.WithLabelValues(modelName, targetModelName).Observe(token.Sub(previousToken).Seconds())
+	return true
+}
+
+// RecordUnregisteredModelRequest records a request for a model without an InferenceModel. Only the
+// first maxUnregisteredModelNames model names are reported, the others are counted as
+// UnregisteredModelOverflow.
+func RecordUnregisteredModelRequest(modelName, policy string) {
+	unregisteredModelNamesMu.Lock()
+	if !unregisteredModelNames[modelName] {
+		if len(unregisteredModelNames) < maxUnregisteredModelNames {
+			unregisteredModelNames[modelName] = true
+		} else {
+			modelName = UnregisteredModelOverflow
+		}
+	}
+	unregisteredModelNamesMu.Unlock()
+	unregisteredModelRequests.WithLabelValues(modelName, policy).Inc()
+}
+
+// RecordRequestLimitExceeded records a request rejected for exceeding a limit. The model name is
+// empty for requests rejected before their model is known.
+func RecordRequestLimitExceeded(modelName, limit string) {
+	requestLimitExceeded.WithLabelValues(modelName, limit).Inc()
+}
+
 // IncRunningRequests increases the current running requests.
 func IncRunningRequests(modelName string) {
 	if modelName != "" {
@@ -293,3 +470,36 @@ func RecordInferencePoolAvgQueueSize(name string, queueSize float64) {
 func RecordinferencePoolReadyPods(name string, runningPods float64) {
 	inferencePoolReadyPods.WithLabelValues(name).Set(runningPods)
 }
+
+// RecordPodScrapeLatency records the latency of a metrics scrape of a pod in the pool.
+func RecordPodScrapeLatency(name string, success bool, latency time.Duration) {
+	result := "success"
+	if !success {
+		result = "error"
+	}
+	inferencePoolPodScrapeLatencies.WithLabelValues(name, result).Observe(latency.Seconds())
+}
+
+// RecordPodScrapeError records a failed metrics scrape of a pod in the pool.
+func RecordPodScrapeError(name, pod string) {
+	inferencePoolPodScrapeErrors.WithLabelValues(name, pod).Inc()
+}
+
+// DeletePodScrapeErrors drops the scrape errors of a pod that left the pool.
+func DeletePodScrapeErrors(name, pod string) {
+	inferencePoolPodScrapeErrors.Delete(map[string]string{"name": name, "pod": pod})
+}
+
+// RecordInferencePoolScrapeFailingPods records the number of pods whose last scrape failed.
+func RecordInferencePoolScrapeFailingPods(name string, failingPods float64) {
+	inferencePoolScrapeFailingPods.WithLabelValues(name).Set(failingPods)
+}
+
+// RecordInferencePoolPodsMissingMetrics records, for each mapped metric, the number of pods whose
+// last scrape lacked it. Metrics that are no longer missing are reset.
+func RecordInferencePoolPodsMissingMetrics(name string, podsMissingMetric map[string]int) {
+	inferencePoolPodsMissingMetric.Reset()
+	for metric, pods := range podsMissingMetric {
+		inferencePoolPodsMissingMetric.WithLabelValues(name, metric).Set(float64(pods))
+	}
+}
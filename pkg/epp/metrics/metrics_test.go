@@ -18,7 +18,9 @@ package metrics
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,10 +38,18 @@ const (
 	ResponseSizesMetric                = InferenceModelComponent + "_response_sizes"
 	InputTokensMetric                  = InferenceModelComponent + "_input_tokens"
 	OutputTokensMetric                 = InferenceModelComponent + "_output_tokens"
+	EstimatedOutputTokensMetric        = InferenceModelComponent + "_estimated_output_tokens"
 	NormalizedTimePerOutputTokenMetric = InferenceModelComponent + "_normalized_time_per_output_token_seconds"
+	TimeToFirstTokenMetric             = InferenceModelComponent + "_time_to_first_token_seconds"
+	InterTokenLatencyMetric            = InferenceModelComponent + "_inter_token_latency_seconds"
 	RunningRequestsMetric              = InferenceModelComponent + "_running_requests"
+	UnregisteredRequestTotalMetric     = InferenceModelComponent + "_unregistered_request_total"
+	RequestLimitExceededTotalMetric    = InferenceModelComponent + "_request_limit_exceeded_total"
 	KVCacheAvgUsageMetric              = InferencePoolComponent + "_average_kv_cache_utilization"
 	QueueAvgSizeMetric                 = InferencePoolComponent + "_average_queue_size"
+	PodScrapeLatenciesMetric           = InferencePoolComponent + "_pod_scrape_duration_seconds"
+	PodScrapeErrorsMetric              = InferencePoolComponent + "_pod_scrape_errors_total"
+	PodsMissingMetricMetric            = InferencePoolComponent + "_pods_missing_metric"
 )
 
 func TestRecordRequestCounterandSizes(t *testing.T) {
@@ -354,6 +364,117 @@ func TestRecordNormalizedTimePerOutputToken(t *testing.T) {
 	}
 }
 
+func TestRecordTokenLatencies(t *testing.T) {
+	ctx := logutil.NewTestLoggerIntoContext(context.Background())
+	timeBaseline := time.Now()
+	type tokenTimes struct {
+		modelName       string
+		targetModelName string
+		receivedTime    time.Time
+		tokenTimes      []time.Time
+	}
+	scenarios := []struct {
+		name    string
+		reqs    []tokenTimes
+		invalid bool
+	}{
+		{
+			name: "multiple requests",
+			reqs: []tokenTimes{
+				{
+					modelName:       "m10",
+					targetModelName: "t10",
+					receivedTime:    timeBaseline,
+					tokenTimes: []time.Time{
+						timeBaseline.Add(time.Millisecond * 50),
+						timeBaseline.Add(time.Millisecond * 60),
+						timeBaseline.Add(time.Millisecond * 80),
+					},
+				},
+				{
+					modelName:       "m10",
+					targetModelName: "t11",
+					receivedTime:    timeBaseline,
+					tokenTimes: []time.Time{
+						timeBaseline.Add(time.Millisecond * 300),
+						timeBaseline.Add(time.Millisecond * 400),
+					},
+				},
+				{
+					modelName:       "m20",
+					targetModelName: "t20",
+					receivedTime:    timeBaseline,
+					tokenTimes: []time.Time{
+						timeBaseline.Add(time.Millisecond * 1500),
+					},
+				},
+			},
+		},
+		{
+			name: "invalid time to first token",
+			reqs: []tokenTimes{
+				{
+					modelName:       "m10",
+					targetModelName: "t10",
+					receivedTime:    timeBaseline.Add(time.Millisecond * 10),
+					tokenTimes:      []time.Time{timeBaseline},
+				},
+			},
+			invalid: true,
+		},
+	}
+	Register()
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			for _, req := range scenario.reqs {
+				success := RecordTimeToFirstToken(ctx, req.modelName, req.targetModelName, req.receivedTime, req.tokenTimes[0])
+				if success == scenario.invalid {
+					t.Errorf("got record success(%v), but the request expects invalid(%v)", success, scenario.invalid)
+				}
+				for i := 1; i < len(req.tokenTimes); i++ {
+					if !RecordInterTokenLatency(ctx, req.modelName, req.targetModelName, req.tokenTimes[i-1], req.tokenTimes[i]) {
+						t.Errorf("failed to record inter-token latency for %v", req)
+					}
+				}
+			}
+
+			wantTTFT, err := os.Open("testdata/time_to_first_token_seconds_metric")
+			defer func() {
+				if err := wantTTFT.Close(); err != nil {
+					t.Error(err)
+				}
+			}()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := testutil.GatherAndCompare(legacyregistry.DefaultGatherer, wantTTFT, TimeToFirstTokenMetric); err != nil {
+				t.Error(err)
+			}
+
+			wantITL, err := os.Open("testdata/inter_token_latency_seconds_metric")
+			defer func() {
+				if err := wantITL.Close(); err != nil {
+					t.Error(err)
+				}
+			}()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := testutil.GatherAndCompare(legacyregistry.DefaultGatherer, wantITL, InterTokenLatencyMetric); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestRecordInterTokenLatencyInvalid(t *testing.T) {
+	ctx := logutil.NewTestLoggerIntoContext(context.Background())
+	now := time.Now()
+	if RecordInterTokenLatency(ctx, "m", "t", now, now.Add(-time.Millisecond)) {
+		t.Error("expected a token preceding the previous token to be rejected")
+	}
+}
+
 func TestRecordResponseMetrics(t *testing.T) {
 	type responses struct {
 		modelName       string
@@ -448,6 +569,80 @@ func TestRecordResponseMetrics(t *testing.T) {
 	}
 }
 
+func TestRecordUnregisteredModelRequest(t *testing.T) {
+	Register()
+	RecordUnregisteredModelRequest("m1", "reject")
+	RecordUnregisteredModelRequest("m1", "reject")
+	RecordUnregisteredModelRequest("m1", "passthrough")
+	for i := 2; i <= maxUnregisteredModelNames+2; i++ {
+		RecordUnregisteredModelRequest(fmt.Sprintf("m%d", i), "default")
+	}
+	// Names seen before the cap are still reported.
+	RecordUnregisteredModelRequest("m1", "reject")
+
+	var want strings.Builder
+	want.WriteString(`# HELP inference_model_unregistered_request_total [ALPHA] Counter of requests for models without an InferenceModel broken out for each model and the policy applied. Models beyond the first 100 are counted as __overflow__.
+# TYPE inference_model_unregistered_request_total counter
+inference_model_unregistered_request_total{model_name="m1", policy="reject"} 3
+inference_model_unregistered_request_total{model_name="m1", policy="passthrough"} 1
+inference_model_unregistered_request_total{model_name="__overflow__", policy="default"} 2
+`)
+	for i := 2; i <= maxUnregisteredModelNames; i++ {
+		fmt.Fprintf(&want, "inference_model_unregistered_request_total{model_name=\"m%d\", policy=\"default\"} 1\n", i)
+	}
+	if err := testutil.GatherAndCompare(legacyregistry.DefaultGatherer, strings.NewReader(want.String()), UnregisteredRequestTotalMetric); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecordEstimatedOutputTokens(t *testing.T) {
+	Register()
+	RecordEstimatedOutputTokens("m1", "t1", 3)
+	RecordEstimatedOutputTokens("m1", "t1", 40)
+	// Responses without streamed tokens aren't recorded.
+	RecordEstimatedOutputTokens("m1", "t1", 0)
+
+	want := `# HELP inference_model_estimated_output_tokens [ALPHA] Inference model output token count distribution estimated from the streamed events of the responses without usage, for requests in each model.
+# TYPE inference_model_estimated_output_tokens histogram
+inference_model_estimated_output_tokens_bucket{model_name="m1",target_model_name="t1",le="1"} 0
+inference_model_estimated_output_tokens_bucket{model_name="m1",target_model_name="t1",le="8"} 1
+inference_model_estimated_output_tokens_bucket{model_name="m1",target_model_name="t1",le="16"} 1
+inference_model_estimated_output_tokens_bucket{model_name="m1",target_model_name="t1",le="32"} 1
+inference_model_estimated_output_tokens_bucket{model_name="m1",target_model_name="t1",le="64"} 2
+inference_model_estimated_output_tokens_bucket{model_name="m1",target_model_name="t1",le="128"} 2
+inference_model_estimated_output_tokens_bucket{model_name="m1",target_model_name="t1",le="256"} 2
+inference_model_estimated_output_tokens_bucket{model_name="m1",target_model_name="t1",le="512"} 2
+inference_model_estimated_output_tokens_bucket{model_name="m1",target_model_name="t1",le="1024"} 2
+inference_model_estimated_output_tokens_bucket{model_name="m1",target_model_name="t1",le="2048"} 2
+inference_model_estimated_output_tokens_bucket{model_name="m1",target_model_name="t1",le="4096"} 2
+inference_model_estimated_output_tokens_bucket{model_name="m1",target_model_name="t1",le="8192"} 2
+inference_model_estimated_output_tokens_bucket{model_name="m1",target_model_name="t1",le="+Inf"} 2
+inference_model_estimated_output_tokens_sum{model_name="m1",target_model_name="t1"} 43
+inference_model_estimated_output_tokens_count{model_name="m1",target_model_name="t1"} 2
+`
+	if err := testutil.GatherAndCompare(legacyregistry.DefaultGatherer, strings.NewReader(want), EstimatedOutputTokensMetric); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecordRequestLimitExceeded(t *testing.T) {
+	Register()
+	RecordRequestLimitExceeded("", "body_size")
+	RecordRequestLimitExceeded("m1", "max_tokens")
+	RecordRequestLimitExceeded("m1", "max_tokens")
+	RecordRequestLimitExceeded("m2", "messages")
+
+	want := `# HELP inference_model_request_limit_exceeded_total [ALPHA] Counter of requests rejected for exceeding a request limit broken out for each model and limit.
+# TYPE inference_model_request_limit_exceeded_total counter
+inference_model_request_limit_exceeded_total{limit="body_size", model_name=""} 1
+inference_model_request_limit_exceeded_total{limit="max_tokens", model_name="m1"} 2
+inference_model_request_limit_exceeded_total{limit="messages", model_name="m2"} 1
+`
+	if err := testutil.GatherAndCompare(legacyregistry.DefaultGatherer, strings.NewReader(want), RequestLimitExceededTotalMetric); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestRunningRequestsMetrics(t *testing.T) {
 	type request struct {
 		modelName string
@@ -556,3 +751,56 @@ func TestInferencePoolMetrics(t *testing.T) {
 		})
 	}
 }
+
+func TestRecordPodScrapeLatency(t *testing.T) {
+	Register()
+	RecordPodScrapeLatency("p1", true, 3*time.Millisecond)
+	RecordPodScrapeLatency("p1", true, 20*time.Millisecond)
+	RecordPodScrapeLatency("p1", false, 200*time.Millisecond)
+
+	wantLatencies, err := os.Open("testdata/pod_scrape_duration_seconds_metric")
+	defer func() {
+		if err := wantLatencies.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := testutil.GatherAndCompare(legacyregistry.DefaultGatherer, wantLatencies, PodScrapeLatenciesMetric); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPodScrapeHealthMetrics(t *testing.T) {
+	Register()
+	RecordPodScrapeError("p1", "pod1")
+	RecordPodScrapeError("p1", "pod1")
+	RecordPodScrapeError("p1", "pod2")
+	// Deleted pods are no longer reported.
+	DeletePodScrapeErrors("p1", "pod2")
+
+	RecordInferencePoolPodsMissingMetrics("p1", map[string]int{"vllm:lora_requests_info": 1})
+	// Metrics that are no longer missing are reset.
+	RecordInferencePoolPodsMissingMetrics("p1", map[string]int{"vllm:gpu_cache_usage_perc": 3})
+
+	for metric, file := range map[string]string{
+		PodScrapeErrorsMetric:   "testdata/pod_scrape_errors_total_metric",
+		PodsMissingMetricMetric: "testdata/pods_missing_metric_metric",
+	} {
+		t.Run(metric, func(t *testing.T) {
+			want, err := os.Open(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				if err := want.Close(); err != nil {
+					t.Error(err)
+				}
+			}()
+			if err := testutil.GatherAndCompare(legacyregistry.DefaultGatherer, want, metric); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
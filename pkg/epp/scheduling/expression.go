@@ -0,0 +1,343 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/scheduling/types"
+)
+
+// customMetricPrefix prefixes the custom metrics of the pods in expressions.
+const customMetricPrefix = "custom."
+
+// expression is an arithmetic and boolean expression over the metrics of a pod, such as
+// "custom.batch_fill_ratio < 0.9 && waiting_queue_size <= 5". Comparisons and boolean operators
+// evaluate to 1 (true) or 0 (false).
+//
+// The identifiers are the custom metrics, prefixed with "custom.", and the built-in metrics listed
+// in podMetricVariables.
+type expression struct {
+	src  string
+	root exprNode
+}
+
+// exprNode evaluates a part of an expression for a pod. ok is false if the value is undefined for
+// the pod, because it doesn't report a custom metric or because of a division by zero.
+type exprNode func(pod *types.PodMetrics) (value float64, ok bool)
+
+// podMetricVariables are the built-in metrics of the pods that the expressions can reference.
+var podMetricVariables = map[string]exprNode{
+	"waiting_queue_size": func(pod *types.PodMetrics) (float64, bool) {
+		return float64(pod.WaitingQueueSize), true
+	},
+	"running_queue_size": func(pod *types.PodMetrics) (float64, bool) {
+		return float64(pod.RunningQueueSize), true
+	},
+	"kv_cache_usage_percent": func(pod *types.PodMetrics) (float64, bool) {
+		return pod.KVCacheUsagePercent, true
+	},
+	"kv_cache_max_token_capacity": func(pod *types.PodMetrics) (float64, bool) {
+		return float64(pod.KvCacheMaxTokenCapacity), true
+	},
+	"prefix_cache_hit_rate": func(pod *types.PodMetrics) (float64, bool) {
+		return pod.PrefixCacheHitRate, true
+	},
+	"preemption_rate": func(pod *types.PodMetrics) (float64, bool) {
+		return pod.PreemptionRate, true
+	},
+	"token_throughput": func(pod *types.PodMetrics) (float64, bool) {
+		return pod.TokenThroughput, true
+	},
+	"smoothed_waiting_queue_size": func(pod *types.PodMetrics) (float64, bool) {
+		return pod.SmoothedWaitingQueueSize, true
+	},
+	"smoothed_kv_cache_usage_percent": func(pod *types.PodMetrics) (float64, bool) {
+		return pod.SmoothedKVCacheUsagePercent, true
+	},
+}
+
+// parseExpression parses an expression, checking that all the identifiers are known.
+func parseExpression(src string) (*expression, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", src, err)
+	}
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", src, err)
+	}
+	return &expression{src: src, root: root}, nil
+}
+
+// parseExpressions parses a list of semicolon-separated expressions.
+func parseExpressions(src string) ([]*expression, error) {
+	var exprs []*expression
+	for _, part := range strings.Split(src, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		expr, err := parseExpression(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	return exprs, nil
+}
+
+func (e *expression) String() string {
+	return e.src
+}
+
+// evaluate returns the value of the expression for the pod, ok is false if it is undefined.
+func (e *expression) evaluate(pod *types.PodMetrics) (value float64, ok bool) {
+	return e.root(pod)
+}
+
+// predicate returns a podPredicate accepting the pods for which the expression is defined and
+// non-zero.
+func (e *expression) predicate() podPredicate {
+	return func(req *types.LLMRequest, pod *types.PodMetrics) bool {
+		value, ok := e.evaluate(pod)
+		return ok && value != 0
+	}
+}
+
+// tokenize splits an expression into numbers, identifiers, operators and parentheses.
+func tokenize(src string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || c == '.':
+			j := i
+			for j < len(src) && (unicode.IsDigit(rune(src[j])) || src[j] == '.' || src[j] == 'e' || src[j] == 'E' ||
+				((src[j] == '+' || src[j] == '-') && (src[j-1] == 'e' || src[j-1] == 'E'))) {
+				j++
+			}
+			tokens = append(tokens, src[i:j])
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(src) && (unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j])) || src[j] == '_' || src[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, src[i:j])
+			i = j
+		default:
+			if i+1 < len(src) {
+				if op := src[i : i+2]; op == "&&" || op == "||" || op == "<=" || op == ">=" || op == "==" || op == "!=" {
+					tokens = append(tokens, op)
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("<>!+-*/()", c) {
+				return nil, fmt.Errorf("unexpected character %q", c)
+			}
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	return tokens, nil
+}
+
+// exprParser is a recursive descent parser of the expressions, from the lowest to the highest
+// precedence: ||, &&, !, comparisons, + and -, * and /, unary minus.
+type exprParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *exprParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+// parseBinary parses a left-associative sequence of operands separated by the given operators.
+func (p *exprParser) parseBinary(operand func() (exprNode, error), ops map[string]func(a, b float64) (float64, bool)) (exprNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, found := ops[p.peek()]
+		if !found {
+			return left, nil
+		}
+		p.next()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(pod *types.PodMetrics) (float64, bool) {
+			a, ok := l(pod)
+			if !ok {
+				return 0, false
+			}
+			b, ok := right(pod)
+			if !ok {
+				return 0, false
+			}
+			return op(a, b)
+		}
+	}
+}
+
+func boolValue(b bool) (float64, bool) {
+	if b {
+		return 1, true
+	}
+	return 0, true
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	return p.parseBinary(p.parseAnd, map[string]func(a, b float64) (float64, bool){
+		"||": func(a, b float64) (float64, bool) { return boolValue(a != 0 || b != 0) },
+	})
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	return p.parseBinary(p.parseNot, map[string]func(a, b float64) (float64, bool){
+		"&&": func(a, b float64) (float64, bool) { return boolValue(a != 0 && b != 0) },
+	})
+}
+
+func (p *exprParser) parseNot() (exprNode, error) {
+	if p.peek() != "!" {
+		return p.parseComparison()
+	}
+	p.next()
+	operand, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	return func(pod *types.PodMetrics) (float64, bool) {
+		value, ok := operand(pod)
+		if !ok {
+			return 0, false
+		}
+		return boolValue(value == 0)
+	}, nil
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	return p.parseBinary(p.parseSum, map[string]func(a, b float64) (float64, bool){
+		"<":  func(a, b float64) (float64, bool) { return boolValue(a < b) },
+		"<=": func(a, b float64) (float64, bool) { return boolValue(a <= b) },
+		">":  func(a, b float64) (float64, bool) { return boolValue(a > b) },
+		">=": func(a, b float64) (float64, bool) { return boolValue(a >= b) },
+		"==": func(a, b float64) (float64, bool) { return boolValue(a == b) },
+		"!=": func(a, b float64) (float64, bool) { return boolValue(a != b) },
+	})
+}
+
+func (p *exprParser) parseSum() (exprNode, error) {
+	return p.parseBinary(p.parseProduct, map[string]func(a, b float64) (float64, bool){
+		"+": func(a, b float64) (float64, bool) { return a + b, true },
+		"-": func(a, b float64) (float64, bool) { return a - b, true },
+	})
+}
+
+func (p *exprParser) parseProduct() (exprNode, error) {
+	return p.parseBinary(p.parseUnary, map[string]func(a, b float64) (float64, bool){
+		"*": func(a, b float64) (float64, bool) { return a * b, true },
+		"/": func(a, b float64) (float64, bool) {
+			if b == 0 {
+				return 0, false
+			}
+			return a / b, true
+		},
+	})
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.peek() != "-" {
+		return p.parsePrimary()
+	}
+	p.next()
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return func(pod *types.PodMetrics) (float64, bool) {
+		value, ok := operand(pod)
+		return -value, ok
+	}, nil
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	token := p.next()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case token == "(":
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return inner, nil
+	case unicode.IsDigit(rune(token[0])) || token[0] == '.':
+		value, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", token)
+		}
+		return func(*types.PodMetrics) (float64, bool) { return value, true }, nil
+	case strings.HasPrefix(token, customMetricPrefix):
+		name := strings.TrimPrefix(token, customMetricPrefix)
+		if name == "" {
+			return nil, fmt.Errorf("empty custom metric name")
+		}
+		return func(pod *types.PodMetrics) (float64, bool) {
+			value, ok := pod.Custom[name]
+			return value, ok
+		}, nil
+	case unicode.IsLetter(rune(token[0])) || token[0] == '_':
+		variable, ok := podMetricVariables[token]
+		if !ok {
+			return nil, fmt.Errorf("unknown metric %q", token)
+		}
+		return variable, nil
+	default:
+		return nil, fmt.Errorf("unexpected %q", token)
+	}
+}
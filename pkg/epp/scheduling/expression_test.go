@@ -0,0 +1,229 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	backendmetrics "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/backend/metrics"
+	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/scheduling/types"
+)
+
+func TestExpression(t *testing.T) {
+	pod := &types.PodMetrics{
+		Metrics: &backendmetrics.Metrics{
+			WaitingQueueSize:    4,
+			RunningQueueSize:    2,
+			KVCacheUsagePercent: 0.5,
+			Custom:              map[string]float64{"batch_fill_ratio": 0.8},
+		},
+	}
+	tests := []struct {
+		expr   string
+		want   float64
+		wantOk bool
+	}{
+		{expr: "custom.batch_fill_ratio < 0.9", want: 1, wantOk: true},
+		{expr: "custom.batch_fill_ratio >= 0.9", want: 0, wantOk: true},
+		{expr: "waiting_queue_size + running_queue_size * 2", want: 8, wantOk: true},
+		{expr: "(waiting_queue_size + running_queue_size) * 2", want: 12, wantOk: true},
+		{expr: "-kv_cache_usage_percent", want: -0.5, wantOk: true},
+		{expr: "waiting_queue_size <= 5 && kv_cache_usage_percent < 0.4", want: 0, wantOk: true},
+		{expr: "waiting_queue_size <= 5 || kv_cache_usage_percent < 0.4", want: 1, wantOk: true},
+		{expr: "!(waiting_queue_size == 4)", want: 0, wantOk: true},
+		{expr: "waiting_queue_size != 4 == 0", want: 1, wantOk: true},
+		{expr: "1e-1 * 10", want: 1, wantOk: true},
+		{expr: "custom.missing < 1", wantOk: false},
+		{expr: "waiting_queue_size / (running_queue_size - 2)", wantOk: false},
+	}
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			expr, err := parseExpression(test.expr)
+			if err != nil {
+				t.Fatalf("parseExpression() unexpected error: %v", err)
+			}
+			got, ok := expr.evaluate(pod)
+			if ok != test.wantOk || (ok && got != test.want) {
+				t.Errorf("evaluate() = %v, %v, want %v, %v", got, ok, test.want, test.wantOk)
+			}
+		})
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"unknown_metric < 1",
+		"custom. < 1",
+		"waiting_queue_size <",
+		"(waiting_queue_size",
+		"waiting_queue_size)",
+		"waiting_queue_size % 2",
+		"1.2.3",
+	} {
+		if _, err := parseExpression(src); err == nil {
+			t.Errorf("parseExpression(%q) expected error", src)
+		}
+	}
+	exprs, err := parseExpressions("custom.a < 1; ;waiting_queue_size > 0")
+	if err != nil || len(exprs) != 2 {
+		t.Errorf("parseExpressions() = %v, %v, want 2 expressions", exprs, err)
+	}
+}
+
+func TestCustomExpressionFilters(t *testing.T) {
+	pods := []*types.PodMetrics{
+		{
+			Pod:     &backendmetrics.Pod{NamespacedName: k8stypes.NamespacedName{Name: "pod1"}},
+			Metrics: &backendmetrics.Metrics{Custom: map[string]float64{"batch_fill_ratio": 0.95}},
+		},
+		{
+			Pod:     &backendmetrics.Pod{NamespacedName: k8stypes.NamespacedName{Name: "pod2"}},
+			Metrics: &backendmetrics.Metrics{Custom: map[string]float64{"batch_fill_ratio": 0.5}},
+		},
+		{
+			Pod:     &backendmetrics.Pod{NamespacedName: k8stypes.NamespacedName{Name: "pod3"}},
+			Metrics: &backendmetrics.Metrics{Custom: map[string]float64{"batch_fill_ratio": 0.1}},
+		},
+		{
+			Pod:     &backendmetrics.Pod{NamespacedName: k8stypes.NamespacedName{Name: "pod4"}},
+			Metrics: &backendmetrics.Metrics{},
+		},
+	}
+	tests := []struct {
+		name   string
+		filter *basicFilter
+		input  []*types.PodMetrics
+		output []*types.PodMetrics
+		err    bool
+	}{
+		{
+			name:   "predicates",
+			filter: newExpressionPredicateFilter(mustParseExpressions(t, "custom.batch_fill_ratio < 0.9; custom.batch_fill_ratio > 0.2")),
+			input:  pods,
+			output: []*types.PodMetrics{pods[1]},
+		},
+		{
+			name:   "no pod satisfies the predicates",
+			filter: newExpressionPredicateFilter(mustParseExpressions(t, "custom.batch_fill_ratio > 1")),
+			input:  pods,
+			err:    true,
+		},
+		{
+			name:   "least score",
+			filter: newLeastScoreFilter(mustParseExpressions(t, "custom.batch_fill_ratio")[0]),
+			input:  pods,
+			output: []*types.PodMetrics{pods[2]},
+		},
+		{
+			name:   "no pod has a score",
+			filter: newLeastScoreFilter(mustParseExpressions(t, "custom.batch_fill_ratio")[0]),
+			input:  pods[3:],
+			output: pods[3:],
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := types.NewContext(context.Background(), &types.LLMRequest{}, test.input)
+			got, err := test.filter.Filter(ctx, test.input)
+			if test.err != (err != nil) {
+				t.Errorf("Unexpected error, got %v, want %v", err, test.err)
+			}
+			if diff := cmp.Diff(test.output, got); diff != "" {
+				t.Errorf("Unexpected output (-want +got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestScheduleWithCustomExpressions(t *testing.T) {
+	pod := func(name string, fill float64) *backendmetrics.FakePodMetrics {
+		return &backendmetrics.FakePodMetrics{
+			Pod: &backendmetrics.Pod{NamespacedName: k8stypes.NamespacedName{Name: name}},
+			Metrics: &backendmetrics.Metrics{
+				WaitingModels: map[string]int{},
+				Custom:        map[string]float64{"batch_fill_ratio": fill},
+			},
+		}
+	}
+	input := []*backendmetrics.FakePodMetrics{pod("pod1", 0.95), pod("pod2", 0.6), pod("pod3", 0.3)}
+	req := &types.LLMRequest{Model: "critical", ResolvedTargetModel: "critical", Critical: true}
+
+	tests := []struct {
+		name       string
+		predicates string
+		scorer     string
+		want       string
+	}{
+		{name: "predicate and scorer", predicates: "custom.batch_fill_ratio > 0.5", scorer: "custom.batch_fill_ratio", want: "pod2"},
+		{name: "scorer only", scorer: "custom.batch_fill_ratio", want: "pod3"},
+		{name: "unsatisfied predicates fall back to all pods", predicates: "custom.batch_fill_ratio > 1", scorer: "-custom.batch_fill_ratio", want: "pod1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var scorer *expression
+			if test.scorer != "" {
+				scorer = mustParseExpressions(t, test.scorer)[0]
+			}
+			scheduler := newScheduler(&fakeDataStore{pods: input}, mustParseExpressions(t, test.predicates), scorer)
+			got, err := scheduler.Schedule(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Schedule() unexpected error: %v", err)
+			}
+			if got.GetPod().NamespacedName.Name != test.want {
+				t.Errorf("Schedule() = %v, want %v", got.GetPod().NamespacedName.Name, test.want)
+			}
+		})
+	}
+}
+
+func TestNewSchedulerInvalidExpressions(t *testing.T) {
+	tests := []struct {
+		name       string
+		predicates string
+		scorer     string
+		wantErr    bool
+	}{
+		{name: "valid", predicates: "custom.batch_fill_ratio > 0.5", scorer: "custom.batch_fill_ratio"},
+		{name: "invalid predicates", predicates: "custom.batch_fill_ratio >", wantErr: true},
+		{name: "invalid scorer", scorer: "custom.batch_fill_ratio +", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			saved := config
+			t.Cleanup(func() { config = saved })
+			config.CustomPredicates, config.CustomScorer = test.predicates, test.scorer
+
+			_, err := NewScheduler(&fakeDataStore{})
+			if (err != nil) != test.wantErr {
+				t.Errorf("NewScheduler() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func mustParseExpressions(t *testing.T, src string) []*expression {
+	t.Helper()
+	exprs, err := parseExpressions(src)
+	if err != nil {
+		t.Fatalf("parseExpressions() unexpected error: %v", err)
+	}
+	return exprs
+}
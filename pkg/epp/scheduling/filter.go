@@ -18,6 +18,7 @@ package scheduling
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"math/rand"
 	"time"
@@ -139,21 +140,21 @@ var leastQueueFilter = &basicFilter{
 // results.
 // TODO: Compare this strategy with other strategies such as top K.
 func leastQueuingFilterFunc(ctx *types.Context, pods []*types.PodMetrics) ([]*types.PodMetrics, error) {
-	min := math.MaxInt
-	max := 0
+	min := math.MaxFloat64
+	var max float64 = 0
 	filtered := []*types.PodMetrics{}
 
 	for _, pod := range pods {
-		if pod.WaitingQueueSize <= min {
-			min = pod.WaitingQueueSize
+		if queueSize(pod) <= min {
+			min = queueSize(pod)
 		}
-		if pod.WaitingQueueSize >= max {
-			max = pod.WaitingQueueSize
+		if queueSize(pod) >= max {
+			max = queueSize(pod)
 		}
 	}
 
 	for _, pod := range pods {
-		if pod.WaitingQueueSize >= min && pod.WaitingQueueSize <= min+(max-min)/len(pods) {
+		if queueSize(pod) >= min && queueSize(pod) <= min+(max-min)/float64(len(pods)) {
 			filtered = append(filtered, pod)
 		}
 	}
@@ -182,22 +183,67 @@ func leastKVCacheFilterFunc(ctx *types.Context, pods []*types.PodMetrics) ([]*ty
 	filtered := []*types.PodMetrics{}
 
 	for _, pod := range pods {
-		if pod.KVCacheUsagePercent <= min {
-			min = pod.KVCacheUsagePercent
+		if kvCacheUsage(pod) <= min {
+			min = kvCacheUsage(pod)
 		}
-		if pod.KVCacheUsagePercent >= max {
-			max = pod.KVCacheUsagePercent
+		if kvCacheUsage(pod) >= max {
+			max = kvCacheUsage(pod)
 		}
 	}
 
 	for _, pod := range pods {
-		if pod.KVCacheUsagePercent >= min && pod.KVCacheUsagePercent <= min+(max-min)/float64(len(pods)) {
+		if kvCacheUsage(pod) >= min && kvCacheUsage(pod) <= min+(max-min)/float64(len(pods)) {
 			filtered = append(filtered, pod)
 		}
 	}
 	return filtered, nil
 }
 
+// newExpressionPredicateFilter returns a filter keeping the pods that satisfy all the predicates.
+func newExpressionPredicateFilter(predicates []*expression) *basicFilter {
+	pp := predicates[0].predicate()
+	for _, predicate := range predicates[1:] {
+		pp = pp.and(predicate.predicate())
+	}
+	return &basicFilter{
+		name:   fmt.Sprintf("custom predicates %v", predicates),
+		filter: toFilterFunc(pp),
+	}
+}
+
+// newLeastScoreFilter returns a filter keeping the pods with the lowest score, the same way as
+// leastQueuingFilterFunc. The pods with an undefined score are only kept if no pod has a score.
+func newLeastScoreFilter(score *expression) *basicFilter {
+	return &basicFilter{
+		name: fmt.Sprintf("least custom score %v", score),
+		filter: func(ctx *types.Context, pods []*types.PodMetrics) ([]*types.PodMetrics, error) {
+			min := math.MaxFloat64
+			max := -math.MaxFloat64
+			scores := make(map[*types.PodMetrics]float64, len(pods))
+			for _, pod := range pods {
+				value, ok := score.evaluate(pod)
+				if !ok {
+					continue
+				}
+				scores[pod] = value
+				min = math.Min(min, value)
+				max = math.Max(max, value)
+			}
+			if len(scores) == 0 {
+				return pods, nil
+			}
+
+			filtered := []*types.PodMetrics{}
+			for _, pod := range pods {
+				if value, ok := scores[pod]; ok && value <= min+(max-min)/float64(len(scores)) {
+					filtered = append(filtered, pod)
+				}
+			}
+			return filtered, nil
+		},
+	}
+}
+
 var loRAAffinityFilter = &basicFilter{
 	name:   "affinity LoRA",
 	filter: loRASoftAffinityFilterFunc,
@@ -257,18 +303,34 @@ func loRASoftAffinityFilterFunc(ctx *types.Context, pods []*types.PodMetrics) ([
 	return filtered_available, nil
 }
 
+// queueSize returns the queue size of the pod the filters act on, smoothed if so configured.
+func queueSize(pod *types.PodMetrics) float64 {
+	if config.UseSmoothedMetrics {
+		return pod.SmoothedWaitingQueueSize
+	}
+	return float64(pod.WaitingQueueSize)
+}
+
+// kvCacheUsage returns the KV cache usage of the pod the filters act on, smoothed if so configured.
+func kvCacheUsage(pod *types.PodMetrics) float64 {
+	if config.UseSmoothedMetrics {
+		return pod.SmoothedKVCacheUsagePercent
+	}
+	return pod.KVCacheUsagePercent
+}
+
 // podPredicate is a filter function to check whether a pod is desired.
 type podPredicate func(req *types.LLMRequest, pod *types.PodMetrics) bool
 
 func queueThresholdPredicate(queueThreshold int) podPredicate {
 	return func(req *types.LLMRequest, pod *types.PodMetrics) bool {
-		return pod.WaitingQueueSize <= queueThreshold
+		return queueSize(pod) <= float64(queueThreshold)
 	}
 }
 
 func kvCacheThresholdPredicate(kvCacheThreshold float64) podPredicate {
 	return func(req *types.LLMRequest, pod *types.PodMetrics) bool {
-		return pod.KVCacheUsagePercent <= kvCacheThreshold
+		return kvCacheUsage(pod) <= kvCacheThreshold
 	}
 }
 
@@ -277,3 +339,11 @@ func (pp podPredicate) and(another podPredicate) podPredicate {
 		return pp(req, pod) && another(req, pod)
 	}
 }
+
+// scrapeHealthyPredicate checks that the metrics of the pod were scraped successfully within the
+// last failureThreshold attempts.
+func scrapeHealthyPredicate(failureThreshold int) podPredicate {
+	return func(req *types.LLMRequest, pod *types.PodMetrics) bool {
+		return pod.ScrapeHealth == nil || pod.ScrapeHealth.ConsecutiveFailures < failureThreshold
+	}
+}
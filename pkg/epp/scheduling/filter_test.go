@@ -187,6 +187,46 @@ func TestFilterFunc(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "scrapeHealthyPredicate",
+			f:    toFilterFunc(scrapeHealthyPredicate(3)),
+			input: []*types.PodMetrics{
+				{
+					// Never scraped, should be returned.
+					Metrics: &backendmetrics.Metrics{WaitingQueueSize: 0},
+				},
+				{
+					// Failed fewer times than the threshold, should be returned.
+					Metrics:      &backendmetrics.Metrics{WaitingQueueSize: 1},
+					ScrapeHealth: &backendmetrics.ScrapeHealth{ConsecutiveFailures: 2},
+				},
+				{
+					// Failed as many times as the threshold, should not be returned.
+					Metrics:      &backendmetrics.Metrics{WaitingQueueSize: 2},
+					ScrapeHealth: &backendmetrics.ScrapeHealth{ConsecutiveFailures: 3},
+				},
+			},
+			output: []*types.PodMetrics{
+				{
+					Metrics: &backendmetrics.Metrics{WaitingQueueSize: 0},
+				},
+				{
+					Metrics:      &backendmetrics.Metrics{WaitingQueueSize: 1},
+					ScrapeHealth: &backendmetrics.ScrapeHealth{ConsecutiveFailures: 2},
+				},
+			},
+		},
+		{
+			name: "scrapeHealthyPredicate no healthy pods",
+			f:    toFilterFunc(scrapeHealthyPredicate(1)),
+			input: []*types.PodMetrics{
+				{
+					Metrics:      &backendmetrics.Metrics{},
+					ScrapeHealth: &backendmetrics.ScrapeHealth{ConsecutiveFailures: 1},
+				},
+			},
+			err: true,
+		},
 	}
 
 	for _, test := range tests {
@@ -306,3 +346,42 @@ func TestLoRASoftAffinityDistribution(t *testing.T) {
 			actualAvailablePercent, availableLowerBound, availableUpperBound)
 	}
 }
+
+func TestFiltersOnSmoothedMetrics(t *testing.T) {
+	// pod1 has a momentary spike in its queue, while pod2 is steadily loaded.
+	pods := []*types.PodMetrics{
+		{
+			Pod:     &backendmetrics.Pod{NamespacedName: k8stypes.NamespacedName{Name: "pod1"}},
+			Metrics: &backendmetrics.Metrics{WaitingQueueSize: 10, SmoothedWaitingQueueSize: 1, KVCacheUsagePercent: 0.9, SmoothedKVCacheUsagePercent: 0.2},
+		},
+		{
+			Pod:     &backendmetrics.Pod{NamespacedName: k8stypes.NamespacedName{Name: "pod2"}},
+			Metrics: &backendmetrics.Metrics{WaitingQueueSize: 2, SmoothedWaitingQueueSize: 6, KVCacheUsagePercent: 0.3, SmoothedKVCacheUsagePercent: 0.6},
+		},
+	}
+	tests := []struct {
+		name     string
+		smoothed bool
+		want     string
+	}{
+		{name: "raw", smoothed: false, want: "pod2"},
+		{name: "smoothed", smoothed: true, want: "pod1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer func(orig bool) { config.UseSmoothedMetrics = orig }(config.UseSmoothedMetrics)
+			config.UseSmoothedMetrics = test.smoothed
+
+			ctx := types.NewContext(context.Background(), &types.LLMRequest{}, pods)
+			for _, f := range []filterFunc{leastQueuingFilterFunc, leastKVCacheFilterFunc} {
+				got, err := f(ctx, pods)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(got) != 1 || got[0].NamespacedName.Name != test.want {
+					t.Errorf("Unexpected output %v, want %s", got, test.want)
+				}
+			}
+		})
+	}
+}
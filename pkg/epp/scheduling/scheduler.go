@@ -36,6 +36,17 @@ type Config struct {
 	QueueThresholdCritical int
 	QueueingThresholdLoRA  int
 	LoraAffinityThreshold  float64
+	ScrapeFailureThreshold int
+	// UseSmoothedMetrics makes the filters act on the smoothed queue size and KV cache usage of the
+	// pods, rather than on their last values. It is set with SetUseSmoothedMetrics, when the metrics
+	// are smoothed.
+	UseSmoothedMetrics bool
+	// CustomPredicates are semicolon-separated expressions over the metrics of the pods, such as
+	// "custom.batch_fill_ratio < 0.9". The pods satisfying all of them are preferred.
+	CustomPredicates string
+	// CustomScorer is an expression over the metrics of the pods, the pods with the lowest score are
+	// preferred among the candidates of the filters.
+	CustomScorer string
 }
 
 const (
@@ -44,6 +55,7 @@ const (
 	defaultQueueThresholdCritical = 5
 	defaultQueueingThresholdLoRA  = 128
 	defaultLoraAffinityThreshold  = 0.999
+	defaultScrapeFailureThreshold = 3
 )
 
 // LoadConfig loads configuration from environment variables
@@ -56,6 +68,9 @@ func LoadConfig() Config {
 		QueueThresholdCritical: envutil.GetEnvInt("QUEUE_THRESHOLD_CRITICAL", defaultQueueThresholdCritical, baseLogger),
 		QueueingThresholdLoRA:  envutil.GetEnvInt("QUEUING_THRESHOLD_LORA", defaultQueueingThresholdLoRA, baseLogger),
 		LoraAffinityThreshold:  envutil.GetEnvFloat("LORA_AFFINITY_THRESHOLD", defaultLoraAffinityThreshold, baseLogger),
+		ScrapeFailureThreshold: envutil.GetEnvInt("SCRAPE_FAILURE_THRESHOLD", defaultScrapeFailureThreshold, baseLogger),
+		CustomPredicates:       envutil.GetEnvString("CUSTOM_PREDICATES", "", baseLogger),
+		CustomScorer:           envutil.GetEnvString("CUSTOM_SCORER", "", baseLogger),
 	}
 
 	baseLogger.V(logutil.DEFAULT).Info("Scheduler configuration loaded", "config", config)
@@ -99,6 +114,13 @@ var (
 		nextOnFailure: dropRequestFilter,
 	}
 
+	// scrapeHealthyFilter drops the pods whose metrics could not be scraped for a while, as their
+	// metrics no longer reflect their load. If no pod is healthy, all pods are considered.
+	scrapeHealthyFilter = &basicFilter{
+		name:   "healthy metrics scrapes",
+		filter: toFilterFunc(scrapeHealthyPredicate(config.ScrapeFailureThreshold)),
+	}
+
 	hasCapacityFilter = &basicFilter{
 		name:   "has capacity for sheddable requests",
 		filter: toFilterFunc(queueThresholdPredicate(config.QueueThresholdCritical).and(kvCacheThresholdPredicate(config.KVCacheThreshold))),
@@ -115,11 +137,47 @@ var (
 	}
 )
 
-func NewScheduler(datastore Datastore) *Scheduler {
+// SetUseSmoothedMetrics sets whether the filters act on the smoothed metrics of the pods. It must be
+// called before any scheduler is used.
+func SetUseSmoothedMetrics(use bool) {
+	config.UseSmoothedMetrics = use
+}
+
+// NewScheduler returns the scheduler configured by the environment. It fails if the custom
+// predicates or scorer are invalid.
+func NewScheduler(datastore Datastore) (*Scheduler, error) {
+	predicates, err := parseExpressions(config.CustomPredicates)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CUSTOM_PREDICATES: %w", err)
+	}
+	var scorer *expression
+	if config.CustomScorer != "" {
+		if scorer, err = parseExpression(config.CustomScorer); err != nil {
+			return nil, fmt.Errorf("invalid CUSTOM_SCORER: %w", err)
+		}
+	}
+	return newScheduler(datastore, predicates, scorer), nil
+}
+
+// newScheduler returns a scheduler preferring the pods that satisfy the predicates, and then the
+// ones with the lowest score among the candidates of the filters. The scorer may be nil.
+func newScheduler(datastore Datastore, predicates []*expression, scorer *expression) *Scheduler {
+	critical, sheddable := Filter(lowLatencyFilter), Filter(sheddableRequestFilter)
+	if scorer != nil {
+		leastScoreFilter := newLeastScoreFilter(scorer)
+		critical = &decisionTreeFilter{current: critical, nextOnSuccess: leastScoreFilter}
+		sheddable = &decisionTreeFilter{current: sheddable, nextOnSuccess: leastScoreFilter}
+	}
+	if len(predicates) > 0 {
+		// If no pod satisfies the predicates, all pods are considered.
+		predicateFilter := newExpressionPredicateFilter(predicates)
+		critical = &decisionTreeFilter{current: predicateFilter, nextOnSuccessOrFailure: critical}
+		sheddable = &decisionTreeFilter{current: predicateFilter, nextOnSuccessOrFailure: sheddable}
+	}
 	return &Scheduler{
 		datastore:              datastore,
-		criticalRequestFilter:  lowLatencyFilter,
-		sheddableRequestFilter: sheddableRequestFilter,
+		criticalRequestFilter:  &decisionTreeFilter{current: scrapeHealthyFilter, nextOnSuccessOrFailure: critical},
+		sheddableRequestFilter: &decisionTreeFilter{current: scrapeHealthyFilter, nextOnSuccessOrFailure: sheddable},
 	}
 }
 
@@ -202,11 +202,63 @@ func TestSchedule(t *testing.T) {
 			output: nil,
 			err:    true,
 		},
+		{
+			name: "critical request, pod failing to be scraped is skipped",
+			req: &types.LLMRequest{
+				Model:               "critical",
+				ResolvedTargetModel: "critical",
+				Critical:            true,
+			},
+			// pod2 will be picked because the metrics of pod1 could not be scraped recently,
+			// despite pod1 reporting a lower queue size.
+			input: []*backendmetrics.FakePodMetrics{
+				{
+					Pod: &backendmetrics.Pod{NamespacedName: k8stypes.NamespacedName{Name: "pod1"}},
+					Metrics: &backendmetrics.Metrics{
+						WaitingQueueSize:    0,
+						KVCacheUsagePercent: 0.1,
+						MaxActiveModels:     2,
+						ActiveModels: map[string]int{
+							"critical": 1,
+						},
+					},
+					Health: &backendmetrics.ScrapeHealth{ConsecutiveFailures: 10, LastError: "connection refused"},
+				},
+				{
+					Pod: &backendmetrics.Pod{NamespacedName: k8stypes.NamespacedName{Name: "pod2"}},
+					Metrics: &backendmetrics.Metrics{
+						WaitingQueueSize:    10,
+						KVCacheUsagePercent: 0.5,
+						MaxActiveModels:     2,
+						ActiveModels: map[string]int{
+							"critical": 1,
+						},
+					},
+					Health: &backendmetrics.ScrapeHealth{},
+				},
+			},
+			output: &types.PodMetrics{
+				Pod: &backendmetrics.Pod{NamespacedName: k8stypes.NamespacedName{Name: "pod2"}},
+				Metrics: &backendmetrics.Metrics{
+					WaitingQueueSize:    10,
+					KVCacheUsagePercent: 0.5,
+					MaxActiveModels:     2,
+					ActiveModels: map[string]int{
+						"critical": 1,
+					},
+					WaitingModels: map[string]int{},
+				},
+				ScrapeHealth: &backendmetrics.ScrapeHealth{},
+			},
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			scheduler := NewScheduler(&fakeDataStore{pods: test.input})
+			scheduler, err := NewScheduler(&fakeDataStore{pods: test.input})
+			if err != nil {
+				t.Fatalf("NewScheduler() unexpected error: %v", err)
+			}
 			got, err := scheduler.Schedule(context.Background(), test.req)
 			if test.err != (err != nil) {
 				t.Errorf("Unexpected error, got %v, want %v", err, test.err)
@@ -27,12 +27,24 @@ import (
 
 // LLMRequest is a structured representation of the fields we parse out of the LLMRequest body.
 type LLMRequest struct {
+	// Path is the OpenAI API path of the request, such as "/v1/chat/completions". It is empty if the
+	// request path is unknown.
+	Path  string
 	Model string
 	// Target models is a map of target model name to weight.
 	TargetModels map[string]int
 	// Resolved target model is the final target model after traffic split.
 	ResolvedTargetModel string
 	Critical            bool
+	// Prompt is the text of the prompt, chat messages or input of the request. Token ID prompts are
+	// not included.
+	Prompt string
+	// MaxTokens is the maximum number of tokens to generate, 0 if the request doesn't limit it.
+	MaxTokens int
+	// Stream is true if the response is streamed.
+	Stream bool
+	// N is the number of choices to generate for each prompt.
+	N int
 }
 
 // Context holds contextual information during a scheduling operation.
@@ -67,6 +79,8 @@ func (pm *PodMetrics) GetMetrics() *backendmetrics.Metrics {
 type PodMetrics struct {
 	*backendmetrics.Pod
 	*backendmetrics.Metrics
+	// ScrapeHealth of the metrics, nil if the pod was not scraped yet.
+	ScrapeHealth *backendmetrics.ScrapeHealth
 }
 
 func NewContext(ctx context.Context, req *LLMRequest, pods []*PodMetrics) *Context {
@@ -82,7 +96,11 @@ func NewContext(ctx context.Context, req *LLMRequest, pods []*PodMetrics) *Conte
 func ToSchedulerPodMetrics(pods []backendmetrics.PodMetrics) []*PodMetrics {
 	pm := make([]*PodMetrics, 0, len(pods))
 	for _, pod := range pods {
-		pm = append(pm, &PodMetrics{pod.GetPod().Clone(), pod.GetMetrics().Clone()})
+		pm = append(pm, &PodMetrics{
+			Pod:          pod.GetPod().Clone(),
+			Metrics:      pod.GetMetrics().Clone(),
+			ScrapeHealth: pod.GetScrapeHealth().Clone(),
+		})
 	}
 	return pm
 }
@@ -40,9 +40,14 @@ func init() {
 }
 
 // DefaultManagerOptions returns the default options used to create the manager.
-func DefaultManagerOptions(namespace, name string) ctrl.Options {
+// When leaderElection is enabled, only the elected replica writes status; all replicas serve traffic.
+func DefaultManagerOptions(namespace, name string, leaderElection bool) ctrl.Options {
 	return ctrl.Options{
-		Scheme: scheme,
+		Scheme:                        scheme,
+		LeaderElection:                leaderElection,
+		LeaderElectionID:              fmt.Sprintf("epp-%s-%s.gateway-api-inference-extension.sigs.k8s.io", namespace, name),
+		LeaderElectionNamespace:       namespace,
+		LeaderElectionReleaseOnCancel: true,
 		Cache: cache.Options{
 			ByObject: map[client.Object]cache.ByObject{
 				&corev1.Pod{}: {
@@ -70,8 +75,8 @@ func DefaultManagerOptions(namespace, name string) ctrl.Options {
 }
 
 // NewDefaultManager creates a new controller manager with default configuration.
-func NewDefaultManager(namespace, name string, restConfig *rest.Config) (ctrl.Manager, error) {
-	manager, err := ctrl.NewManager(restConfig, DefaultManagerOptions(namespace, name))
+func NewDefaultManager(namespace, name string, restConfig *rest.Config, leaderElection bool) (ctrl.Manager, error) {
+	manager, err := ctrl.NewManager(restConfig, DefaultManagerOptions(namespace, name, leaderElection))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create controller manager: %v", err)
 	}
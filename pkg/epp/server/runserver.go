@@ -20,6 +20,7 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
@@ -36,6 +37,7 @@ import (
 	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/datastore"
 	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/handlers"
 	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/scheduling"
+	"sigs.k8s.io/gateway-api-inference-extension/pkg/epp/webhook"
 )
 
 // ExtProcServerRunner provides methods to manage an external process server.
@@ -50,6 +52,19 @@ type ExtProcServerRunner struct {
 	CertPath                                 string
 	UseStreaming                             bool
 	RefreshPrometheusMetricsInterval         time.Duration
+	PoolStatusUpdateInterval                 time.Duration
+	EnableValidationWebhook                  bool
+	InjectStreamUsage                        bool
+	FilterModelsByCapacity                   bool
+	UnregisteredModelPolicy                  handlers.UnregisteredModelPolicy
+	DefaultModelName                         string
+	ModelNameHeader                          string
+	MaxRequestBodyBytes                      int64
+	// UseSmoothedMetrics makes the scheduler act on the smoothed metrics of the pods.
+	UseSmoothedMetrics bool
+
+	// serving reports whether the ext-proc server is listening, for the InferencePool status.
+	serving atomic.Bool
 
 	// This should only be used in tests. We won't need this once we don't inject metrics in the tests.
 	// TODO:(https://github.com/kubernetes-sigs/gateway-api-inference-extension/issues/432) Cleanup
@@ -58,14 +73,25 @@ type ExtProcServerRunner struct {
 
 // Default values for CLI flags in main
 const (
-	DefaultGrpcPort                                 = 9002                             // default for --grpcPort
-	DefaultDestinationEndpointHintMetadataNamespace = "envoy.lb"                       // default for --destinationEndpointHintMetadataNamespace
-	DefaultDestinationEndpointHintKey               = "x-gateway-destination-endpoint" // default for --destinationEndpointHintKey
-	DefaultPoolName                                 = ""                               // required but no default
-	DefaultPoolNamespace                            = "default"                        // default for --poolNamespace
-	DefaultRefreshMetricsInterval                   = 50 * time.Millisecond            // default for --refreshMetricsInterval
-	DefaultRefreshPrometheusMetricsInterval         = 5 * time.Second                  // default for --refreshPrometheusMetricsInterval
-	DefaultSecureServing                            = true                             // default for --secureServing
+	DefaultGrpcPort                                 = 9002                                   // default for --grpcPort
+	DefaultDestinationEndpointHintMetadataNamespace = "envoy.lb"                             // default for --destinationEndpointHintMetadataNamespace
+	DefaultDestinationEndpointHintKey               = "x-gateway-destination-endpoint"       // default for --destinationEndpointHintKey
+	DefaultPoolName                                 = ""                                     // required but no default
+	DefaultPoolNamespace                            = "default"                              // default for --poolNamespace
+	DefaultRefreshMetricsInterval                   = 50 * time.Millisecond                  // default for --refreshMetricsInterval
+	DefaultScrapeWorkers                            = backendmetrics.DefaultScrapeWorkers    // default for --scrapeWorkers
+	DefaultScrapeMaxBackoff                         = backendmetrics.DefaultScrapeMaxBackoff // default for --scrapeMaxBackoff
+	DefaultQueueSmoothing                           = "none"                                 // default for --queueSmoothing
+	DefaultKVCacheSmoothing                         = "none"                                 // default for --kvCacheSmoothing
+	DefaultRefreshPrometheusMetricsInterval         = 5 * time.Second                        // default for --refreshPrometheusMetricsInterval
+	DefaultPoolStatusUpdateInterval                 = 10 * time.Second                       // default for --poolStatusUpdateInterval
+	DefaultSecureServing                            = true                                   // default for --secureServing
+	DefaultEnableLeaderElection                     = false                                  // default for --enableLeaderElection
+	DefaultEnableValidationWebhook                  = false                                  // default for --enableValidationWebhook
+	DefaultWebhookPort                              = 9443                                   // default for --webhookPort
+	DefaultInjectStreamUsage                        = false                                  // default for --injectStreamUsage
+	DefaultFilterModelsByCapacity                   = false                                  // default for --filterModelsByCapacity
+	DefaultUnregisteredModelPolicy                  = handlers.UnregisteredModelReject       // default for --unregisteredModelPolicy
 )
 
 func NewDefaultExtProcServerRunner() *ExtProcServerRunner {
@@ -77,6 +103,8 @@ func NewDefaultExtProcServerRunner() *ExtProcServerRunner {
 		PoolNamespace:                            DefaultPoolNamespace,
 		SecureServing:                            DefaultSecureServing,
 		RefreshPrometheusMetricsInterval:         DefaultRefreshPrometheusMetricsInterval,
+		PoolStatusUpdateInterval:                 DefaultPoolStatusUpdateInterval,
+		UnregisteredModelPolicy:                  DefaultUnregisteredModelPolicy,
 		// Datastore can be assigned later.
 	}
 }
@@ -103,7 +131,8 @@ func (r *ExtProcServerRunner) SetupWithManager(ctx context.Context, mgr ctrl.Man
 			Name:      r.PoolName,
 			Namespace: r.PoolNamespace,
 		},
-		Record: mgr.GetEventRecorderFor("InferenceModel"),
+		Record:  mgr.GetEventRecorderFor("InferenceModel"),
+		Elected: mgr.Elected(),
 	}).SetupWithManager(ctx, mgr); err != nil {
 		return fmt.Errorf("failed setting up InferenceModelReconciler: %w", err)
 	}
@@ -115,6 +144,30 @@ func (r *ExtProcServerRunner) SetupWithManager(ctx context.Context, mgr ctrl.Man
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("failed setting up EndpointSliceReconciler: %v", err)
 	}
+
+	if r.PoolStatusUpdateInterval > 0 {
+		if err := mgr.Add(runnable.RequireLeaderElection(&controller.InferencePoolStatusUpdater{
+			Client:    mgr.GetClient(),
+			Datastore: r.Datastore,
+			PoolNamespacedName: types.NamespacedName{
+				Name:      r.PoolName,
+				Namespace: r.PoolNamespace,
+			},
+			Interval: r.PoolStatusUpdateInterval,
+			Serving:  r.serving.Load,
+		})); err != nil {
+			return fmt.Errorf("failed setting up InferencePoolStatusUpdater: %w", err)
+		}
+	}
+
+	if r.EnableValidationWebhook {
+		if err := (&webhook.InferenceModelValidator{}).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("failed setting up InferenceModel validating webhook: %w", err)
+		}
+		if err := (&webhook.InferencePoolValidator{}).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("failed setting up InferencePool validating webhook: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -122,11 +175,16 @@ func (r *ExtProcServerRunner) SetupWithManager(ctx context.Context, mgr ctrl.Man
 // The runnable implements LeaderElectionRunnable with leader election disabled.
 func (r *ExtProcServerRunner) AsRunnable(logger logr.Logger) manager.Runnable {
 	return runnable.NoLeaderElection(manager.RunnableFunc(func(ctx context.Context) error {
+		scheduling.SetUseSmoothedMetrics(r.UseSmoothedMetrics)
+		scheduler, err := scheduling.NewScheduler(r.Datastore)
+		if err != nil {
+			logger.Error(err, "Failed to create scheduler")
+			return err
+		}
 		backendmetrics.StartMetricsLogger(ctx, r.Datastore, r.RefreshPrometheusMetricsInterval)
 		var srv *grpc.Server
 		if r.SecureServing {
 			var cert tls.Certificate
-			var err error
 			if r.CertPath != "" {
 				cert, err = tls.LoadX509KeyPair(r.CertPath+"/tls.crt", r.CertPath+"/tls.key")
 			} else {
@@ -146,13 +204,20 @@ func (r *ExtProcServerRunner) AsRunnable(logger logr.Logger) manager.Runnable {
 		} else {
 			srv = grpc.NewServer()
 		}
-		extProcServer := handlers.NewStreamingServer(scheduling.NewScheduler(r.Datastore), r.DestinationEndpointHintMetadataNamespace, r.DestinationEndpointHintKey, r.Datastore)
+		extProcServer := handlers.NewStreamingServer(scheduler, r.DestinationEndpointHintMetadataNamespace, r.DestinationEndpointHintKey, r.Datastore, handlers.Config{
+			InjectStreamUsage:       r.InjectStreamUsage,
+			FilterModelsByCapacity:  r.FilterModelsByCapacity,
+			UnregisteredModelPolicy: r.UnregisteredModelPolicy,
+			DefaultModelName:        r.DefaultModelName,
+			ModelNameHeader:         r.ModelNameHeader,
+			MaxRequestBodyBytes:     r.MaxRequestBodyBytes,
+		})
 		extProcPb.RegisterExternalProcessorServer(
 			srv,
 			extProcServer,
 		)
 
 		// Forward to the gRPC runnable.
-		return runnable.GRPCServer("ext-proc", srv, r.GrpcPort).Start(ctx)
+		return runnable.ServingGRPCServer("ext-proc", srv, r.GrpcPort, &r.serving).Start(ctx)
 	}))
 }
@@ -49,3 +49,38 @@ func GetEnvInt(key string, defaultVal int, logger logr.Logger) int {
 		"key", key, "value", intVal)
 	return intVal
 }
+
+// GetEnvBool gets a bool from an environment variable with a default value
+func GetEnvBool(key string, defaultVal bool, logger logr.Logger) bool {
+	val, exists := os.LookupEnv(key)
+	if !exists {
+		logger.V(logutil.VERBOSE).Info("Environment variable not set, using default value",
+			"key", key, "defaultValue", defaultVal)
+		return defaultVal
+	}
+
+	boolVal, err := strconv.ParseBool(val)
+	if err != nil {
+		logger.V(logutil.VERBOSE).Info("Failed to parse environment variable as bool, using default value",
+			"key", key, "value", val, "error", err, "defaultValue", defaultVal)
+		return defaultVal
+	}
+
+	logger.V(logutil.VERBOSE).Info("Successfully loaded environment variable",
+		"key", key, "value", boolVal)
+	return boolVal
+}
+
+// GetEnvString gets a string from an environment variable with a default value
+func GetEnvString(key string, defaultVal string, logger logr.Logger) string {
+	val, exists := os.LookupEnv(key)
+	if !exists {
+		logger.V(logutil.VERBOSE).Info("Environment variable not set, using default value",
+			"key", key, "defaultValue", defaultVal)
+		return defaultVal
+	}
+
+	logger.V(logutil.VERBOSE).Info("Successfully loaded environment variable",
+		"key", key, "value", val)
+	return val
+}
@@ -142,3 +142,110 @@ func TestGetEnvInt(t *testing.T) {
 		})
 	}
 }
+
+func TestGetEnvBool(t *testing.T) {
+	logger := testr.New(t)
+
+	tests := []struct {
+		name       string
+		key        string
+		value      string
+		defaultVal bool
+		expected   bool
+		setup      func()
+		teardown   func()
+	}{
+		{
+			name:       "env variable exists and is valid",
+			key:        "TEST_BOOL",
+			value:      "true",
+			defaultVal: false,
+			expected:   true,
+			setup: func() {
+				os.Setenv("TEST_BOOL", "true")
+			},
+			teardown: func() {
+				os.Unsetenv("TEST_BOOL")
+			},
+		},
+		{
+			name:       "env variable exists but is invalid",
+			key:        "TEST_BOOL",
+			value:      "maybe",
+			defaultVal: true,
+			expected:   true,
+			setup: func() {
+				os.Setenv("TEST_BOOL", "maybe")
+			},
+			teardown: func() {
+				os.Unsetenv("TEST_BOOL")
+			},
+		},
+		{
+			name:       "env variable does not exist",
+			key:        "TEST_BOOL_MISSING",
+			defaultVal: true,
+			expected:   true,
+			setup:      func() {},
+			teardown:   func() {},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setup()
+			defer tc.teardown()
+
+			result := GetEnvBool(tc.key, tc.defaultVal, logger.V(logutil.VERBOSE))
+			if result != tc.expected {
+				t.Errorf("GetEnvBool(%s, %t) = %t, expected %t", tc.key, tc.defaultVal, result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetEnvString(t *testing.T) {
+	logger := testr.New(t)
+
+	tests := []struct {
+		name       string
+		key        string
+		defaultVal string
+		expected   string
+		setup      func()
+		teardown   func()
+	}{
+		{
+			name:       "env variable exists",
+			key:        "TEST_STRING",
+			defaultVal: "default",
+			expected:   "custom.batch_fill_ratio < 0.9",
+			setup: func() {
+				os.Setenv("TEST_STRING", "custom.batch_fill_ratio < 0.9")
+			},
+			teardown: func() {
+				os.Unsetenv("TEST_STRING")
+			},
+		},
+		{
+			name:       "env variable does not exist",
+			key:        "TEST_STRING_MISSING",
+			defaultVal: "default",
+			expected:   "default",
+			setup:      func() {},
+			teardown:   func() {},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setup()
+			defer tc.teardown()
+
+			result := GetEnvString(tc.key, tc.defaultVal, logger.V(logutil.VERBOSE))
+			if result != tc.expected {
+				t.Errorf("GetEnvString(%s, %s) = %s, expected %s", tc.key, tc.defaultVal, result, tc.expected)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ const (
 	ModelServerError               = "ModelServerError"
 	BadConfiguration               = "BadConfiguration"
 	InferencePoolResourceExhausted = "InferencePoolResourceExhausted"
+	RequestTooLarge                = "RequestTooLarge"
 )
 
 // Error returns a string version of the error.
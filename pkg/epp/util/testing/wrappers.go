@@ -140,11 +140,26 @@ func (m *InferenceModelWrapper) ModelName(modelName string) *InferenceModelWrapp
 	return m
 }
 
+func (m *InferenceModelWrapper) Aliases(aliases ...string) *InferenceModelWrapper {
+	m.Spec.Aliases = append(m.Spec.Aliases, aliases...)
+	return m
+}
+
+func (m *InferenceModelWrapper) Mutations(mutations ...v1alpha2.RequestMutation) *InferenceModelWrapper {
+	m.Spec.Mutations = append(m.Spec.Mutations, mutations...)
+	return m
+}
+
 func (m *InferenceModelWrapper) TargetModel(modelName string) *InferenceModelWrapper {
 	m.Spec.TargetModels = append(m.Spec.TargetModels, v1alpha2.TargetModel{Name: modelName})
 	return m
 }
 
+func (m *InferenceModelWrapper) WeightedTargetModel(modelName string, weight int32) *InferenceModelWrapper {
+	m.Spec.TargetModels = append(m.Spec.TargetModels, v1alpha2.TargetModel{Name: modelName, Weight: &weight})
+	return m
+}
+
 func (m *InferenceModelWrapper) PoolName(poolName string) *InferenceModelWrapper {
 	m.Spec.PoolRef = v1alpha2.PoolObjectReference{Name: v1alpha2.ObjectName(poolName)}
 	return m
@@ -0,0 +1,198 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+)
+
+// +kubebuilder:webhook:path=/validate-inference-networking-x-k8s-io-v1alpha2-inferencemodel,mutating=false,failurePolicy=fail,sideEffects=None,groups=inference.networking.x-k8s.io,resources=inferencemodels,verbs=create;update,versions=v1alpha2,name=vinferencemodel-v1alpha2.inference.networking.x-k8s.io,admissionReviewVersions=v1
+
+// InferenceModelValidator rejects InferenceModels that the endpoint picker would not be able to
+// route to, covering the rules the CRD schema can't express on its own.
+type InferenceModelValidator struct{}
+
+var _ admission.CustomValidator = &InferenceModelValidator{}
+
+func (v *InferenceModelValidator) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha2.InferenceModel{}).
+		WithValidator(v).
+		Complete()
+}
+
+func (v *InferenceModelValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, validateInferenceModelObject(obj)
+}
+
+func (v *InferenceModelValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, validateInferenceModelObject(newObj)
+}
+
+func (v *InferenceModelValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateInferenceModelObject(obj runtime.Object) error {
+	infModel, ok := obj.(*v1alpha2.InferenceModel)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected an InferenceModel but got %T", obj))
+	}
+	if errs := ValidateInferenceModel(infModel); len(errs) > 0 {
+		return apierrors.NewInvalid(v1alpha2.SchemeGroupVersion.WithKind("InferenceModel").GroupKind(), infModel.Name, errs)
+	}
+	return nil
+}
+
+// ValidateInferenceModel returns the list of problems found in the InferenceModel spec.
+func ValidateInferenceModel(infModel *v1alpha2.InferenceModel) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if infModel.Spec.ModelName == "" {
+		errs = append(errs, field.Required(specPath.Child("modelName"), ""))
+	}
+
+	poolRefPath := specPath.Child("poolRef")
+	if infModel.Spec.PoolRef.Name == "" {
+		errs = append(errs, field.Required(poolRefPath.Child("name"), ""))
+	}
+	if group := infModel.Spec.PoolRef.Group; group != "" && group != v1alpha2.GroupName {
+		errs = append(errs, field.NotSupported(poolRefPath.Child("group"), group, []string{v1alpha2.GroupName}))
+	}
+	if kind := infModel.Spec.PoolRef.Kind; kind != "" && kind != "InferencePool" {
+		errs = append(errs, field.NotSupported(poolRefPath.Child("kind"), kind, []string{"InferencePool"}))
+	}
+
+	errs = append(errs, validateAliases(infModel.Spec.ModelName, infModel.Spec.Aliases, specPath.Child("aliases"))...)
+	errs = append(errs, validateMutations(infModel.Spec.Mutations, specPath.Child("mutations"))...)
+	return append(errs, validateTargetModels(infModel.Spec.TargetModels, specPath.Child("targetModels"))...)
+}
+
+func validateAliases(modelName string, aliases []string, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := sets.New[string]()
+	for i, alias := range aliases {
+		idxPath := fldPath.Index(i)
+		switch {
+		case alias == "":
+			errs = append(errs, field.Required(idxPath, ""))
+		case alias == modelName:
+			errs = append(errs, field.Invalid(idxPath, alias, "must differ from the modelName"))
+		case seen.Has(alias):
+			errs = append(errs, field.Duplicate(idxPath, alias))
+		}
+		seen.Insert(alias)
+	}
+	return errs
+}
+
+func validateMutations(mutations []v1alpha2.RequestMutation, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, m := range mutations {
+		idxPath := fldPath.Index(i)
+		if m.Type != v1alpha2.MutationPrependSystemMessage {
+			if m.Field == "" {
+				errs = append(errs, field.Required(idxPath.Child("field"), fmt.Sprintf("must be set for %s mutations", m.Type)))
+			} else if m.Field == "model" {
+				errs = append(errs, field.Forbidden(idxPath.Child("field"), "the model can't be mutated"))
+			}
+		}
+
+		switch m.Type {
+		case v1alpha2.MutationSetIfAbsent:
+			if m.Value == nil {
+				errs = append(errs, field.Required(idxPath.Child("value"), "must be set for SetIfAbsent mutations"))
+			} else if !json.Valid(m.Value.Raw) {
+				errs = append(errs, field.Invalid(idxPath.Child("value"), string(m.Value.Raw), "must be valid JSON"))
+			}
+		case v1alpha2.MutationClamp:
+			if m.Min == nil && m.Max == nil {
+				errs = append(errs, field.Required(idxPath, "min or max must be set for Clamp mutations"))
+			}
+			lower, lowerErrs := parseBound(m.Min, idxPath.Child("min"))
+			upper, upperErrs := parseBound(m.Max, idxPath.Child("max"))
+			errs = append(append(errs, lowerErrs...), upperErrs...)
+			if m.Min != nil && m.Max != nil && len(lowerErrs) == 0 && len(upperErrs) == 0 && lower > upper {
+				errs = append(errs, field.Invalid(idxPath.Child("max"), *m.Max, "must not be less than min"))
+			}
+		case v1alpha2.MutationPrependSystemMessage:
+			if m.Content == "" {
+				errs = append(errs, field.Required(idxPath.Child("content"), "must be set for PrependSystemMessage mutations"))
+			}
+		}
+	}
+	return errs
+}
+
+func parseBound(bound *string, fldPath *field.Path) (float64, field.ErrorList) {
+	if bound == nil {
+		return 0, nil
+	}
+	value, err := strconv.ParseFloat(*bound, 64)
+	if err != nil {
+		return 0, field.ErrorList{field.Invalid(fldPath, *bound, "must be a decimal number")}
+	}
+	return value, nil
+}
+
+func validateTargetModels(targetModels []v1alpha2.TargetModel, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(targetModels) == 0 {
+		return errs
+	}
+
+	names := sets.New[string]()
+	weighted := 0
+	var weightSum int64
+	for i, tm := range targetModels {
+		idxPath := fldPath.Index(i)
+		if tm.Name == "" {
+			errs = append(errs, field.Required(idxPath.Child("name"), ""))
+		} else if names.Has(tm.Name) {
+			errs = append(errs, field.Duplicate(idxPath.Child("name"), tm.Name))
+		}
+		names.Insert(tm.Name)
+
+		if tm.Weight != nil {
+			weighted++
+			weightSum += int64(*tm.Weight)
+			if *tm.Weight < 0 {
+				errs = append(errs, field.Invalid(idxPath.Child("weight"), *tm.Weight, "must not be negative"))
+			}
+		}
+	}
+
+	if weighted > 0 && weighted < len(targetModels) {
+		errs = append(errs, field.Invalid(fldPath, fmt.Sprintf("%d of %d weighted", weighted, len(targetModels)),
+			"weights must be set for all target models, or none of them"))
+	} else if weighted > 0 && weightSum <= 0 {
+		errs = append(errs, field.Invalid(fldPath, weightSum, "the sum of the weights must be positive"))
+	}
+	return errs
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+	utiltest "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/testing"
+)
+
+func TestValidateInferenceModel(t *testing.T) {
+	tests := []struct {
+		name      string
+		model     *v1alpha2.InferenceModel
+		wantPaths []string
+	}{
+		{
+			name:  "Valid without target models",
+			model: utiltest.MakeInferenceModel("m").ModelName("my-model").PoolName("pool").ObjRef(),
+		},
+		{
+			name: "Valid unweighted target models",
+			model: utiltest.MakeInferenceModel("m").ModelName("my-model").PoolName("pool").
+				TargetModel("v1").TargetModel("v2").ObjRef(),
+		},
+		{
+			name: "Valid weighted target models",
+			model: utiltest.MakeInferenceModel("m").ModelName("my-model").PoolName("pool").
+				WeightedTargetModel("v1", 10).WeightedTargetModel("v2", 90).ObjRef(),
+		},
+		{
+			name: "Mixed weights",
+			model: utiltest.MakeInferenceModel("m").ModelName("my-model").PoolName("pool").
+				WeightedTargetModel("v1", 10).TargetModel("v2").ObjRef(),
+			wantPaths: []string{"spec.targetModels"},
+		},
+		{
+			name: "Zero weight sum",
+			model: utiltest.MakeInferenceModel("m").ModelName("my-model").PoolName("pool").
+				WeightedTargetModel("v1", 0).WeightedTargetModel("v2", 0).ObjRef(),
+			wantPaths: []string{"spec.targetModels"},
+		},
+		{
+			name: "Negative weight",
+			model: utiltest.MakeInferenceModel("m").ModelName("my-model").PoolName("pool").
+				WeightedTargetModel("v1", -1).WeightedTargetModel("v2", 5).ObjRef(),
+			wantPaths: []string{"spec.targetModels[0].weight"},
+		},
+		{
+			name: "Duplicate target names",
+			model: utiltest.MakeInferenceModel("m").ModelName("my-model").PoolName("pool").
+				TargetModel("v1").TargetModel("v2").TargetModel("v1").ObjRef(),
+			wantPaths: []string{"spec.targetModels[2].name"},
+		},
+		{
+			name: "Valid aliases",
+			model: utiltest.MakeInferenceModel("m").ModelName("my-model").PoolName("pool").
+				Aliases("my-alias", "my-model-*").ObjRef(),
+		},
+		{
+			name: "Invalid aliases",
+			model: utiltest.MakeInferenceModel("m").ModelName("my-model").PoolName("pool").
+				Aliases("my-alias", "", "my-model", "my-alias").ObjRef(),
+			wantPaths: []string{"spec.aliases[1]", "spec.aliases[2]", "spec.aliases[3]"},
+		},
+		{
+			name: "Valid mutations",
+			model: utiltest.MakeInferenceModel("m").ModelName("my-model").PoolName("pool").Mutations(
+				v1alpha2.RequestMutation{Type: v1alpha2.MutationSetIfAbsent, Field: "max_tokens", Value: &apiextensionsv1.JSON{Raw: []byte("256")}},
+				v1alpha2.RequestMutation{Type: v1alpha2.MutationClamp, Field: "temperature", Min: ptr.To("0"), Max: ptr.To("1.5")},
+				v1alpha2.RequestMutation{Type: v1alpha2.MutationClamp, Field: "max_tokens", Max: ptr.To("1024")},
+				v1alpha2.RequestMutation{Type: v1alpha2.MutationPrependSystemMessage, Content: "Be concise."},
+				v1alpha2.RequestMutation{Type: v1alpha2.MutationDrop, Field: "logit_bias"},
+			).ObjRef(),
+		},
+		{
+			name: "Invalid mutations",
+			model: utiltest.MakeInferenceModel("m").ModelName("my-model").PoolName("pool").Mutations(
+				v1alpha2.RequestMutation{Type: v1alpha2.MutationSetIfAbsent, Field: "max_tokens"},
+				v1alpha2.RequestMutation{Type: v1alpha2.MutationDrop, Field: "model"},
+				v1alpha2.RequestMutation{Type: v1alpha2.MutationClamp},
+				v1alpha2.RequestMutation{Type: v1alpha2.MutationClamp, Field: "temperature", Min: ptr.To("2"), Max: ptr.To("1")},
+				v1alpha2.RequestMutation{Type: v1alpha2.MutationPrependSystemMessage},
+				v1alpha2.RequestMutation{Type: v1alpha2.MutationSetIfAbsent, Field: "n", Value: &apiextensionsv1.JSON{Raw: []byte("{")}},
+			).ObjRef(),
+			wantPaths: []string{
+				"spec.mutations[0].value",
+				"spec.mutations[1].field",
+				"spec.mutations[2].field",
+				"spec.mutations[2]",
+				"spec.mutations[3].max",
+				"spec.mutations[4].content",
+				"spec.mutations[5].value",
+			},
+		},
+		{
+			name:      "Missing model name and pool",
+			model:     utiltest.MakeInferenceModel("m").ObjRef(),
+			wantPaths: []string{"spec.modelName", "spec.poolRef.name"},
+		},
+		{
+			name: "Unsupported pool kind",
+			model: func() *v1alpha2.InferenceModel {
+				m := utiltest.MakeInferenceModel("m").ModelName("my-model").PoolName("pool").ObjRef()
+				m.Spec.PoolRef.Kind = "Service"
+				return m
+			}(),
+			wantPaths: []string{"spec.poolRef.kind"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errs := ValidateInferenceModel(test.model)
+			if diff := cmp.Diff(test.wantPaths, errorPaths(errs)); diff != "" {
+				t.Errorf("Unexpected validation errors (-want +got): %s, errors: %v", diff, errs)
+			}
+		})
+	}
+}
+
+func errorPaths(errs field.ErrorList) []string {
+	var paths []string
+	for _, err := range errs {
+		paths = append(paths, err.Field)
+	}
+	return paths
+}
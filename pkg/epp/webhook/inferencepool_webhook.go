@@ -0,0 +1,166 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+	backendmetrics "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/backend/metrics"
+)
+
+// +kubebuilder:webhook:path=/validate-inference-networking-x-k8s-io-v1alpha2-inferencepool,mutating=false,failurePolicy=fail,sideEffects=None,groups=inference.networking.x-k8s.io,resources=inferencepools,verbs=create;update,versions=v1alpha2,name=vinferencepool-v1alpha2.inference.networking.x-k8s.io,admissionReviewVersions=v1
+
+// InferencePoolValidator rejects InferencePools with selectors that can never match a pod or
+// with an unusable extension reference.
+type InferencePoolValidator struct{}
+
+var _ admission.CustomValidator = &InferencePoolValidator{}
+
+func (v *InferencePoolValidator) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha2.InferencePool{}).
+		WithValidator(v).
+		Complete()
+}
+
+func (v *InferencePoolValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, validateInferencePoolObject(obj)
+}
+
+func (v *InferencePoolValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, validateInferencePoolObject(newObj)
+}
+
+func (v *InferencePoolValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateInferencePoolObject(obj runtime.Object) error {
+	pool, ok := obj.(*v1alpha2.InferencePool)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected an InferencePool but got %T", obj))
+	}
+	if errs := ValidateInferencePool(pool); len(errs) > 0 {
+		return apierrors.NewInvalid(v1alpha2.SchemeGroupVersion.WithKind("InferencePool").GroupKind(), pool.Name, errs)
+	}
+	return nil
+}
+
+// ValidateInferencePool returns the list of problems found in the InferencePool spec.
+func ValidateInferencePool(pool *v1alpha2.InferencePool) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	selectorPath := specPath.Child("selector")
+	if len(pool.Spec.Selector) == 0 {
+		errs = append(errs, field.Required(selectorPath, "must select at least one label"))
+	}
+	for k, v := range pool.Spec.Selector {
+		for _, msg := range validation.IsQualifiedName(string(k)) {
+			errs = append(errs, field.Invalid(selectorPath, k, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(string(v)) {
+			errs = append(errs, field.Invalid(selectorPath.Key(string(k)), v, msg))
+		}
+	}
+
+	portPath := specPath.Child("targetPortNumber")
+	for _, msg := range validation.IsValidPortNum(int(pool.Spec.TargetPortNumber)) {
+		errs = append(errs, field.Invalid(portPath, pool.Spec.TargetPortNumber, msg))
+	}
+
+	errs = append(errs, validateMetrics(pool.Spec.Metrics, specPath.Child("metrics"))...)
+	return append(errs, validateExtensionRef(pool.Spec.ExtensionRef, specPath.Child("extensionRef"))...)
+}
+
+func validateMetrics(metrics *v1alpha2.ModelServerMetrics, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if metrics == nil {
+		return errs
+	}
+
+	if metrics.PortNumber != nil {
+		for _, msg := range validation.IsValidPortNum(int(*metrics.PortNumber)) {
+			errs = append(errs, field.Invalid(fldPath.Child("portNumber"), *metrics.PortNumber, msg))
+		}
+	}
+	if metrics.Path != nil && !strings.HasPrefix(*metrics.Path, "/") {
+		errs = append(errs, field.Invalid(fldPath.Child("path"), *metrics.Path, "must be an absolute path"))
+	}
+	if metrics.Scheme != nil && *metrics.Scheme != v1alpha2.MetricsSchemeHTTP && *metrics.Scheme != v1alpha2.MetricsSchemeHTTPS {
+		errs = append(errs, field.NotSupported(fldPath.Child("scheme"), *metrics.Scheme,
+			[]string{string(v1alpha2.MetricsSchemeHTTP), string(v1alpha2.MetricsSchemeHTTPS)}))
+	}
+	if metrics.Protocol != nil {
+		if _, err := backendmetrics.MetricMappingForProtocol(*metrics.Protocol); err != nil {
+			errs = append(errs, field.NotSupported(fldPath.Child("protocol"), *metrics.Protocol, protocolNames()))
+		}
+	}
+	if m := metrics.Mapping; m != nil {
+		if _, err := backendmetrics.NewMetricMapping(m.TotalQueuedRequests, m.KVCacheUtilization, m.LoRARequestInfo); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("mapping"), *m, err.Error()))
+		}
+	}
+	return errs
+}
+
+func protocolNames() []string {
+	var names []string
+	for _, protocol := range backendmetrics.ModelServerProtocols() {
+		names = append(names, string(protocol))
+	}
+	return names
+}
+
+func validateExtensionRef(ext *v1alpha2.Extension, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if ext == nil {
+		return append(errs, field.Required(fldPath, ""))
+	}
+
+	// Group and Kind are defaulted by the API server, treat unset values as a core Service.
+	isService := (ext.Group == nil || *ext.Group == "") && (ext.Kind == nil || *ext.Kind == "Service")
+	namePath := fldPath.Child("name")
+	if ext.Name == "" {
+		errs = append(errs, field.Required(namePath, ""))
+	} else if isService {
+		for _, msg := range validation.IsDNS1035Label(string(ext.Name)) {
+			errs = append(errs, field.Invalid(namePath, ext.Name, msg))
+		}
+	}
+
+	if ext.PortNumber != nil {
+		for _, msg := range validation.IsValidPortNum(int(*ext.PortNumber)) {
+			errs = append(errs, field.Invalid(fldPath.Child("portNumber"), *ext.PortNumber, msg))
+		}
+	}
+
+	if ext.FailureMode != nil && *ext.FailureMode != v1alpha2.FailOpen && *ext.FailureMode != v1alpha2.FailClose {
+		errs = append(errs, field.NotSupported(fldPath.Child("failureMode"), *ext.FailureMode,
+			[]string{string(v1alpha2.FailOpen), string(v1alpha2.FailClose)}))
+	}
+	return errs
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+	utiltest "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/testing"
+)
+
+func TestValidateInferencePool(t *testing.T) {
+	validPool := func() *utiltest.InferencePoolWrapper {
+		return utiltest.MakeInferencePool("pool").
+			Selector(map[string]string{"app": "vllm"}).
+			TargetPortNumber(8000).
+			ExtensionRef("epp")
+	}
+
+	tests := []struct {
+		name      string
+		pool      *v1alpha2.InferencePool
+		wantPaths []string
+	}{
+		{
+			name: "Valid",
+			pool: validPool().ObjRef(),
+		},
+		{
+			name:      "Empty selector",
+			pool:      validPool().Selector(map[string]string{}).ObjRef(),
+			wantPaths: []string{"spec.selector"},
+		},
+		{
+			name:      "Invalid selector key",
+			pool:      validPool().Selector(map[string]string{"app name": "vllm"}).ObjRef(),
+			wantPaths: []string{"spec.selector"},
+		},
+		{
+			name:      "Invalid selector value",
+			pool:      validPool().Selector(map[string]string{"app": "vllm/llama"}).ObjRef(),
+			wantPaths: []string{"spec.selector[app]"},
+		},
+		{
+			name:      "Invalid target port",
+			pool:      validPool().TargetPortNumber(0).ObjRef(),
+			wantPaths: []string{"spec.targetPortNumber"},
+		},
+		{
+			name: "Missing extensionRef",
+			pool: func() *v1alpha2.InferencePool {
+				p := validPool().ObjRef()
+				p.Spec.ExtensionRef = nil
+				return p
+			}(),
+			wantPaths: []string{"spec.extensionRef"},
+		},
+		{
+			name:      "Invalid service name",
+			pool:      validPool().ExtensionRef("EPP.svc").ObjRef(),
+			wantPaths: []string{"spec.extensionRef.name"},
+		},
+		{
+			name: "Invalid extension port",
+			pool: func() *v1alpha2.InferencePool {
+				p := validPool().ObjRef()
+				port := v1alpha2.PortNumber(70000)
+				p.Spec.ExtensionRef.PortNumber = &port
+				return p
+			}(),
+			wantPaths: []string{"spec.extensionRef.portNumber"},
+		},
+		{
+			name: "Unsupported failure mode",
+			pool: func() *v1alpha2.InferencePool {
+				p := validPool().ObjRef()
+				mode := v1alpha2.ExtensionFailureMode("FailSometimes")
+				p.Spec.ExtensionRef.FailureMode = &mode
+				return p
+			}(),
+			wantPaths: []string{"spec.extensionRef.failureMode"},
+		},
+		{
+			name: "Valid metrics",
+			pool: func() *v1alpha2.InferencePool {
+				p := validPool().ObjRef()
+				p.Spec.Metrics = &v1alpha2.ModelServerMetrics{
+					PortNumber: ptr.To(v1alpha2.PortNumber(9090)),
+					Path:       ptr.To("/metrics"),
+					Scheme:     ptr.To(v1alpha2.MetricsSchemeHTTPS),
+					Protocol:   ptr.To(v1alpha2.ModelServerProtocolTGI),
+					Mapping:    &v1alpha2.ModelServerMetricMapping{TotalQueuedRequests: "tgi_queue_size{model=llama}"},
+				}
+				return p
+			}(),
+		},
+		{
+			name: "Invalid metrics",
+			pool: func() *v1alpha2.InferencePool {
+				p := validPool().ObjRef()
+				p.Spec.Metrics = &v1alpha2.ModelServerMetrics{
+					Path:     ptr.To("metrics"),
+					Protocol: ptr.To(v1alpha2.ModelServerProtocol("Ollama")),
+					Mapping:  &v1alpha2.ModelServerMetricMapping{KVCacheUtilization: "kv_usage{model}"},
+				}
+				return p
+			}(),
+			wantPaths: []string{"spec.metrics.path", "spec.metrics.protocol", "spec.metrics.mapping"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errs := ValidateInferencePool(test.pool)
+			if diff := cmp.Diff(test.wantPaths, errorPaths(errs)); diff != "" {
+				t.Errorf("Unexpected validation errors (-want +got): %s, errors: %v", diff, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook contains integration tests for the validating admission webhook.
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/gateway-api-inference-extension/api/v1alpha2"
+	logutil "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/logging"
+	utiltest "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/util/testing"
+	eppwebhook "sigs.k8s.io/gateway-api-inference-extension/pkg/epp/webhook"
+)
+
+var (
+	k8sClient k8sclient.Client
+	testEnv   *envtest.Environment
+	scheme    = runtime.NewScheme()
+	logger    = logutil.NewTestLogger().V(logutil.VERBOSE)
+)
+
+func TestMain(m *testing.M) {
+	cleanup := BeforeSuite()
+	code := m.Run()
+	cleanup()
+	os.Exit(code)
+}
+
+func TestInferenceModelAdmission(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   *v1alpha2.InferenceModel
+		wantErr bool
+	}{
+		{
+			name: "Weighted target models accepted",
+			model: utiltest.MakeInferenceModel("weighted").Namespace("default").ModelName("weighted").PoolName("pool").
+				WeightedTargetModel("v1", 10).WeightedTargetModel("v2", 90).ObjRef(),
+		},
+		{
+			name: "Unweighted target models accepted",
+			model: utiltest.MakeInferenceModel("unweighted").Namespace("default").ModelName("unweighted").PoolName("pool").
+				TargetModel("v1").TargetModel("v2").ObjRef(),
+		},
+		{
+			name: "Mixed weights rejected",
+			model: utiltest.MakeInferenceModel("mixed").Namespace("default").ModelName("mixed").PoolName("pool").
+				WeightedTargetModel("v1", 10).TargetModel("v2").ObjRef(),
+			wantErr: true,
+		},
+		{
+			name: "Duplicate target names rejected",
+			model: utiltest.MakeInferenceModel("duplicate").Namespace("default").ModelName("duplicate").PoolName("pool").
+				TargetModel("v1").TargetModel("v1").ObjRef(),
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := k8sClient.Create(context.Background(), test.model)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("Unexpected error, want error: %v, got: %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestInferencePoolAdmission(t *testing.T) {
+	tests := []struct {
+		name    string
+		pool    *v1alpha2.InferencePool
+		wantErr bool
+	}{
+		{
+			name: "Valid pool accepted",
+			pool: utiltest.MakeInferencePool("valid").Namespace("default").
+				Selector(map[string]string{"app": "vllm"}).TargetPortNumber(8000).ExtensionRef("epp").ObjRef(),
+		},
+		{
+			name: "Empty selector rejected",
+			pool: utiltest.MakeInferencePool("empty-selector").Namespace("default").
+				Selector(map[string]string{}).TargetPortNumber(8000).ExtensionRef("epp").ObjRef(),
+			wantErr: true,
+		},
+		{
+			name: "Invalid extension service name rejected",
+			pool: utiltest.MakeInferencePool("invalid-extension").Namespace("default").
+				Selector(map[string]string{"app": "vllm"}).TargetPortNumber(8000).ExtensionRef("EPP.svc").ObjRef(),
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := k8sClient.Create(context.Background(), test.pool)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("Unexpected error, want error: %v, got: %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+// BeforeSuite starts the API server with the CRDs and the webhook configuration from config/,
+// and serves the webhooks from a manager wired to the envtest serving certificates.
+func BeforeSuite() func() {
+	testEnv = &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "..", "config", "crd", "bases")},
+		ErrorIfCRDPathMissing: true,
+		WebhookInstallOptions: envtest.WebhookInstallOptions{
+			Paths: []string{filepath.Join("..", "..", "..", "config", "webhook", "manifests.yaml")},
+		},
+	}
+	cfg, err := testEnv.Start()
+	if err != nil {
+		logutil.Fatal(logger, err, "Failed to start test environment", "config", cfg)
+	}
+
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(v1alpha2.Install(scheme))
+
+	k8sClient, err = k8sclient.New(cfg, k8sclient.Options{Scheme: scheme})
+	if err != nil {
+		logutil.Fatal(logger, err, "Failed to start k8s Client")
+	}
+
+	ctrl.SetLogger(logger)
+
+	webhookOpts := testEnv.WebhookInstallOptions
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:  scheme,
+		Metrics: metricsserver.Options{BindAddress: "0"},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Host:    webhookOpts.LocalServingHost,
+			Port:    webhookOpts.LocalServingPort,
+			CertDir: webhookOpts.LocalServingCertDir,
+		}),
+	})
+	if err != nil {
+		logutil.Fatal(logger, err, "Failed to create controller manager")
+	}
+	if err := (&eppwebhook.InferenceModelValidator{}).SetupWithManager(mgr); err != nil {
+		logutil.Fatal(logger, err, "Failed to setup InferenceModel webhook")
+	}
+	if err := (&eppwebhook.InferencePoolValidator{}).SetupWithManager(mgr); err != nil {
+		logutil.Fatal(logger, err, "Failed to setup InferencePool webhook")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		if err := mgr.Start(ctx); err != nil {
+			logutil.Fatal(logger, err, "Failed to start manager")
+		}
+	}()
+
+	// Wait for the webhook server to accept connections.
+	addr := net.JoinHostPort(webhookOpts.LocalServingHost, fmt.Sprint(webhookOpts.LocalServingPort))
+	assert.Eventually(nil, func() bool {
+		conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true}) // #nosec G402 -- local test server.
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}, 10*time.Second, 10*time.Millisecond)
+
+	return func() {
+		cancel()
+		_ = testEnv.Stop()
+	}
+}